@@ -62,3 +62,14 @@ func Exit(code int) {
 func RegisterExitHandler(handler func()) {
 	handlers = append(handlers, handler)
 }
+
+// DeferExitHandler prepends a Logrus Exit handler to the list of handlers,
+// call logrus.Exit to invoke all handlers. The handlers will also be invoked
+// when any Fatal log entry is made.
+//
+// Unlike RegisterExitHandler, handlers run in the reverse order of
+// registration, like deferred calls, e.g. to close a connection before the
+// pool it was taken from.
+func DeferExitHandler(handler func()) {
+	handlers = append([]func(){handler}, handlers...)
+}
@@ -1,11 +1,13 @@
 package logrus
 
 import (
+	"bytes"
 	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -18,6 +20,80 @@ func TestRegister(t *testing.T) {
 	}
 }
 
+func TestDeferExitHandler(t *testing.T) {
+	defer func(saved []func()) { handlers = saved }(handlers)
+	handlers = nil
+
+	var order []string
+	RegisterExitHandler(func() { order = append(order, "registered") })
+	DeferExitHandler(func() { order = append(order, "deferred first") })
+	DeferExitHandler(func() { order = append(order, "deferred second") })
+	runHandlers()
+
+	expected := []string{"deferred second", "deferred first", "registered"}
+	if len(order) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, order)
+		}
+	}
+}
+
+func TestRecordExits(t *testing.T) {
+	defer func(saved []func()) { handlers = saved }(handlers)
+	handled := 0
+	RegisterExitHandler(func() { handled++ })
+
+	var buffer bytes.Buffer
+	logger := New()
+	logger.Out = &buffer
+	exits := logger.RecordExits()
+
+	if _, ok := exits.Exited(); ok {
+		t.Fatal("recorded an exit before Fatal")
+	}
+	logger.WithField("key", "value").Fatal("fatal")
+	logger.Fatalf("fatal %d", 2)
+
+	code, ok := exits.Exited()
+	if !ok || code != 1 {
+		t.Fatalf("expected exit code 1, got %d, %v", code, ok)
+	}
+	if codes := exits.Codes(); len(codes) != 2 {
+		t.Fatalf("expected 2 exits, got %v", codes)
+	}
+	if handled != 2 {
+		t.Fatalf("expected the exit handlers to run twice, got %d", handled)
+	}
+	// the logger isn't closed by the exits
+	logger.Info("after")
+	if !strings.Contains(buffer.String(), "after") {
+		t.Fatalf("expected the logger to keep logging, got %q", buffer.String())
+	}
+}
+
+func TestSetExitFunc(t *testing.T) {
+	defer func(saved []func()) { handlers = saved }(handlers)
+	var buffer bytes.Buffer
+	logger := New()
+	logger.Out = &buffer
+	RegisterExitHandler(func() { logger.Info("handled") })
+	exited := -1
+	logger.SetExitFunc(func(code int) {
+		if !strings.Contains(buffer.String(), "handled") {
+			t.Error("expected the exit handlers to log before exiting")
+		}
+		exited = code
+	})
+
+	logger.Exit(3)
+	if exited != 3 {
+		t.Fatalf("expected exit code 3, got %d", exited)
+	}
+}
+
 func TestHandler(t *testing.T) {
 	tempDir, err := ioutil.TempDir("", "test_handler")
 	if err != nil {
@@ -42,28 +118,34 @@ func TestHandler(t *testing.T) {
 		t.Fatalf("can't read output file %s. %q", outfile, err)
 	}
 
-	if string(data) != arg {
-		t.Fatalf("bad data. Expected %q, got %q", data, arg)
+	if string(data) != "Bye bye"+arg {
+		t.Fatalf("bad data. Expected %q, got %q", "Bye bye"+arg, data)
 	}
 }
 
 var testprog = []byte(`
-// Test program for atexit, gets output file and data as arguments and writes
-// data to output file in atexit handler.
+// Test program for atexit, gets output file and data as arguments and logs
+// data to output file in atexit handler, before the logger is closed.
 package main
 
 import (
 	"github.com/dorofeevsa/logrus"
 	"flag"
 	"fmt"
-	"io/ioutil"
+	"os"
 )
 
 var outfile = ""
 var data = ""
 
+type messageFormatter struct{}
+
+func (messageFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	return []byte(entry.Message), nil
+}
+
 func handler() {
-	ioutil.WriteFile(outfile, []byte(data), 0666)
+	logrus.Info(data)
 }
 
 func badHandler() {
@@ -75,6 +157,12 @@ func main() {
 	flag.Parse()
 	outfile = flag.Arg(0)
 	data = flag.Arg(1)
+	f, err := os.Create(outfile)
+	if err != nil {
+		panic(err)
+	}
+	logrus.SetOutput(f)
+	logrus.SetFormatter(messageFormatter{})
 
 	logrus.RegisterExitHandler(handler)
 	logrus.RegisterExitHandler(badHandler)
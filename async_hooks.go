@@ -0,0 +1,152 @@
+package logrus
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// asyncHooks fires hooks in the background, each hook in its own worker with
+// its own queue, see Logger.SetAsyncHooks.
+type asyncHooks struct {
+	logger    *Logger
+	queueSize int
+	workers   map[Hook]*hookWorker
+}
+
+type hookWorker struct {
+	hook   Hook
+	logger *Logger
+	queue  chan *Entry
+	// number of entries queued, with the logger's mu held
+	queued uint64
+	// number of entries fired, guarded by mu and broadcast by cond
+	fired uint64
+	mu    sync.Mutex
+	cond  *sync.Cond
+	done  chan struct{}
+}
+
+// SetAsyncHooks makes the logger fire hooks in the background, so a slow
+// hook, e.g. over the network, can't block logging. Each hook gets a worker
+// goroutine with a queue of queueSize entries; entries for a hook with a full
+// queue are dropped. Fatal and Panic entries are fired synchronously, after
+// the queued entries, so they are delivered before the program exits. A
+// non-positive queueSize fires hooks synchronously again.
+func (logger *Logger) SetAsyncHooks(queueSize int) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	if logger.asyncHooks != nil {
+		logger.asyncHooks.stop()
+		logger.asyncHooks = nil
+	}
+	if queueSize > 0 {
+		logger.asyncHooks = &asyncHooks{logger: logger, queueSize: queueSize, workers: map[Hook]*hookWorker{}}
+	}
+}
+
+// fire queues entry for the hooks of its level.
+// Must be called with the logger's mu held.
+func (a *asyncHooks) fire(hooks LevelHooks, entry *Entry) {
+	for _, hook := range hooks[entry.Level] {
+		if !reflect.TypeOf(hook).Comparable() {
+			// can't have a worker
+			a.logger.fireHook(hook, entry)
+			continue
+		}
+
+		// each worker gets its own copy, as formatting an entry may modify
+		// its fields while another worker reads them
+		a.worker(hook).enqueue(copyEntry(entry))
+	}
+}
+
+// copyEntry returns a copy of entry hooks can use while it's formatted.
+func copyEntry(entry *Entry) *Entry {
+	e := *entry
+	e.Buffer = nil
+	e.Data = make(Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		e.Data[k] = v
+	}
+	return &e
+}
+
+// Must be called with the logger's mu held.
+func (a *asyncHooks) worker(hook Hook) *hookWorker {
+	w, ok := a.workers[hook]
+	if !ok {
+		w = &hookWorker{hook: hook, logger: a.logger, queue: make(chan *Entry, a.queueSize), done: make(chan struct{})}
+		w.cond = sync.NewCond(&w.mu)
+		go w.run()
+		a.workers[hook] = w
+	}
+	return w
+}
+
+// flush waits until the queued entries are fired.
+// Must be called with the logger's mu held.
+func (a *asyncHooks) flush() {
+	for _, w := range a.workers {
+		w.wait(w.queued)
+	}
+}
+
+// waiter returns a function waiting until the entries queued so far are
+// fired, which may be called without the logger's mu.
+// Must be called with the logger's mu held.
+func (a *asyncHooks) waiter() func() {
+	if a == nil {
+		return func() {}
+	}
+	workers := make(map[*hookWorker]uint64, len(a.workers))
+	for _, w := range a.workers {
+		workers[w] = w.queued
+	}
+	return func() {
+		for w, queued := range workers {
+			w.wait(queued)
+		}
+	}
+}
+
+// stop fires the queued entries and stops the workers.
+// Must be called with the logger's mu held.
+func (a *asyncHooks) stop() {
+	for _, w := range a.workers {
+		close(w.queue)
+		<-w.done
+	}
+	a.workers = nil
+}
+
+func (w *hookWorker) enqueue(entry *Entry) {
+	select {
+	case w.queue <- entry:
+		w.queued++
+	default:
+		w.logger.hookError(w.hook, entry, errors.New("hook queue full, entry dropped"))
+	}
+}
+
+func (w *hookWorker) run() {
+	defer close(w.done)
+
+	for entry := range w.queue {
+		w.logger.fireHook(w.hook, entry)
+		w.mu.Lock()
+		w.fired++
+		w.cond.Broadcast()
+		w.mu.Unlock()
+	}
+}
+
+// wait waits until the first queued entries are fired.
+func (w *hookWorker) wait(queued uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for w.fired < queued {
+		w.cond.Wait()
+	}
+}
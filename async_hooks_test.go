@@ -0,0 +1,99 @@
+package logrus
+
+import (
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingHook records the messages of entries, waiting for release before
+// firing each one.
+type blockingHook struct {
+	release  chan struct{}
+	mu       sync.Mutex
+	messages []string
+}
+
+func (hook *blockingHook) Fire(entry *Entry) error {
+	<-hook.release
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+	hook.messages = append(hook.messages, entry.Message)
+	return nil
+}
+
+func (hook *blockingHook) Levels() []Level {
+	return AllLevels
+}
+
+func (hook *blockingHook) Close() error {
+	return nil
+}
+
+func (hook *blockingHook) Messages() []string {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+	return append([]string(nil), hook.messages...)
+}
+
+func TestAsyncHooksDontBlock(t *testing.T) {
+	hook := &blockingHook{release: make(chan struct{})}
+	log := New()
+	log.Out = ioutil.Discard
+	log.AddHook(hook)
+	log.SetAsyncHooks(2)
+
+	log.Info("one")
+	log.Info("two")
+	log.Info("three")
+	log.Info("four")
+	assert.Empty(t, hook.Messages())
+
+	close(hook.release)
+	log.SetAsyncHooks(0)
+	messages := hook.Messages()
+	assert.True(t, len(messages) >= 2 && len(messages) <= 3, "got %v", messages)
+	assert.Equal(t, []string{"one", "two"}, messages[:2])
+}
+
+func TestAsyncHooksFlushOnPanic(t *testing.T) {
+	hook := &blockingHook{release: make(chan struct{})}
+	close(hook.release)
+	log := New()
+	log.Out = ioutil.Discard
+	log.AddHook(hook)
+	log.SetAsyncHooks(10)
+
+	log.Info("one")
+	log.Info("two")
+	assert.Panics(t, func() { log.Panic("three") })
+	assert.Equal(t, []string{"one", "two", "three"}, hook.Messages())
+	log.SetAsyncHooks(0)
+}
+
+// formattingHook formats the entries it fires, like hooks writing them.
+type formattingHook struct {
+	TestHook
+}
+
+func (hook *formattingHook) Fire(entry *Entry) error {
+	_, err := (&TextFormatter{DisableColors: true}).Format(entry)
+	return err
+}
+
+// Run with -race: formatting modifies the fields of entries.
+func TestAsyncHooksFormattingConcurrently(t *testing.T) {
+	log := New()
+	log.Out = ioutil.Discard
+	log.AddHook(&formattingHook{})
+	log.AddHook(&formattingHook{})
+	log.SetAsyncHooks(100)
+
+	for i := 0; i < 100; i++ {
+		// clashing keys are prefixed in the fields while formatting
+		log.WithFields(Fields{"msg": i, "level": i, "time": i}).Info("test")
+	}
+	log.SetAsyncHooks(0)
+}
@@ -0,0 +1,165 @@
+package benchmarks
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dorofeevsa/logrus"
+	"github.com/dorofeevsa/logrus/hooks/lfslog"
+)
+
+var fields = logrus.Fields{
+	"user":     "alice",
+	"request":  "4bf92f3577b34da6",
+	"status":   200,
+	"duration": 0.0123,
+	"error":    errors.New("connection reset"),
+}
+
+// nopHook is a hook doing nothing, to measure firing hooks. The id keeps
+// pointers to hooks distinct.
+type nopHook struct {
+	id int
+}
+
+func (*nopHook) Levels() []logrus.Level   { return logrus.AllLevels }
+func (*nopHook) Fire(*logrus.Entry) error { return nil }
+func (*nopHook) Close() error             { return nil }
+
+func newLogger(f logrus.Formatter) *logrus.Logger {
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	logger.Formatter = f
+	return logger
+}
+
+func benchmarkLogger(b *testing.B, logger *logrus.Logger) {
+	entry := logger.WithFields(fields)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			entry.Info("request handled")
+		}
+	})
+}
+
+func BenchmarkTextFormatter(b *testing.B) {
+	benchmarkLogger(b, newLogger(&logrus.TextFormatter{DisableColors: true}))
+}
+
+func BenchmarkJSONFormatter(b *testing.B) {
+	benchmarkLogger(b, newLogger(&logrus.JSONFormatter{}))
+}
+
+func BenchmarkDisabledLevel(b *testing.B) {
+	logger := newLogger(&logrus.JSONFormatter{})
+	logger.SetLevel(logrus.WarnLevel)
+	benchmarkLogger(b, logger)
+}
+
+func BenchmarkStats(b *testing.B) {
+	logger := newLogger(&logrus.JSONFormatter{})
+	logger.SetStatsEnabled(true)
+	benchmarkLogger(b, logger)
+}
+
+func BenchmarkHookFanOut(b *testing.B) {
+	for _, n := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("hooks=%d", n), func(b *testing.B) {
+			logger := newLogger(&logrus.JSONFormatter{})
+			for i := 0; i < n; i++ {
+				logger.AddHook(&nopHook{id: i})
+			}
+			benchmarkLogger(b, logger)
+		})
+	}
+}
+
+func BenchmarkAsyncHookFanOut(b *testing.B) {
+	for _, n := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("hooks=%d", n), func(b *testing.B) {
+			logger := newLogger(&logrus.JSONFormatter{})
+			for i := 0; i < n; i++ {
+				logger.AddHook(&nopHook{id: i})
+			}
+			logger.SetAsyncHooks(1024)
+			defer logger.SetAsyncHooks(0)
+			// entries are dropped when the hooks lag behind
+			var dropped uint64
+			logger.SetHookErrorHandler(func(logrus.Hook, *logrus.Entry, error) {
+				atomic.AddUint64(&dropped, 1)
+			})
+			benchmarkLogger(b, logger)
+			b.ReportMetric(float64(atomic.LoadUint64(&dropped))/float64(b.N), "drops/op")
+		})
+	}
+}
+
+func BenchmarkLfsHook(b *testing.B) {
+	dir, err := ioutil.TempDir("", "benchmarks")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, size := range []int{0, 64 << 10} {
+		b.Run(fmt.Sprintf("buffer=%d", size), func(b *testing.B) {
+			hook := lfslog.NewSinglePathHook(filepath.Join(dir, fmt.Sprintf("app-%d.log", size)), &logrus.JSONFormatter{})
+			hook.SetBufferSize(size)
+			defer hook.Close()
+			logger := newLogger(&logrus.JSONFormatter{})
+			logger.AddHook(hook)
+			benchmarkLogger(b, logger)
+		})
+	}
+}
+
+// values of fields, not constants which don't allocate when converted to
+// interface{} values
+var (
+	user     = "alice"
+	status   = 404
+	duration = 0.0123
+)
+
+func BenchmarkWithFields(b *testing.B) {
+	logger := newLogger(&logrus.JSONFormatter{})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.WithFields(logrus.Fields{"user": user, "status": status, "duration": duration}).Info("request handled")
+	}
+}
+
+func BenchmarkWithTypedFields(b *testing.B) {
+	logger := newLogger(&logrus.JSONFormatter{})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.WithTypedFields(logrus.Str("user", user), logrus.Int("status", status), logrus.Float64("duration", duration)).Info("request handled")
+	}
+}
+
+func BenchmarkWithFieldsDisabled(b *testing.B) {
+	logger := newLogger(&logrus.JSONFormatter{})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.WithFields(logrus.Fields{"user": user, "status": status, "duration": duration}).Debug("request handled")
+	}
+}
+
+func BenchmarkWithTypedFieldsDisabled(b *testing.B) {
+	logger := newLogger(&logrus.JSONFormatter{})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.WithTypedFields(logrus.Str("user", user), logrus.Int("status", status), logrus.Float64("duration", duration)).Debug("request handled")
+	}
+}
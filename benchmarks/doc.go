@@ -0,0 +1,10 @@
+// Package benchmarks holds benchmarks of logrus as used by programs, from
+// logging an entry to writing it: the text and JSON formatters, typed fields,
+// firing several hooks, and writing files with the lfslog hook. Run them with
+//
+//	go test -run NONE -bench . -benchmem ./benchmarks
+//
+// and compare runs with benchstat to catch performance regressions. The
+// rotatelog hook is benchmarked in its own package. Logger.SetStatsEnabled
+// measures the entries and bytes per second of a running program.
+package benchmarks
@@ -0,0 +1,106 @@
+package logrus
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Default key names for the caller fields
+const (
+	FieldKeyFunc = "func"
+	FieldKeyFile = "file"
+)
+
+const maximumCallerDepth = 25
+
+var (
+	// the package name of logrus, to skip its frames
+	logrusPackage     string
+	logrusPackageOnce sync.Once
+)
+
+// SetReportCaller makes the logger add the calling function and file to
+// entries, in the FieldKeyFunc and FieldKeyFile fields of the formatters.
+func (logger *Logger) SetReportCaller(reportCaller bool) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	logger.ReportCaller = reportCaller
+}
+
+// HasCaller reports whether the entry has the caller it was logged from.
+func (entry *Entry) HasCaller() bool {
+	return entry.Caller != nil
+}
+
+// getCaller returns the frame of the first caller outside of logrus, skipping
+// skip more frames, e.g. of a package wrapping logrus.
+func getCaller(skip int) *runtime.Frame {
+	pcs := make([]uintptr, maximumCallerDepth)
+	depth := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:depth])
+
+	for f, again := frames.Next(); again; f, again = frames.Next() {
+		if isLogrusFrame(f) {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		return &f
+	}
+	return nil
+}
+
+// isLogrusFrame reports whether f is a frame of logrus itself.
+func isLogrusFrame(f runtime.Frame) bool {
+	logrusPackageOnce.Do(func() {
+		pcs := make([]uintptr, 1)
+		runtime.Callers(1, pcs)
+		logrusPackage = packageName(runtime.FuncForPC(pcs[0]).Name())
+	})
+
+	// the package's own tests log like users do
+	return packageName(f.Function) == logrusPackage && !strings.HasSuffix(f.File, "_test.go")
+}
+
+// packageName returns the package of the fully qualified function f.
+func packageName(f string) string {
+	for {
+		lastPeriod := strings.LastIndex(f, ".")
+		lastSlash := strings.LastIndex(f, "/")
+		if lastPeriod > lastSlash {
+			f = f[:lastPeriod]
+		} else {
+			break
+		}
+	}
+	return f
+}
+
+// callerInfo returns the function and file:line of the entry's caller, as
+// formatted by the logger's CallerPrettyfier. File paths are trimmed of the
+// logger's CallerTrimPrefixes.
+func (entry *Entry) callerInfo() (function, file string) {
+	if entry.Logger != nil && entry.Logger.CallerPrettyfier != nil {
+		return entry.Logger.CallerPrettyfier(entry.Caller)
+	}
+	return entry.Caller.Function, fmt.Sprintf("%s:%d", entry.callerFile(), entry.Caller.Line)
+}
+
+// callerFile returns the file of the entry's caller, trimmed of the logger's
+// CallerTrimPrefixes.
+func (entry *Entry) callerFile() string {
+	file := entry.Caller.File
+	if entry.Logger != nil {
+		for _, prefix := range entry.Logger.CallerTrimPrefixes {
+			if strings.HasPrefix(file, prefix) {
+				return strings.TrimPrefix(file[len(prefix):], "/")
+			}
+		}
+	}
+	return file
+}
@@ -0,0 +1,73 @@
+package logrus
+
+import (
+	"bytes"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportCaller(t *testing.T) {
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.SetReportCaller(true)
+		log.Infof("test %s", "caller")
+	}, func(fields Fields) {
+		assert.Equal(t, "github.com/dorofeevsa/logrus.TestReportCaller.func1", fields[FieldKeyFunc])
+		assert.True(t, strings.HasSuffix(fields[FieldKeyFile].(string), "/caller_test.go:16"), fields[FieldKeyFile])
+	})
+}
+
+func TestReportCallerDisabled(t *testing.T) {
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.Info("test")
+	}, func(fields Fields) {
+		_, ok := fields[FieldKeyFunc]
+		assert.False(t, ok)
+	})
+}
+
+// logWrapped logs like a package wrapping logrus.
+func logWrapped(log *Logger) {
+	log.WithField("wrapped", true).Info("test")
+}
+
+func TestCallerSkip(t *testing.T) {
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.SetReportCaller(true)
+		log.CallerSkip = 1
+		logWrapped(log)
+	}, func(fields Fields) {
+		assert.Equal(t, "github.com/dorofeevsa/logrus.TestCallerSkip.func1", fields[FieldKeyFunc])
+	})
+}
+
+func TestCallerTrimPrefixes(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.SetReportCaller(true)
+		log.CallerTrimPrefixes = []string{"/nonexistent", filepath.Dir(filepath.Dir(file))}
+		log.Info("test")
+	}, func(fields Fields) {
+		assert.Equal(t, filepath.Base(filepath.Dir(file))+"/caller_test.go:53", fields[FieldKeyFile])
+	})
+}
+
+func TestCallerPrettyfier(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = &ECSFormatter{DisableTimestamp: true}
+	log.SetReportCaller(true)
+	log.CallerPrettyfier = func(f *runtime.Frame) (string, string) {
+		return "handler", filepath.Base(f.File)
+	}
+
+	log.Info("test")
+
+	assert.Contains(t, buffer.String(), `"origin":{"file":{"line":`)
+	assert.Contains(t, buffer.String(), `"name":"caller_test.go"},"function":"handler"}`)
+}
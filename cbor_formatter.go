@@ -0,0 +1,203 @@
+package logrus
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// cborSelfDescribeTag marks data as CBOR, see RFC 8949 section 3.4.6.
+const cborSelfDescribeTag = 55799
+
+// CBORFormatter formats logs into CBOR maps of the time, level, message and
+// fields, for deployments where log bandwidth and parse cost matter more than
+// readability. Map keys are sorted as in the deterministic encoding of RFC
+// 8949, so equal entries are encoded to equal bytes.
+type CBORFormatter struct {
+	// TimestampFormat sets the format used for marshaling timestamps.
+	TimestampFormat string
+
+	// DisableTimestamp allows disabling automatic timestamps in output
+	DisableTimestamp bool
+
+	// FieldMap allows users to customize the names of keys for default fields.
+	FieldMap FieldMap
+
+	// DataKey allows users to put all the log entry parameters into a nested
+	// dictionary at a given key.
+	DataKey string
+
+	// SelfDescribe prefixes entries with the self-described CBOR tag, so
+	// they can be told apart from other data.
+	SelfDescribe bool
+}
+
+// Format renders a single log entry
+func (f *CBORFormatter) Format(entry *Entry) ([]byte, error) {
+	data := make(Fields, len(entry.Data)+3)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+
+	if f.DataKey != "" {
+		data = Fields{f.DataKey: data}
+	}
+	prefixFieldClashes(data, f.FieldMap, entry.HasCaller())
+
+	timestampFormat := f.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = defaultTimestampFormat
+	}
+
+	if !f.DisableTimestamp {
+		data[f.FieldMap.resolve(FieldKeyTime)] = entry.Time.Format(timestampFormat)
+	}
+	data[f.FieldMap.resolve(FieldKeyMsg)] = entry.Message
+	data[f.FieldMap.resolve(FieldKeyLevel)] = entry.Level.String()
+	if entry.HasCaller() {
+		function, file := entry.callerInfo()
+		data[f.FieldMap.resolve(FieldKeyFunc)] = function
+		data[f.FieldMap.resolve(FieldKeyFile)] = file
+	}
+
+	b := entryBuffer(entry)
+	e := cborEncoder{buf: b.Bytes()}
+	if f.SelfDescribe {
+		e.writeHead(6, cborSelfDescribeTag)
+	}
+	if err := e.writeMap(data); err != nil {
+		return nil, fmt.Errorf("Failed to marshal fields to CBOR, %v", err)
+	}
+	// keep the buffer grown by the encoder in the pool
+	*b = *bytes.NewBuffer(e.buf)
+	return e.buf, nil
+}
+
+// cborEncoder appends CBOR encoded values to buf.
+type cborEncoder struct {
+	buf []byte
+}
+
+func (e *cborEncoder) writeValue(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		e.buf = append(e.buf, 0xf6)
+	case bool:
+		if v {
+			e.buf = append(e.buf, 0xf5)
+		} else {
+			e.buf = append(e.buf, 0xf4)
+		}
+	case int:
+		e.writeInt(int64(v))
+	case int8:
+		e.writeInt(int64(v))
+	case int16:
+		e.writeInt(int64(v))
+	case int32:
+		e.writeInt(int64(v))
+	case int64:
+		e.writeInt(v)
+	case uint:
+		e.writeHead(0, uint64(v))
+	case uint8:
+		e.writeHead(0, uint64(v))
+	case uint16:
+		e.writeHead(0, uint64(v))
+	case uint32:
+		e.writeHead(0, uint64(v))
+	case uint64:
+		e.writeHead(0, v)
+	case float32:
+		e.buf = append(e.buf, 0xfa)
+		e.buf = appendUint32(e.buf, math.Float32bits(v))
+	case float64:
+		e.buf = append(e.buf, 0xfb)
+		e.buf = appendUint64(e.buf, math.Float64bits(v))
+	case string:
+		e.writeString(v)
+	case []byte:
+		e.writeHead(2, uint64(len(v)))
+		e.buf = append(e.buf, v...)
+	case error:
+		e.writeString(v.Error())
+	case time.Time:
+		e.writeString(v.Format(time.RFC3339Nano))
+	case Fields:
+		return e.writeMap(v)
+	case map[string]interface{}:
+		return e.writeMap(v)
+	case []interface{}:
+		e.writeHead(4, uint64(len(v)))
+		for _, item := range v {
+			if err := e.writeValue(item); err != nil {
+				return err
+			}
+		}
+	default:
+		// encode other types like encoding/json would
+		generic, err := toGeneric(v)
+		if err != nil {
+			return err
+		}
+		return e.writeValue(generic)
+	}
+	return nil
+}
+
+// writeMap writes m with its keys sorted by their encoding, shorter keys
+// first, as required by the deterministic encoding.
+func (e *cborEncoder) writeMap(m map[string]interface{}) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	e.writeHead(5, uint64(len(m)))
+	for _, k := range keys {
+		e.writeString(k)
+		if err := e.writeValue(m[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *cborEncoder) writeString(s string) {
+	e.writeHead(3, uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *cborEncoder) writeInt(i int64) {
+	if i < 0 {
+		e.writeHead(1, uint64(-(i + 1)))
+	} else {
+		e.writeHead(0, uint64(i))
+	}
+}
+
+// writeHead writes the initial bytes of a data item of the major type with
+// the argument n in its shortest form.
+func (e *cborEncoder) writeHead(major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		e.buf = append(e.buf, major|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, major|24, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = appendUint16(append(e.buf, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = appendUint32(append(e.buf, major|26), uint32(n))
+	default:
+		e.buf = appendUint64(append(e.buf, major|27), n)
+	}
+}
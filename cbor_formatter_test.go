@@ -0,0 +1,77 @@
+package logrus
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestCBORFormatter(t *testing.T) {
+	formatter := &CBORFormatter{DisableTimestamp: true, SelfDescribe: true}
+
+	entry := WithFields(Fields{"n": -5, "user": "al"})
+	entry.Level = InfoLevel
+	entry.Message = "hi"
+
+	b, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	expected := []byte{0xd9, 0xd9, 0xf7, 0xa4,
+		0x61, 'n', 0x24,
+		0x63, 'm', 's', 'g', 0x62, 'h', 'i',
+		0x64, 'u', 's', 'e', 'r', 0x62, 'a', 'l',
+		0x65, 'l', 'e', 'v', 'e', 'l', 0x64, 'i', 'n', 'f', 'o',
+	}
+	if !bytes.Equal(b, expected) {
+		t.Errorf("Expected % x, got % x", expected, b)
+	}
+}
+
+func TestCBORDataKey(t *testing.T) {
+	formatter := &CBORFormatter{DisableTimestamp: true, DataKey: "d"}
+
+	entry := WithField("msg", 1)
+	entry.Level = InfoLevel
+
+	b, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	expected := []byte{0xa3,
+		0x61, 'd', 0xa1, 0x63, 'm', 's', 'g', 0x01,
+		0x63, 'm', 's', 'g', 0x60,
+		0x65, 'l', 'e', 'v', 'e', 'l', 0x64, 'i', 'n', 'f', 'o',
+	}
+	if !bytes.Equal(b, expected) {
+		t.Errorf("Expected % x, got % x", expected, b)
+	}
+}
+
+func TestCBORValues(t *testing.T) {
+	for _, test := range []struct {
+		value    interface{}
+		expected []byte
+	}{
+		{nil, []byte{0xf6}},
+		{false, []byte{0xf4}},
+		{23, []byte{0x17}},
+		{24, []byte{0x18, 0x18}},
+		{1000, []byte{0x19, 0x03, 0xe8}},
+		{-1000, []byte{0x39, 0x03, 0xe7}},
+		{uint64(math.MaxUint32) + 1, []byte{0x1b, 0, 0, 0, 1, 0, 0, 0, 0}},
+		{1.5, []byte{0xfb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{[]byte{1, 2}, []byte{0x42, 1, 2}},
+		{[]interface{}{1, "a"}, []byte{0x82, 0x01, 0x61, 'a'}},
+	} {
+		var e cborEncoder
+		if err := e.writeValue(test.value); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(e.buf, test.expected) {
+			t.Errorf("Expected %v to encode to % x, got % x", test.value, test.expected, e.buf)
+		}
+	}
+}
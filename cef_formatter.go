@@ -0,0 +1,117 @@
+package logrus
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+)
+
+// CEFFormatter formats logs into the Common Event Format of ArcSight, for
+// SIEM pipelines accepting nothing else:
+//
+//	CEF:0|Vendor|Product|Version|info|hello|3|rt=1514862245006 user=alice
+//
+// The signature ID is the value of the SignatureIDKey field, or else the
+// event code (see WithCode) or the level, the name is the message and the
+// severity is derived from the level. The fields become the extension, with
+// the time as `rt` in epoch milliseconds.
+type CEFFormatter struct {
+	// Vendor, Product and Version identify the device sending the events.
+	Vendor  string
+	Product string
+	Version string
+
+	// SignatureIDKey is the field holding the signature ID of events.
+	SignatureIDKey string
+
+	// DisableTimestamp allows disabling automatic timestamps in output
+	DisableTimestamp bool
+}
+
+// Format renders a single log entry
+func (f *CEFFormatter) Format(entry *Entry) ([]byte, error) {
+	signatureIDKey := f.SignatureIDKey
+	if signatureIDKey == "" {
+		signatureIDKey = FieldKeyCode
+	}
+	signatureID := entry.Level.String()
+	if v, ok := entry.Data[signatureIDKey]; ok {
+		signatureID = fmt.Sprint(v)
+	}
+
+	b := entryBuffer(entry)
+	b.WriteString("CEF:0")
+	for _, field := range []string{f.Vendor, f.Product, f.Version, signatureID, entry.Message} {
+		b.WriteByte('|')
+		b.WriteString(cefHeaderEscaper.Replace(field))
+	}
+	b.WriteByte('|')
+	b.WriteString(strconv.Itoa(cefSeverity(entry.Level)))
+	b.WriteByte('|')
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		if k != signatureIDKey {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	sep := ""
+	if !f.DisableTimestamp {
+		b.WriteString("rt=")
+		b.WriteString(strconv.FormatInt(entry.Time.UnixNano()/1e6, 10))
+		sep = " "
+	}
+	if entry.HasCaller() {
+		function, file := entry.callerInfo()
+		b.WriteString(sep)
+		b.WriteString(FieldKeyFunc + "=" + cefExtensionEscaper.Replace(function))
+		b.WriteString(" " + FieldKeyFile + "=" + cefExtensionEscaper.Replace(file))
+		sep = " "
+	}
+	for _, k := range keys {
+		b.WriteString(sep)
+		b.WriteString(cefKey(k))
+		b.WriteByte('=')
+		b.WriteString(cefExtensionEscaper.Replace(fmt.Sprint(entry.Data[k])))
+		sep = " "
+	}
+
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+// cefSeverity maps level to the CEF severity from 0 to 10.
+func cefSeverity(level Level) int {
+	switch level.Builtin() {
+	case PanicLevel, FatalLevel:
+		return 10
+	case ErrorLevel:
+		return 7
+	case WarnLevel:
+		return 5
+	case InfoLevel:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// cefKey makes k a valid extension key, which may only contain letters and
+// digits.
+func cefKey(k string) string {
+	key := []byte(k)
+	for i, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			key[i] = '_'
+		}
+	}
+	return string(key)
+}
@@ -0,0 +1,43 @@
+package logrus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCEFFormatter(t *testing.T) {
+	formatter := &CEFFormatter{Vendor: "Acme", Product: "Web|App", Version: "1.0", SignatureIDKey: "event"}
+
+	entry := WithFields(Fields{"event": "login", "user": "alice", "query": "a=b\\c\nd", "src ip": "10.0.0.1"})
+	entry.Time = time.Date(2018, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	entry.Level = WarnLevel
+	entry.Message = "failed | login"
+
+	b, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	expected := `CEF:0|Acme|Web\|App|1.0|login|failed \| login|5|rt=1514862245006 query=a\=b\\c\nd src_ip=10.0.0.1 user=alice` + "\n"
+	if string(b) != expected {
+		t.Errorf("Expected %q, got %q", expected, b)
+	}
+}
+
+func TestCEFFormatterSignatureIDDefaultsToLevel(t *testing.T) {
+	formatter := &CEFFormatter{Vendor: "Acme", Product: "App", Version: "1.0", DisableTimestamp: true}
+
+	entry := WithField("user", "alice")
+	entry.Level = ErrorLevel
+	entry.Message = "hello"
+
+	b, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	expected := "CEF:0|Acme|App|1.0|error|hello|7|user=alice\n"
+	if string(b) != expected {
+		t.Errorf("Expected %q, got %q", expected, b)
+	}
+}
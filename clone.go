@@ -0,0 +1,136 @@
+package logrus
+
+import (
+	"io"
+	"time"
+)
+
+// Clone returns an independent copy of the logger, with its own level, hooks,
+// outputs and settings, so a component can add hooks to it or change its
+// level without affecting the logger, and vice versa. The sampling, rate
+// limit, duplicate suppression, statistics, async hooks and hook policies
+// are copied without their state, e.g. the clone's counters start from zero.
+// The writers, formatters and hooks themselves are shared, so closing either
+// logger closes them for both.
+func (logger *Logger) Clone() *Logger {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	clone := &Logger{
+		Out:                logger.Out,
+		Hooks:              make(LevelHooks, len(logger.Hooks)),
+		Formatter:          logger.Formatter,
+		Level:              logger.level(),
+		contextExtractors:  append([]ContextExtractor(nil), logger.contextExtractors...),
+		traceIDGeneration:  logger.traceIDGeneration,
+		middlewares:        append([]Middleware(nil), logger.middlewares...),
+		redactor:           logger.redactor,
+		scrubber:           logger.scrubber,
+		outputs:            append([]*output(nil), logger.outputs...),
+		ReportCaller:       logger.ReportCaller,
+		CallerSkip:         logger.CallerSkip,
+		CallerTrimPrefixes: append([]string(nil), logger.CallerTrimPrefixes...),
+		CallerPrettyfier:   logger.CallerPrettyfier,
+		ReportStack:        logger.ReportStack,
+		StackDepth:         logger.StackDepth,
+		ExitFunc:           logger.ExitFunc,
+		DemoteFatal:        logger.DemoteFatal,
+		PanicFlushTimeout:  logger.PanicFlushTimeout,
+		RepanicRecovered:   logger.RepanicRecovered,
+		UnwrapErrors:       logger.UnwrapErrors,
+	}
+	for level, hooks := range logger.Hooks {
+		clone.Hooks[level] = append([]Hook(nil), hooks...)
+	}
+	if logger.nop != nil {
+		clone.nop = &Entry{Logger: clone, disabled: true}
+	}
+
+	if s := logger.sampler; s != nil {
+		clone.sampler = &sampler{
+			initial:    s.initial,
+			thereafter: s.thereafter,
+			keyField:   s.keyField,
+			counts:     make(map[sampleKey]*sampleCount),
+		}
+	}
+	if l := logger.limiter; l != nil {
+		clone.limiter = &rateLimiter{rate: l.rate, burst: l.burst, tokens: l.burst}
+	}
+	if d := logger.deduper; d != nil {
+		clone.deduper = &deduper{logger: clone, window: d.window}
+	}
+	if logger.stats != nil {
+		clone.stats = &Stats{Since: time.Now()}
+	}
+	if a := logger.asyncHooks; a != nil {
+		clone.asyncHooks = &asyncHooks{logger: clone, queueSize: a.queueSize, workers: map[Hook]*hookWorker{}}
+	}
+	if handler, _ := logger.hookErrorHandler.Load().(HookErrorHandler); handler != nil {
+		clone.hookErrorHandler.Store(handler)
+	}
+
+	p := &logger.hookPolicies
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(p.policies) > 0 {
+		clone.hookPolicies.policies = make(map[Hook]*hookPolicy, len(p.policies))
+		for hook, h := range p.policies {
+			clone.hookPolicies.policies[hook] = &hookPolicy{policy: h.policy}
+		}
+	}
+	return clone
+}
+
+// Option configures a logger derived with WithOptions. Any function changing
+// a logger is an option, e.g. to set its redaction.
+type Option func(*Logger)
+
+// WithOptions returns a clone of the logger with the options applied, see
+// Clone, leaving the logger unchanged:
+//
+//	dbLog := log.WithOptions(logrus.LevelOption(logrus.DebugLevel), logrus.HooksOption(queryHook))
+func (logger *Logger) WithOptions(options ...Option) *Logger {
+	clone := logger.Clone()
+	for _, option := range options {
+		option(clone)
+	}
+	return clone
+}
+
+// LevelOption sets the level of a derived logger.
+func LevelOption(level Level) Option {
+	return func(logger *Logger) {
+		logger.SetLevel(level)
+	}
+}
+
+// HooksOption adds hooks to a derived logger.
+func HooksOption(hooks ...Hook) Option {
+	return func(logger *Logger) {
+		for _, hook := range hooks {
+			logger.AddHook(hook)
+		}
+	}
+}
+
+// FormatterOption sets the formatter of a derived logger.
+func FormatterOption(formatter Formatter) Option {
+	return func(logger *Logger) {
+		logger.SetFormatter(formatter)
+	}
+}
+
+// OutputOption sets the output of a derived logger.
+func OutputOption(out io.Writer) Option {
+	return func(logger *Logger) {
+		logger.SetOut(out)
+	}
+}
+
+// ReportCallerOption sets whether a derived logger reports the callers.
+func ReportCallerOption(reportCaller bool) Option {
+	return func(logger *Logger) {
+		logger.SetReportCaller(reportCaller)
+	}
+}
@@ -0,0 +1,95 @@
+package logrus
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClone(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := New()
+	logger.Out = &buffer
+	logger.Formatter = new(JSONFormatter)
+	logger.SetLevel(WarnLevel)
+	hook := new(TestHook)
+	logger.AddHook(hook)
+	logger.SetRateLimit(1, 1)
+	logger.SetStatsEnabled(true)
+
+	clone := logger.Clone()
+	assert.Equal(t, WarnLevel, clone.level())
+	cloneHook := new(TestHook)
+	clone.AddHook(cloneHook)
+	clone.SetLevel(DebugLevel)
+
+	clone.Debug("clone")
+	logger.Debug("logger")
+	logger.Warn("logger")
+
+	fields := logLines(t, &buffer)
+	if assert.Len(t, fields, 2) {
+		assert.Equal(t, "clone", fields[0]["msg"])
+		assert.Equal(t, "logger", fields[1]["msg"])
+	}
+	assert.Equal(t, WarnLevel, logger.level())
+	assert.True(t, hook.Fired)
+	assert.True(t, cloneHook.Fired)
+	assert.Len(t, logger.Hooks[DebugLevel], 1)
+	assert.Len(t, clone.Hooks[DebugLevel], 2)
+	// the rate limits and counters are separate
+	assert.Equal(t, uint64(1), logger.Stats().Entries)
+	assert.Equal(t, uint64(1), clone.Stats().Entries)
+}
+
+func TestCloneHookPolicy(t *testing.T) {
+	logger := New()
+	hook := new(TestHook)
+	logger.AddHook(hook)
+	logger.SetHookPolicy(hook, HookPolicy{Timeout: time.Second, DisableAfter: 1})
+
+	clone := logger.Clone()
+	p := clone.hookPolicies.get(hook)
+	if assert.NotNil(t, p) {
+		assert.Equal(t, HookPolicy{Timeout: time.Second, DisableAfter: 1}, p.policy)
+		assert.True(t, p != logger.hookPolicies.get(hook))
+	}
+}
+
+func TestCloneNop(t *testing.T) {
+	clone := NewNop().Clone()
+	assert.False(t, clone.IsLevelEnabled(PanicLevel))
+	assert.True(t, clone.WithField("a", 1).Logger == clone)
+}
+
+func TestWithOptions(t *testing.T) {
+	var buffer, optionBuffer bytes.Buffer
+	logger := New()
+	logger.Out = &buffer
+
+	hook := new(TestHook)
+	derived := logger.WithOptions(
+		LevelOption(DebugLevel),
+		HooksOption(hook),
+		FormatterOption(new(JSONFormatter)),
+		OutputOption(&optionBuffer),
+		ReportCallerOption(true),
+		func(l *Logger) { l.UnwrapErrors = true },
+	)
+
+	derived.Debug("derived")
+	assert.True(t, hook.Fired)
+	assert.True(t, derived.UnwrapErrors)
+	fields := logLines(t, &optionBuffer)
+	if assert.Len(t, fields, 1) {
+		assert.Equal(t, "derived", fields[0]["msg"])
+		assert.Contains(t, fields[0], FieldKeyFunc)
+	}
+
+	assert.Equal(t, InfoLevel, logger.level())
+	assert.Empty(t, logger.Hooks)
+	assert.False(t, logger.ReportCaller)
+	assert.Zero(t, buffer.Len())
+}
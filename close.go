@@ -0,0 +1,66 @@
+package logrus
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// closer closes hooks and writers once each, though e.g. a hook is added for
+// several levels, collecting their errors.
+type closer struct {
+	closed map[interface{}]bool
+	errs   closeError
+}
+
+func (c *closer) close(v io.Closer) {
+	if v == os.Stdout || v == os.Stderr {
+		return
+	}
+	if reflect.TypeOf(v).Comparable() {
+		if c.closed[v] {
+			return
+		}
+		if c.closed == nil {
+			c.closed = map[interface{}]bool{}
+		}
+		c.closed[v] = true
+	}
+	if err := v.Close(); err != nil {
+		c.errs = append(c.errs, err)
+	}
+}
+
+// keep marks v as closed, so it isn't.
+func (c *closer) keep(v interface{}) {
+	if reflect.TypeOf(v).Comparable() {
+		if c.closed == nil {
+			c.closed = map[interface{}]bool{}
+		}
+		c.closed[v] = true
+	}
+}
+
+func (c *closer) err() error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return c.errs
+}
+
+// closeError is the errors of closing the hooks and writers of a logger.
+type closeError []error
+
+func (e closeError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors, for errors.Is and errors.As.
+func (e closeError) Unwrap() []error {
+	return e
+}
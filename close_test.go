@@ -0,0 +1,92 @@
+package logrus
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// closingHook counts its closes, failing with err.
+type closingHook struct {
+	TestHook
+	closes int
+	err    error
+}
+
+func (hook *closingHook) Close() error {
+	hook.closes++
+	return hook.err
+}
+
+// closingWriter counts its closes, failing with err.
+type closingWriter struct {
+	bytes.Buffer
+	closes int
+	err    error
+}
+
+func (w *closingWriter) Close() error {
+	w.closes++
+	return w.err
+}
+
+func TestCloseOnce(t *testing.T) {
+	hook := &closingHook{}
+	out := &closingWriter{}
+	log := New()
+	log.Out = out
+	log.AddHook(hook)
+	log.AddOutput(out, InfoLevel, nil)
+
+	assert.NoError(t, log.Close())
+	assert.Equal(t, 1, hook.closes)
+	assert.Equal(t, 1, out.closes)
+
+	// closed already
+	assert.NoError(t, log.Close())
+	assert.Equal(t, 1, hook.closes)
+	assert.Equal(t, 1, out.closes)
+
+	// hooks can be added again
+	log.AddHook(&closingHook{})
+}
+
+func TestCloseErrors(t *testing.T) {
+	errHook, errOut := errors.New("hook failed"), errors.New("out failed")
+	hook := &closingHook{err: errHook}
+	out := &closingWriter{err: errOut}
+	log := New()
+	log.Out = out
+	log.AddHook(hook)
+
+	err := log.Close()
+	assert.EqualError(t, err, "hook failed; out failed")
+	assert.True(t, errors.Is(err, errHook))
+	assert.True(t, errors.Is(err, errOut))
+}
+
+func TestCloseKeepsStandardStreams(t *testing.T) {
+	log := New()
+	log.Out = os.Stderr
+	log.AddOutput(os.Stdout, InfoLevel, nil)
+
+	assert.NoError(t, log.Close())
+	_, err := os.Stderr.Stat()
+	assert.NoError(t, err)
+	_, err = os.Stdout.Stat()
+	assert.NoError(t, err)
+}
+
+func TestLevelHooksCloseOnce(t *testing.T) {
+	hooks := LevelHooks{}
+	hook, other := &closingHook{}, &closingHook{}
+	hooks.Add(hook)
+	hooks.Add(other)
+
+	assert.NoError(t, hooks.Close())
+	assert.Equal(t, 1, hook.closes)
+	assert.Equal(t, 1, other.closes)
+}
@@ -0,0 +1,108 @@
+package logrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCode(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = &JSONFormatter{DataKey: "fields"}
+
+	log.WithCode("AUTH-401").WithField("user", "bob").Warn("login failed")
+
+	fields := logLines(t, &buffer)
+	if assert.Len(t, fields, 1) {
+		assert.Equal(t, "AUTH-401", fields[0][FieldKeyCode])
+		assert.Equal(t, map[string]interface{}{"user": "bob"}, fields[0]["fields"])
+	}
+}
+
+func TestWithCodeFieldMap(t *testing.T) {
+	entry := NewEntry(New()).WithCode("AUTH-401")
+	b, err := (&JSONFormatter{FieldMap: FieldMap{FieldKeyCode: "event_code"}}).Format(entry)
+	assert.NoError(t, err)
+	var fields Fields
+	assert.NoError(t, json.Unmarshal(b, &fields))
+	assert.Equal(t, "AUTH-401", fields["event_code"])
+	assert.NotContains(t, fields, FieldKeyCode)
+}
+
+func TestWithCodeText(t *testing.T) {
+	entry := NewEntry(New()).WithFields(Fields{"a": 1, "z": 2}).WithCode("AUTH-401")
+	entry.Message = "login failed"
+	b, err := (&TextFormatter{DisableColors: true, DisableTimestamp: true}).Format(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "level=panic msg=\"login failed\" event.code=AUTH-401 a=1 z=2\n", string(b))
+}
+
+func TestWithCodeECSAndCEF(t *testing.T) {
+	entry := NewEntry(New()).WithFields(Fields{"event": "login"}).WithCode("AUTH-401")
+	b, err := (&ECSFormatter{DisableTimestamp: true}).Format(entry)
+	assert.NoError(t, err)
+	var fields Fields
+	assert.NoError(t, json.Unmarshal(b, &fields))
+	assert.Equal(t, map[string]interface{}{"code": "AUTH-401"}, fields["event"])
+	assert.Equal(t, "login", fields["fields.event"])
+
+	b, err = (&CEFFormatter{Vendor: "v", Product: "p", Version: "1", DisableTimestamp: true}).Format(entry)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(b), "CEF:0|v|p|1|AUTH-401|"), string(b))
+	assert.NotContains(t, string(b), "event_code=")
+}
+
+func TestPlainCodeField(t *testing.T) {
+	entry := NewEntry(New()).WithFields(Fields{"a": 1, "code": 404})
+	entry.Message = "not found"
+
+	b, err := (&JSONFormatter{DataKey: "fields"}).Format(entry)
+	assert.NoError(t, err)
+	var fields Fields
+	assert.NoError(t, json.Unmarshal(b, &fields))
+	assert.NotContains(t, fields, "code")
+	assert.Equal(t, map[string]interface{}{"a": float64(1), "code": float64(404)}, fields["fields"])
+
+	b, err = (&TextFormatter{DisableColors: true, DisableTimestamp: true}).Format(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "level=panic msg=\"not found\" a=1 code=404\n", string(b))
+
+	b, err = (&CEFFormatter{Vendor: "v", Product: "p", Version: "1", DisableTimestamp: true}).Format(entry)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(b), "CEF:0|v|p|1|panic|"), string(b))
+}
+
+func TestRegisterEvent(t *testing.T) {
+	assert.NoError(t, RegisterEvent("TEST-1", Event{
+		Template:    "Login failed for {{.user}}",
+		Description: "A user failed to log in",
+	}))
+	assert.Error(t, RegisterEvent("TEST-2", Event{Template: "{{.user"}))
+
+	event, ok := LookupEvent("TEST-1")
+	assert.True(t, ok)
+	assert.Equal(t, "A user failed to log in", event.Description)
+	_, ok = LookupEvent("TEST-2")
+	assert.False(t, ok)
+	assert.Contains(t, Events(), "TEST-1")
+
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = &JSONFormatter{}
+	log.WithCode("TEST-1").WithField("user", "bob").Warn()
+	log.WithCode("TEST-1").WithField("user", "bob").Warn("explicit")
+	log.WithCode("UNKNOWN").Warn()
+
+	fields := logLines(t, &buffer)
+	if assert.Len(t, fields, 3) {
+		assert.Equal(t, "Login failed for bob", fields[0]["msg"])
+		assert.Equal(t, "explicit", fields[1]["msg"])
+		assert.Equal(t, "", fields[2]["msg"])
+	}
+}
@@ -0,0 +1,65 @@
+package logrus
+
+// When returns the entry if cond is true, or else an entry logging nothing,
+// on which WithField, WithFields, WithFieldsFn, WithError and WithContext do
+// nothing either. Its Fatal and Panic methods still exit and panic.
+//
+//	log.WithField("user", user).When(user.Admin).Info("admin login")
+func (entry *Entry) When(cond bool) *Entry {
+	if cond || entry.disabled {
+		return entry
+	}
+	return &Entry{Logger: entry.Logger, disabled: true}
+}
+
+// WithFieldsFn adds the fields returned by fn to the entry, calling fn only
+// if the entry logs, i.e. wasn't disabled by When or a gated builder like
+// Logger.IfDebug:
+//
+//	log.IfDebug().WithFieldsFn(func() logrus.Fields {
+//		return logrus.Fields{"state": dump(state)}
+//	}).Debug("state changed")
+func (entry *Entry) WithFieldsFn(fn func() Fields) *Entry {
+	if entry.disabled {
+		return entry
+	}
+	return entry.WithFields(fn())
+}
+
+// enabled reports whether the entry logs at level.
+func (entry *Entry) enabled(level Level) bool {
+	return !entry.disabled && entry.Logger.IsLevelEnabled(level)
+}
+
+// IfLevel returns an entry if the logger logs at level, or else an entry
+// logging nothing, see Entry.When, so fields of entries which wouldn't be
+// logged aren't built.
+func (logger *Logger) IfLevel(level Level) *Entry {
+	if logger.nop != nil {
+		return logger.nop
+	}
+	if !logger.IsLevelEnabled(level) {
+		return &Entry{Logger: logger, disabled: true}
+	}
+	return NewEntry(logger)
+}
+
+// IfDebug returns an entry if the logger logs at DebugLevel, see IfLevel.
+func (logger *Logger) IfDebug() *Entry {
+	return logger.IfLevel(DebugLevel)
+}
+
+// IfInfo returns an entry if the logger logs at InfoLevel, see IfLevel.
+func (logger *Logger) IfInfo() *Entry {
+	return logger.IfLevel(InfoLevel)
+}
+
+// IfWarn returns an entry if the logger logs at WarnLevel, see IfLevel.
+func (logger *Logger) IfWarn() *Entry {
+	return logger.IfLevel(WarnLevel)
+}
+
+// IfError returns an entry if the logger logs at ErrorLevel, see IfLevel.
+func (logger *Logger) IfError() *Entry {
+	return logger.IfLevel(ErrorLevel)
+}
@@ -0,0 +1,46 @@
+package logrus
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhen(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = new(JSONFormatter)
+
+	log.WithField("a", 1).When(false).WithField("b", 2).Info("skipped")
+	log.WithField("a", 1).When(true).WithField("b", 2).Info("logged")
+
+	lines := logLines(t, &buffer)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, "logged", lines[0]["msg"])
+	assert.Equal(t, float64(2), lines[0]["b"])
+
+	assert.Panics(t, func() { log.WithField("a", 1).When(false).Panic("test") })
+}
+
+func TestIfLevel(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = new(JSONFormatter)
+
+	calls := 0
+	fields := func() Fields {
+		calls++
+		return Fields{"state": "dump"}
+	}
+	log.IfDebug().WithFieldsFn(fields).Debug("state changed")
+	log.IfDebug().WithFieldsFn(fields).Info("not debug")
+	log.IfInfo().WithFieldsFn(fields).Info("state changed")
+
+	lines := logLines(t, &buffer)
+	assert.Equal(t, 1, calls)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, "dump", lines[0]["state"])
+}
@@ -0,0 +1,111 @@
+package logrus
+
+import (
+	"context"
+)
+
+// ContextExtractor returns the fields to add to entries logged with ctx, e.g.
+// a request ID or user ID stored in it.
+type ContextExtractor func(ctx context.Context) Fields
+
+// AddContextExtractor registers an extractor whose fields are added to every
+// entry logged with a context, see WithContext. Fields set on the entry take
+// precedence over extracted ones.
+func (logger *Logger) AddContextExtractor(extractor ContextExtractor) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	logger.contextExtractors = append(logger.contextExtractors, extractor)
+}
+
+// contextFieldsKey is the key of the fields of contexts, see
+// ContextWithFields.
+type contextFieldsKey struct{}
+
+// ContextWithFields returns a copy of ctx carrying fields, on top of the
+// fields ctx carries, so layers like HTTP middleware can add request metadata
+// once and every entry logged with the context has it:
+//
+//	ctx = logrus.ContextWithFields(ctx, logrus.Fields{"request_id": id})
+//	...
+//	logrus.FromContext(ctx).Info("Charged the card")
+//
+// Fields added later replace those of the same keys, the context extractors
+// replace them in turn and the entry's own fields take precedence over all.
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	parent := FieldsFromContext(ctx)
+	data := make(Fields, len(parent)+len(fields))
+	for k, v := range parent {
+		data[k] = v
+	}
+	for k, v := range fields {
+		data[k] = v
+	}
+	return context.WithValue(ctx, contextFieldsKey{}, data)
+}
+
+// FieldsFromContext returns the fields carried by ctx, see
+// ContextWithFields. They must not be modified.
+func FieldsFromContext(ctx context.Context) Fields {
+	fields, _ := ctx.Value(contextFieldsKey{}).(Fields)
+	return fields
+}
+
+// FromContext creates an entry from the standard logger with ctx, logged
+// with the fields ctx carries, see ContextWithFields.
+func FromContext(ctx context.Context) *Entry {
+	return std.WithContext(ctx)
+}
+
+// Adds a context to the log entry, note that it doesn't log until you call
+// Debug, Print, Info, Warn, Error, Fatal or Panic. The fields of the context,
+// see ContextWithFields, and of the context extractors are added when the
+// entry is logged.
+func (logger *Logger) WithContext(ctx context.Context) *Entry {
+	entry := logger.newEntry()
+	defer logger.releaseEntry(entry)
+	return entry.WithContext(ctx)
+}
+
+// Add a context to the Entry.
+func (entry *Entry) WithContext(ctx context.Context) *Entry {
+	if entry.disabled {
+		return entry
+	}
+	return &Entry{
+		Logger:  entry.Logger,
+		Data:    entry.copyData(0),
+		Time:    entry.Time,
+		Context: entry.Logger.withGeneratedTraceID(ctx),
+	}
+}
+
+// contextData returns the entry's fields with the fields of its context and
+// the fields extracted from it added.
+func (entry *Entry) contextData() Fields {
+	if entry.Context == nil {
+		return entry.Data
+	}
+
+	fields := FieldsFromContext(entry.Context)
+	entry.Logger.mu.Lock()
+	extractors := entry.Logger.contextExtractors
+	entry.Logger.mu.Unlock()
+	if len(fields) == 0 && len(extractors) == 0 {
+		return entry.Data
+	}
+
+	data := make(Fields, len(fields)+len(entry.Data))
+	for k, v := range fields {
+		data[k] = v
+	}
+	for _, extractor := range extractors {
+		for k, v := range extractor(entry.Context) {
+			data[k] = v
+		}
+	}
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	return data
+}
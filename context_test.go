@@ -0,0 +1,74 @@
+package logrus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type requestIDKey struct{}
+
+func TestWithContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "abc")
+
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.AddContextExtractor(func(ctx context.Context) Fields {
+			return Fields{"request_id": ctx.Value(requestIDKey{}), "user": "anonymous"}
+		})
+		log.WithContext(ctx).WithField("user", "alice").Info("test")
+	}, func(fields Fields) {
+		assert.Equal(t, "abc", fields["request_id"])
+		assert.Equal(t, "alice", fields["user"])
+	})
+}
+
+func TestWithoutContext(t *testing.T) {
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.AddContextExtractor(func(ctx context.Context) Fields {
+			return Fields{"request_id": ctx.Value(requestIDKey{})}
+		})
+		log.WithField("user", "alice").Info("test")
+	}, func(fields Fields) {
+		_, ok := fields["request_id"]
+		assert.False(t, ok)
+	})
+}
+
+func TestEntryWithContext(t *testing.T) {
+	ctx := context.Background()
+	entry := NewEntry(New()).WithField("user", "alice").WithContext(ctx)
+
+	assert.Equal(t, ctx, entry.Context)
+	assert.Equal(t, ctx, entry.WithField("key", "value").Context)
+	assert.Equal(t, "alice", entry.Data["user"])
+}
+
+func TestContextWithFields(t *testing.T) {
+	ctx := ContextWithFields(context.Background(), Fields{"request_id": "abc", "user": "anonymous"})
+	child := ContextWithFields(ctx, Fields{"user": "alice", "route": "/pay"})
+
+	assert.Equal(t, Fields{"request_id": "abc", "user": "anonymous"}, FieldsFromContext(ctx))
+	assert.Equal(t, Fields{"request_id": "abc", "user": "alice", "route": "/pay"}, FieldsFromContext(child))
+	assert.Nil(t, FieldsFromContext(context.Background()))
+
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.AddContextExtractor(func(ctx context.Context) Fields {
+			return Fields{"route": "extracted"}
+		})
+		log.WithContext(child).WithField("request_id", "override").Info("test")
+	}, func(fields Fields) {
+		assert.Equal(t, "override", fields["request_id"])
+		assert.Equal(t, "alice", fields["user"])
+		assert.Equal(t, "extracted", fields["route"])
+	})
+}
+
+func TestFromContext(t *testing.T) {
+	ctx := ContextWithFields(context.Background(), Fields{"request_id": "abc"})
+	entry := FromContext(ctx)
+
+	assert.True(t, entry.Logger == std)
+	assert.Equal(t, ctx, entry.Context)
+	assert.Equal(t, "abc", entry.contextData()["request_id"])
+}
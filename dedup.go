@@ -0,0 +1,124 @@
+package logrus
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+)
+
+// deduper collapses consecutive identical entries, see
+// Logger.SetDuplicateSuppression.
+type deduper struct {
+	logger *Logger
+	window time.Duration
+
+	mu       sync.Mutex
+	last     uint64
+	level    Level
+	repeated int
+	timer    *time.Timer
+}
+
+// SetDuplicateSuppression drops entries identical to the previous one in
+// level, message and fields, and logs "last message repeated N times" with
+// the number in the `repeated` field instead, once the next different entry
+// is logged or window after the first duplicate, like syslog daemons do.
+// Fatal and Panic entries are never dropped. A non-positive window disables
+// the suppression.
+func (logger *Logger) SetDuplicateSuppression(window time.Duration) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	if logger.deduper != nil {
+		logger.deduper.stop()
+	}
+	if window <= 0 {
+		logger.deduper = nil
+		return
+	}
+	logger.deduper = &deduper{logger: logger, window: window}
+}
+
+// check reports whether entry is logged, and returns the summary of the
+// duplicates of the previous entry to log before it, if any.
+func (d *deduper) check(entry *Entry) (*Entry, bool) {
+	h := entryHash(entry)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if h == d.last {
+		d.repeated++
+		if d.timer == nil {
+			d.timer = time.AfterFunc(d.window, d.flush)
+		}
+		return nil, false
+	}
+
+	summary := d.summary()
+	d.last = h
+	d.level = entry.Level
+	return summary, true
+}
+
+// flush logs the summary of the duplicates so far.
+func (d *deduper) flush() {
+	d.mu.Lock()
+	summary := d.summary()
+	d.mu.Unlock()
+
+	if summary != nil {
+		summary.emit()
+	}
+}
+
+// summary returns the summary of the duplicates of the last entry and resets
+// their count, or nil if there are none.
+// Must be called with d.mu held.
+func (d *deduper) summary() *Entry {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if d.repeated == 0 {
+		return nil
+	}
+
+	summary := &Entry{
+		Logger:  d.logger,
+		Data:    Fields{"repeated": d.repeated},
+		Time:    time.Now(),
+		Level:   d.level,
+		Message: fmt.Sprintf("last message repeated %d times", d.repeated),
+	}
+	d.repeated = 0
+	return summary
+}
+
+func (d *deduper) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+}
+
+// entryHash returns a hash of the level, message and fields of entry.
+func entryHash(entry *Entry) uint64 {
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d\x00%s", entry.Level, entry.Message)
+	for _, k := range keys {
+		fmt.Fprintf(h, "\x00%s=%v", k, entry.Data[k])
+	}
+	return h.Sum64()
+}
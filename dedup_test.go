@@ -0,0 +1,78 @@
+package logrus
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu sync.Mutex
+	bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Buffer.String()
+}
+
+func TestDuplicateSuppression(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = new(JSONFormatter)
+	log.SetDuplicateSuppression(time.Hour)
+
+	for i := 0; i < 4; i++ {
+		log.WithField("a", 1).Warn("disk full")
+	}
+	log.WithField("a", 2).Warn("disk full")
+
+	lines := logLines(t, &buffer)
+	if assert.Len(t, lines, 3) {
+		assert.Equal(t, "disk full", lines[0]["msg"])
+		assert.Equal(t, "last message repeated 3 times", lines[1]["msg"])
+		assert.Equal(t, float64(3), lines[1]["repeated"])
+		assert.Equal(t, "warning", lines[1]["level"])
+		assert.Equal(t, float64(2), lines[2]["a"])
+	}
+}
+
+func TestDuplicateSuppressionWindow(t *testing.T) {
+	var buffer syncBuffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = new(JSONFormatter)
+	log.SetDuplicateSuppression(10 * time.Millisecond)
+
+	log.Info("tick")
+	log.Info("tick")
+	log.Info("tick")
+
+	time.Sleep(100 * time.Millisecond)
+
+	lines := logLines(t, bytes.NewBufferString(buffer.String()))
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, float64(2), lines[1]["repeated"])
+	}
+}
+
+func TestEntryHash(t *testing.T) {
+	a := &Entry{Data: Fields{"a": 1, "b": "x"}, Message: "m"}
+	b := &Entry{Data: Fields{"b": "x", "a": 1}, Message: "m"}
+	c := &Entry{Data: Fields{"a": 1, "b": "x"}, Message: "m", Level: InfoLevel}
+
+	assert.Equal(t, entryHash(a), entryHash(b))
+	assert.NotEqual(t, entryHash(a), entryHash(c))
+}
@@ -0,0 +1,131 @@
+package logrus
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ecsVersion is the version of the Elastic Common Schema the output follows.
+const ecsVersion = "1.6.0"
+
+// ecsTimestampFormat is the millisecond precision timestamp ECS expects.
+const ecsTimestampFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// ECSFormatter formats logs into JSON following the Elastic Common Schema, so
+// they can be used by Elastic and Kibana dashboards as is. The level, message
+// and timestamp become `log.level`, `message` and `@timestamp`, an error in
+// the ErrorKey field becomes `error.message`, `error.type` and, for errors
+// with a stack trace like those of github.com/pkg/errors,
+// `error.stack_trace`, as does a reported stack. The classification of the
+// error (see WithErrorKind) is logged in the same `error` object. The caller,
+// if reported, becomes `log.origin` and the event code `event.code`. Other
+// fields are kept at the top level.
+type ECSFormatter struct {
+	// DisableTimestamp allows disabling automatic timestamps in output
+	DisableTimestamp bool
+}
+
+// Format renders a single log entry
+func (f *ECSFormatter) Format(entry *Entry) ([]byte, error) {
+	data := make(Fields, len(entry.Data)+5)
+	for k, v := range entry.Data {
+		switch k {
+		case "@timestamp", "message", "log", "ecs", "error":
+			// reserved by ECS
+			k = "fields." + k
+		}
+		if _, ok := v.(Stack); ok && k == FieldKeyStack {
+			// logged as error.stack_trace
+			continue
+		}
+		switch k {
+		case FieldKeyCode, FieldKeyErrorKind, FieldKeyErrorRetryable, FieldKeyErrorSource:
+			// logged as event.code and in the error object
+			continue
+		}
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		data[k] = v
+	}
+	if code, ok := entry.Data[FieldKeyCode]; ok {
+		if v, ok := data["event"]; ok {
+			data["fields.event"] = v
+		}
+		data["event"] = map[string]interface{}{"code": code}
+	}
+
+	if !f.DisableTimestamp {
+		data["@timestamp"] = entry.Time.Format(ecsTimestampFormat)
+	}
+	data["message"] = entry.Message
+	log := map[string]interface{}{"level": entry.Level.String()}
+	if entry.HasCaller() {
+		log["origin"] = ecsOrigin(entry)
+	}
+	data["log"] = log
+	data["ecs"] = map[string]interface{}{"version": ecsVersion}
+
+	var errorFields map[string]interface{}
+	if err, ok := entry.Data[ErrorKey].(error); ok {
+		delete(data, "fields."+ErrorKey)
+		errorFields = ecsError(err)
+	}
+	if stack, ok := entry.Data[FieldKeyStack].(Stack); ok {
+		if errorFields == nil {
+			errorFields = map[string]interface{}{}
+		}
+		if _, ok := errorFields["stack_trace"]; !ok {
+			errorFields["stack_trace"] = stack.String()
+		}
+	}
+	for _, k := range []string{FieldKeyErrorKind, FieldKeyErrorRetryable, FieldKeyErrorSource} {
+		if v, ok := entry.Data[k]; ok {
+			if errorFields == nil {
+				errorFields = map[string]interface{}{}
+			}
+			errorFields[strings.TrimPrefix(k, "error.")] = v
+		}
+	}
+	if errorFields != nil {
+		data["error"] = errorFields
+	}
+
+	b := entryBuffer(entry)
+	if err := json.NewEncoder(b).Encode(data); err != nil {
+		return nil, fmt.Errorf("Failed to marshal fields to JSON, %v", err)
+	}
+	return b.Bytes(), nil
+}
+
+// ecsOrigin returns the ECS log.origin fields of the entry's caller.
+func ecsOrigin(entry *Entry) map[string]interface{} {
+	function, file := entry.callerInfo()
+	if entry.Logger == nil || entry.Logger.CallerPrettyfier == nil {
+		// ECS has a separate field for the line
+		file = entry.callerFile()
+	}
+
+	return map[string]interface{}{
+		"function": function,
+		"file": map[string]interface{}{
+			"name": file,
+			"line": entry.Caller.Line,
+		},
+	}
+}
+
+// ecsError returns the ECS error fields of err.
+func ecsError(err error) map[string]interface{} {
+	fields := map[string]interface{}{
+		"message": err.Error(),
+		"type":    fmt.Sprintf("%T", err),
+	}
+
+	// errors with a stack trace print it with the + flag
+	if trace := fmt.Sprintf("%+v", err); trace != err.Error() {
+		fields["stack_trace"] = trace
+	}
+	return fields
+}
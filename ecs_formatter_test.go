@@ -0,0 +1,91 @@
+package logrus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// tracedError is an error printing a stack trace with the + flag.
+type tracedError struct{}
+
+func (tracedError) Error() string { return "wild walrus" }
+
+func (e tracedError) Format(s fmt.State, verb rune) {
+	fmt.Fprint(s, e.Error())
+	if s.Flag('+') {
+		fmt.Fprint(s, "\nmain.main\n\tmain.go:42")
+	}
+}
+
+func TestECSFormatter(t *testing.T) {
+	formatter := &ECSFormatter{}
+
+	entry := WithFields(Fields{"user": "alice", "message": "clash", ErrorKey: tracedError{}})
+	entry.Time = time.Date(2018, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	entry.Level = WarnLevel
+	entry.Message = "hello"
+
+	b, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	var fields struct {
+		Timestamp string `json:"@timestamp"`
+		Message   string `json:"message"`
+		Log       struct {
+			Level string `json:"level"`
+		} `json:"log"`
+		ECS struct {
+			Version string `json:"version"`
+		} `json:"ecs"`
+		Error struct {
+			Message    string `json:"message"`
+			Type       string `json:"type"`
+			StackTrace string `json:"stack_trace"`
+		} `json:"error"`
+		User          string `json:"user"`
+		FieldsMessage string `json:"fields.message"`
+	}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		t.Fatal("Unable to unmarshal formatted entry: ", err)
+	}
+
+	if fields.Timestamp != "2018-01-02T03:04:05.006Z" {
+		t.Errorf("Unexpected @timestamp %q", fields.Timestamp)
+	}
+	if fields.Message != "hello" || fields.Log.Level != "warning" || fields.ECS.Version == "" {
+		t.Errorf("Unexpected base fields in %s", b)
+	}
+	if fields.Error.Message != "wild walrus" || fields.Error.Type != "logrus.tracedError" || fields.Error.StackTrace == "" {
+		t.Errorf("Unexpected error fields in %s", b)
+	}
+	if fields.User != "alice" || fields.FieldsMessage != "clash" {
+		t.Errorf("Unexpected user fields in %s", b)
+	}
+}
+
+func TestECSFormatterPlainError(t *testing.T) {
+	formatter := &ECSFormatter{}
+
+	b, err := formatter.Format(WithError(errors.New("wild walrus")))
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	entry := make(map[string]interface{})
+	if err := json.Unmarshal(b, &entry); err != nil {
+		t.Fatal("Unable to unmarshal formatted entry: ", err)
+	}
+
+	errorFields, _ := entry["error"].(map[string]interface{})
+	if errorFields["message"] != "wild walrus" {
+		t.Errorf("Error field not set in %s", b)
+	}
+	if _, ok := errorFields["stack_trace"]; ok {
+		t.Errorf("Expected no stack trace in %s", b)
+	}
+}
@@ -2,14 +2,20 @@ package logrus
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"runtime"
 	"sync"
 	"time"
 )
 
 var bufferPool *sync.Pool
 
+// maxPooledBufferSize is the capacity over which buffers aren't reused.
+const maxPooledBufferSize = 64 << 10
+
 func init() {
 	bufferPool = &sync.Pool{
 		New: func() interface{} {
@@ -31,7 +37,7 @@ type Entry struct {
 	// Contains all the fields set by the user.
 	Data Fields
 
-	// Time at which the log entry was created
+	// Time at which the log entry was created, or the time set with WithTime
 	Time time.Time
 
 	// Level the log entry was logged at: Debug, Info, Warn, Error, Fatal or Panic
@@ -43,6 +49,20 @@ type Entry struct {
 
 	// When formatter is called in entry.log(), an Buffer may be set to entry
 	Buffer *bytes.Buffer
+
+	// Context the entry was created with, see WithContext
+	Context context.Context
+
+	// Calling method, with package name, set if the logger reports callers.
+	// Adapters of other logging APIs may set it before logging to report
+	// their caller instead.
+	Caller *runtime.Frame
+
+	// Whether the entry logs nothing, see When
+	disabled bool
+
+	// Fields added to Data when logged, see WithTypedFields
+	typed []Field
 }
 
 func NewEntry(logger *Logger) *Entry {
@@ -69,77 +89,250 @@ func (entry *Entry) String() (string, error) {
 }
 
 // Add an error as single field (using the key defined in ErrorKey) to the Entry.
+// If the logger unwraps errors, the fields of its chain are added instead.
 func (entry *Entry) WithError(err error) *Entry {
+	if entry.disabled {
+		return entry
+	}
+	if err != nil && entry.Logger != nil && entry.Logger.UnwrapErrors {
+		return entry.WithFields(errorChainFields(err))
+	}
 	return entry.WithField(ErrorKey, err)
 }
 
 // Add a single field to the Entry.
 func (entry *Entry) WithField(key string, value interface{}) *Entry {
-	return entry.WithFields(Fields{key: value})
+	if entry.disabled {
+		return entry
+	}
+	data := entry.copyData(1)
+	data[key] = value
+
+	return &Entry{
+		Logger:  entry.Logger,
+		Data:    data,
+		Time:    entry.Time,
+		Context: entry.Context,
+	}
 }
 
 // Add a map of fields to the Entry.
 func (entry *Entry) WithFields(fields Fields) *Entry {
-	data := make(Fields, len(entry.Data)+len(fields))
-	for k, v := range entry.Data {
-		data[k] = v
+	if entry.disabled {
+		return entry
 	}
+	data := entry.copyData(len(fields))
 	for k, v := range fields {
 		data[k] = v
 	}
 
 	return &Entry{
-		Logger: entry.Logger,
-		Data:   data,
+		Logger:  entry.Logger,
+		Data:    data,
+		Time:    entry.Time,
+		Context: entry.Context,
+	}
+}
+
+// WithTime sets the time of the entry, logged instead of the time of logging,
+// e.g. for replayed or imported events keeping their original timestamps.
+// The zero time logs the time of logging.
+func (entry *Entry) WithTime(t time.Time) *Entry {
+	if entry.disabled {
+		return entry
+	}
+	return &Entry{
+		Logger:  entry.Logger,
+		Data:    entry.copyData(0),
+		Time:    t,
+		Context: entry.Context,
 	}
 }
 
 // This function is not declared with a pointer value because otherwise
 // race conditions will occur when using multiple goroutines
 func (entry Entry) log(level Level, msg string) {
-	var buffer *bytes.Buffer
-	entry.Time = time.Now()
+	logged := entry.logNoPanic(level, msg)
+
+	// To avoid Entry#log() returning a value that only would make sense for
+	// panic() to use in Entry#Panic(), we avoid the allocation by checking
+	// directly here.
+	if level <= PanicLevel {
+		panic(logged)
+	}
+}
+
+// logNoPanic logs like log, without panicking for PanicLevel, and returns the
+// entry as logged.
+func (entry Entry) logNoPanic(level Level, msg string) *Entry {
+	// sampling and rate limiting go by the time of logging, not WithTime's
+	if len(entry.typed) > 0 {
+		entry.Data, entry.typed = entry.copyData(0), nil
+	}
+	now := time.Now()
+	if entry.Time.IsZero() {
+		entry.Time = now
+	}
 	entry.Level = level
 	entry.Message = msg
+	entry.Data = entry.contextData()
 
+	entry.Logger.mu.Lock()
+	reportCaller, callerSkip := entry.Logger.ReportCaller, entry.Logger.CallerSkip
+	reportStack, stackDepth := entry.Logger.ReportStack, entry.Logger.StackDepth
+	sampler, limiter := entry.Logger.sampler, entry.Logger.limiter
+	deduper, redactor := entry.Logger.deduper, entry.Logger.redactor
+	scrubber, middlewares := entry.Logger.scrubber, entry.Logger.middlewares
+	entry.Logger.mu.Unlock()
+
+	if sampler != nil && level > FatalLevel {
+		data, ok := sampler.sample(&entry, now)
+		if !ok {
+			return &entry
+		}
+		entry.Data = data
+	}
+	if limiter != nil && level > FatalLevel {
+		suppressed, ok := limiter.allow(now)
+		if !ok {
+			return &entry
+		}
+		if suppressed > 0 {
+			summary := &Entry{
+				Logger:  entry.Logger,
+				Data:    Fields{"suppressed": suppressed},
+				Time:    now,
+				Level:   WarnLevel,
+				Message: fmt.Sprintf("Suppressed %d entries over the rate limit", suppressed),
+			}
+			summary.emit()
+		}
+	}
+	entry.Data = resolveLazy(entry.Data)
+	if entry.Message == "" {
+		entry.Message = entry.eventMessage()
+	}
+	if len(middlewares) > 0 {
+		next := entry.runMiddlewares(middlewares)
+		if next == nil {
+			return &entry
+		}
+		entry = *next
+	}
+	if redactor != nil {
+		entry.Data, _ = redactor.redact(entry.Data)
+	}
+	if scrubber != nil {
+		entry = *scrubber.Scrub(&entry)
+	}
+	if deduper != nil && level > FatalLevel {
+		summary, ok := deduper.check(&entry)
+		if summary != nil {
+			summary.emit()
+		}
+		if !ok {
+			return &entry
+		}
+	}
+
+	if !reportCaller {
+		entry.Caller = nil
+	} else if entry.Caller == nil {
+		entry.Caller = getCaller(callerSkip)
+	}
+	if reportStack && level.Builtin() <= ErrorLevel {
+		entry.Data = entry.withStack(stackDepth)
+	}
+
+	entry.emit()
+	return &entry
+}
+
+// emit fires the hooks for the entry and writes it.
+func (entry *Entry) emit() {
 	entry.fireHooks()
 
-	buffer = bufferPool.Get().(*bytes.Buffer)
+	buffer := bufferPool.Get().(*bytes.Buffer)
 	buffer.Reset()
-	defer bufferPool.Put(buffer)
 	entry.Buffer = buffer
 
 	entry.write()
 
 	entry.Buffer = nil
-
-	// To avoid Entry#log() returning a value that only would make sense for
-	// panic() to use in Entry#Panic(), we avoid the allocation by checking
-	// directly here.
-	if level <= PanicLevel {
-		panic(&entry)
+	// don't keep the memory of an occasional huge entry
+	if buffer.Cap() <= maxPooledBufferSize {
+		bufferPool.Put(buffer)
 	}
 }
 
-// This function is not declared with a pointer value because otherwise
-// race conditions will occur when using multiple goroutines
-func (entry Entry) fireHooks() {
+// Hooks may replace the message and fields of the entry, which is the copy
+// made by log.
+func (entry *Entry) fireHooks() {
 	entry.Logger.mu.Lock()
 	defer entry.Logger.mu.Unlock()
-	err := entry.Logger.Hooks.Fire(entry.Level, &entry)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to fire hook: %v\n", err)
+	if entry.Level == PanicLevel && entry.Logger.PanicFlushTimeout > 0 {
+		entry.Logger.firePanicHooks(entry, entry.Logger.PanicFlushTimeout)
+		return
+	}
+	if async := entry.Logger.asyncHooks; async != nil {
+		if entry.Level != FatalLevel && entry.Level != PanicLevel {
+			async.fire(entry.Logger.Hooks, entry)
+			return
+		}
+		async.flush()
+	}
+	for _, hook := range entry.Logger.Hooks[entry.Level] {
+		entry.Logger.fireHook(hook, entry)
 	}
 }
 
 func (entry *Entry) write() {
-	serialized, err := entry.Logger.Formatter.Format(entry)
+	// Reconfigure and Close wait for the entries being written before
+	// closing the writers
+	entry.Logger.writes.RLock()
+	defer entry.Logger.writes.RUnlock()
+
+	entry.Logger.mu.Lock()
+	out, outputs, loggerFormatter := entry.Logger.Out, entry.Logger.outputs, entry.Logger.Formatter
+	if stats := entry.Logger.stats; stats != nil {
+		stats.Entries++
+	}
+	entry.Logger.mu.Unlock()
+
+	if out != nil {
+		entry.writeTo(nil, loggerFormatter)
+	}
+	for _, o := range outputs {
+		if !o.enabled(entry.Level) {
+			continue
+		}
+		formatter := o.formatter
+		if formatter == nil {
+			formatter = loggerFormatter
+		}
+		entry.Buffer.Reset()
+		entry.writeTo(o.writer, formatter)
+	}
+}
+
+// writeTo writes the entry to w, or to the logger's Out if nil.
+func (entry *Entry) writeTo(w io.Writer, formatter Formatter) {
+	serialized, err := formatter.Format(entry)
 	entry.Logger.mu.Lock()
 	defer entry.Logger.mu.Unlock()
+	if w == nil {
+		if w = entry.Logger.Out; w == nil {
+			return
+		}
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to obtain reader, %v\n", err)
 	} else {
-		_, err = entry.Logger.Out.Write(serialized)
+		var n int
+		n, err = w.Write(serialized)
+		if stats := entry.Logger.stats; stats != nil {
+			stats.Bytes += uint64(n)
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
 		}
@@ -147,7 +340,7 @@ func (entry *Entry) write() {
 }
 
 func (entry *Entry) Debug(args ...interface{}) {
-	if entry.Logger.level() >= DebugLevel {
+	if entry.enabled(DebugLevel) {
 		entry.log(DebugLevel, fmt.Sprint(args...))
 	}
 }
@@ -157,13 +350,13 @@ func (entry *Entry) Print(args ...interface{}) {
 }
 
 func (entry *Entry) Info(args ...interface{}) {
-	if entry.Logger.level() >= InfoLevel {
+	if entry.enabled(InfoLevel) {
 		entry.log(InfoLevel, fmt.Sprint(args...))
 	}
 }
 
 func (entry *Entry) Warn(args ...interface{}) {
-	if entry.Logger.level() >= WarnLevel {
+	if entry.enabled(WarnLevel) {
 		entry.log(WarnLevel, fmt.Sprint(args...))
 	}
 }
@@ -173,20 +366,17 @@ func (entry *Entry) Warning(args ...interface{}) {
 }
 
 func (entry *Entry) Error(args ...interface{}) {
-	if entry.Logger.level() >= ErrorLevel {
+	if entry.enabled(ErrorLevel) {
 		entry.log(ErrorLevel, fmt.Sprint(args...))
 	}
 }
 
 func (entry *Entry) Fatal(args ...interface{}) {
-	if entry.Logger.level() >= FatalLevel {
-		entry.log(FatalLevel, fmt.Sprint(args...))
-	}
-	entry.Logger.Exit(1)
+	entry.fatal(func() string { return fmt.Sprint(args...) })
 }
 
 func (entry *Entry) Panic(args ...interface{}) {
-	if entry.Logger.level() >= PanicLevel {
+	if entry.enabled(PanicLevel) {
 		entry.log(PanicLevel, fmt.Sprint(args...))
 	}
 	panic(fmt.Sprint(args...))
@@ -195,13 +385,13 @@ func (entry *Entry) Panic(args ...interface{}) {
 // Entry Printf family functions
 
 func (entry *Entry) Debugf(format string, args ...interface{}) {
-	if entry.Logger.level() >= DebugLevel {
+	if entry.enabled(DebugLevel) {
 		entry.Debug(fmt.Sprintf(format, args...))
 	}
 }
 
 func (entry *Entry) Infof(format string, args ...interface{}) {
-	if entry.Logger.level() >= InfoLevel {
+	if entry.enabled(InfoLevel) {
 		entry.Info(fmt.Sprintf(format, args...))
 	}
 }
@@ -211,7 +401,7 @@ func (entry *Entry) Printf(format string, args ...interface{}) {
 }
 
 func (entry *Entry) Warnf(format string, args ...interface{}) {
-	if entry.Logger.level() >= WarnLevel {
+	if entry.enabled(WarnLevel) {
 		entry.Warn(fmt.Sprintf(format, args...))
 	}
 }
@@ -221,34 +411,30 @@ func (entry *Entry) Warningf(format string, args ...interface{}) {
 }
 
 func (entry *Entry) Errorf(format string, args ...interface{}) {
-	if entry.Logger.level() >= ErrorLevel {
+	if entry.enabled(ErrorLevel) {
 		entry.Error(fmt.Sprintf(format, args...))
 	}
 }
 
 func (entry *Entry) Fatalf(format string, args ...interface{}) {
-	if entry.Logger.level() >= FatalLevel {
-		entry.Fatal(fmt.Sprintf(format, args...))
-	}
-	entry.Logger.Exit(1)
+	entry.fatal(func() string { return fmt.Sprintf(format, args...) })
 }
 
 func (entry *Entry) Panicf(format string, args ...interface{}) {
-	if entry.Logger.level() >= PanicLevel {
-		entry.Panic(fmt.Sprintf(format, args...))
-	}
+	// panics even if the level is disabled, like Panic
+	entry.Panic(fmt.Sprintf(format, args...))
 }
 
 // Entry Println family functions
 
 func (entry *Entry) Debugln(args ...interface{}) {
-	if entry.Logger.level() >= DebugLevel {
+	if entry.enabled(DebugLevel) {
 		entry.Debug(entry.sprintlnn(args...))
 	}
 }
 
 func (entry *Entry) Infoln(args ...interface{}) {
-	if entry.Logger.level() >= InfoLevel {
+	if entry.enabled(InfoLevel) {
 		entry.Info(entry.sprintlnn(args...))
 	}
 }
@@ -258,7 +444,7 @@ func (entry *Entry) Println(args ...interface{}) {
 }
 
 func (entry *Entry) Warnln(args ...interface{}) {
-	if entry.Logger.level() >= WarnLevel {
+	if entry.enabled(WarnLevel) {
 		entry.Warn(entry.sprintlnn(args...))
 	}
 }
@@ -268,22 +454,17 @@ func (entry *Entry) Warningln(args ...interface{}) {
 }
 
 func (entry *Entry) Errorln(args ...interface{}) {
-	if entry.Logger.level() >= ErrorLevel {
+	if entry.enabled(ErrorLevel) {
 		entry.Error(entry.sprintlnn(args...))
 	}
 }
 
 func (entry *Entry) Fatalln(args ...interface{}) {
-	if entry.Logger.level() >= FatalLevel {
-		entry.Fatal(entry.sprintlnn(args...))
-	}
-	entry.Logger.Exit(1)
+	entry.fatal(func() string { return entry.sprintlnn(args...) })
 }
 
 func (entry *Entry) Panicln(args ...interface{}) {
-	if entry.Logger.level() >= PanicLevel {
-		entry.Panic(entry.sprintlnn(args...))
-	}
+	entry.Panic(entry.sprintlnn(args...))
 }
 
 // Sprintlnn => Sprint no newline. This is to get the behavior of how
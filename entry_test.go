@@ -2,8 +2,10 @@ package logrus
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -117,3 +119,53 @@ func TestEntryHooksPanic(t *testing.T) {
 	entry := NewEntry(logger)
 	entry.Info(badMessage)
 }
+
+func TestEntryWithTime(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := New()
+	logger.Out = &buffer
+	logger.Formatter = &JSONFormatter{TimestampFormat: time.RFC3339}
+	hook := new(timeHook)
+	logger.AddHook(hook)
+
+	then := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	logger.WithTime(then).WithField("a", 1).WithContext(context.Background()).Info("replayed")
+	logger.WithTime(time.Time{}).Info("now")
+
+	fields := logLines(t, &buffer)
+	if assert.Len(t, fields, 2) {
+		assert.Equal(t, "2020-01-02T03:04:05Z", fields[0]["time"])
+		assert.NotEqual(t, "2020-01-02T03:04:05Z", fields[1]["time"])
+	}
+	if assert.Len(t, hook.times, 2) {
+		assert.Equal(t, then, hook.times[0])
+	}
+}
+
+// timeHook records the times of the entries.
+type timeHook struct {
+	TestHook
+	times []time.Time
+}
+
+func (hook *timeHook) Fire(entry *Entry) error {
+	hook.times = append(hook.times, entry.Time)
+	return nil
+}
+
+func TestEntryWithTimeRateLimit(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := New()
+	logger.Out = &buffer
+	logger.Formatter = new(JSONFormatter)
+	logger.SetRateLimit(1000, 2)
+
+	// old timestamps don't drain the bucket
+	then := time.Now().Add(-time.Hour)
+	for i := 0; i < 2; i++ {
+		logger.WithTime(then).Info("replayed")
+	}
+	time.Sleep(10 * time.Millisecond)
+	logger.WithTime(then).Info("replayed")
+	assert.Len(t, logLines(t, &buffer), 3)
+}
@@ -0,0 +1,98 @@
+package logrus
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// The environment variables read by ConfigureFromEnv.
+const (
+	// The level, e.g. "debug"
+	EnvLevel = "LOG_LEVEL"
+	// The format: "json", "text" or "logfmt", text without colors
+	EnvFormat = "LOG_FORMAT"
+	// Whether text is colored: "always", "never" or "auto", also as booleans
+	EnvColor = "LOG_COLOR"
+	// Whether callers are reported, as a boolean
+	EnvReportCaller = "LOG_REPORT_CALLER"
+	// The Go layout of timestamps, e.g. "2006-01-02T15:04:05.000Z07:00"
+	EnvTimestampFormat = "LOG_TIMESTAMP_FORMAT"
+)
+
+// ConfigureFromEnv configures logger from the environment variables
+// LOG_LEVEL, LOG_FORMAT, LOG_COLOR, LOG_REPORT_CALLER and
+// LOG_TIMESTAMP_FORMAT, as twelve-factor apps expect. Unset or empty
+// variables leave the logger as is. Color and timestamp settings modify the
+// logger's formatter, so call it before logging. It returns the error of the
+// first invalid variable, after applying the valid ones.
+func ConfigureFromEnv(logger *Logger) error {
+	var errs []error
+	fail := func(name string, err error) {
+		errs = append(errs, fmt.Errorf("%s: %v", name, err))
+	}
+
+	if value := os.Getenv(EnvLevel); value != "" {
+		if level, err := ParseLevel(value); err != nil {
+			fail(EnvLevel, err)
+		} else {
+			logger.SetLevel(level)
+		}
+	}
+
+	if value := os.Getenv(EnvReportCaller); value != "" {
+		if report, err := strconv.ParseBool(value); err != nil {
+			fail(EnvReportCaller, err)
+		} else {
+			logger.SetReportCaller(report)
+		}
+	}
+
+	formatter := logger.GetFormatter()
+	switch format := strings.ToLower(os.Getenv(EnvFormat)); format {
+	case "":
+	case "json":
+		formatter = &JSONFormatter{}
+	case "text":
+		formatter = &TextFormatter{}
+	case "logfmt":
+		formatter = &TextFormatter{DisableColors: true}
+	default:
+		fail(EnvFormat, fmt.Errorf("unknown format %q", format))
+	}
+
+	if value := os.Getenv(EnvColor); value != "" {
+		if f, ok := formatter.(*TextFormatter); ok {
+			switch strings.ToLower(value) {
+			case "always", "true", "1":
+				f.ForceColors, f.DisableColors = true, false
+			case "never", "false", "0":
+				f.ForceColors, f.DisableColors = false, true
+			case "auto":
+				f.ForceColors, f.DisableColors = false, false
+			default:
+				fail(EnvColor, fmt.Errorf("unknown color mode %q", value))
+			}
+		}
+	}
+
+	if layout := os.Getenv(EnvTimestampFormat); layout != "" {
+		switch f := formatter.(type) {
+		case *TextFormatter:
+			f.TimestampFormat, f.FullTimestamp = layout, true
+		case *JSONFormatter:
+			f.TimestampFormat = layout
+		case *CBORFormatter:
+			f.TimestampFormat = layout
+		case *MsgpackFormatter:
+			f.TimestampFormat = layout
+		}
+	}
+	logger.SetFormatter(formatter)
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package logrus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setenv(t *testing.T, env map[string]string) {
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+}
+
+func TestConfigureFromEnv(t *testing.T) {
+	setenv(t, map[string]string{
+		EnvLevel:           "debug",
+		EnvFormat:          "json",
+		EnvReportCaller:    "true",
+		EnvTimestampFormat: "15:04",
+	})
+	log := New()
+
+	assert.NoError(t, ConfigureFromEnv(log))
+	assert.Equal(t, DebugLevel, log.level())
+	assert.True(t, log.ReportCaller)
+	if assert.IsType(t, &JSONFormatter{}, log.Formatter) {
+		assert.Equal(t, "15:04", log.Formatter.(*JSONFormatter).TimestampFormat)
+	}
+}
+
+func TestConfigureFromEnvText(t *testing.T) {
+	setenv(t, map[string]string{
+		EnvFormat:          "logfmt",
+		EnvColor:           "always",
+		EnvTimestampFormat: "15:04",
+	})
+	log := New()
+
+	assert.NoError(t, ConfigureFromEnv(log))
+	f, ok := log.Formatter.(*TextFormatter)
+	if assert.True(t, ok) {
+		assert.True(t, f.ForceColors)
+		assert.False(t, f.DisableColors)
+		assert.True(t, f.FullTimestamp)
+		assert.Equal(t, "15:04", f.TimestampFormat)
+	}
+}
+
+func TestConfigureFromEnvUnset(t *testing.T) {
+	setenv(t, map[string]string{EnvLevel: "", EnvFormat: "", EnvColor: "", EnvReportCaller: "", EnvTimestampFormat: ""})
+	formatter := &JSONFormatter{}
+	log := New()
+	log.Formatter = formatter
+	log.SetLevel(WarnLevel)
+
+	assert.NoError(t, ConfigureFromEnv(log))
+	assert.Equal(t, WarnLevel, log.level())
+	assert.Equal(t, formatter, log.Formatter)
+	assert.False(t, log.ReportCaller)
+}
+
+func TestConfigureFromEnvErrors(t *testing.T) {
+	setenv(t, map[string]string{
+		EnvLevel:        "loud",
+		EnvFormat:       "xml",
+		EnvReportCaller: "true",
+	})
+	log := New()
+
+	err := ConfigureFromEnv(log)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), EnvLevel)
+	}
+	// the valid variables are applied
+	assert.True(t, log.ReportCaller)
+}
@@ -0,0 +1,49 @@
+package logrus
+
+import "fmt"
+
+// maximumErrorChain limits the errors unwrapped, in case of a cycle.
+const maximumErrorChain = 100
+
+// errorChainFields returns the ErrorKey+".message", ".type", ".stack" and
+// ".causes" fields of the chain of err, see Logger.UnwrapErrors.
+func errorChainFields(err error) Fields {
+	fields := Fields{
+		ErrorKey + ".message": err.Error(),
+		ErrorKey + ".type":    fmt.Sprintf("%T", err),
+	}
+
+	var causes []string
+	var stack string
+	for e := err; e != nil && len(causes) < maximumErrorChain; e = unwrap(e) {
+		if e != err {
+			causes = append(causes, e.Error())
+		}
+		// errors with a stack trace print it with the + flag; the
+		// innermost one is where the error occurred
+		if trace := fmt.Sprintf("%+v", e); trace != e.Error() {
+			stack = trace
+		}
+	}
+
+	if stack != "" {
+		fields[ErrorKey+".stack"] = stack
+	}
+	if len(causes) > 0 {
+		fields[ErrorKey+".causes"] = causes
+	}
+	return fields
+}
+
+// unwrap returns the error wrapped by err, supporting the Unwrap method of
+// the standard library and the Cause method of github.com/pkg/errors.
+func unwrap(err error) error {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	case interface{ Cause() error }:
+		return e.Cause()
+	default:
+		return nil
+	}
+}
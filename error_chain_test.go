@@ -0,0 +1,79 @@
+package logrus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type wrappedError struct {
+	msg   string
+	cause error
+}
+
+func (e *wrappedError) Error() string { return e.msg + ": " + e.cause.Error() }
+func (e *wrappedError) Unwrap() error { return e.cause }
+
+// causeError wraps errors like github.com/pkg/errors.
+type causeError struct {
+	msg   string
+	cause error
+}
+
+func (e *causeError) Error() string { return e.msg + ": " + e.cause.Error() }
+func (e *causeError) Cause() error  { return e.cause }
+
+type stackError struct {
+	msg string
+}
+
+func (e *stackError) Error() string { return e.msg }
+
+func (e *stackError) Format(s fmt.State, verb rune) {
+	if s.Flag('+') {
+		io.WriteString(s, e.msg+"\nmain.main\n\t/app/main.go:7")
+		return
+	}
+	io.WriteString(s, e.msg)
+}
+
+func TestUnwrapErrors(t *testing.T) {
+	root := &stackError{"no such file"}
+	err := &wrappedError{"read config", &causeError{"open", root}}
+
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.UnwrapErrors = true
+		log.WithError(err).Error("test")
+	}, func(fields Fields) {
+		assert.Equal(t, "read config: open: no such file", fields["error.message"])
+		assert.Equal(t, "*logrus.wrappedError", fields["error.type"])
+		assert.Equal(t, "no such file\nmain.main\n\t/app/main.go:7", fields["error.stack"])
+		assert.Equal(t, []interface{}{"open: no such file", "no such file"}, fields["error.causes"])
+		_, ok := fields["error"]
+		assert.False(t, ok)
+	})
+}
+
+func TestUnwrapErrorsWithoutChain(t *testing.T) {
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.UnwrapErrors = true
+		log.WithError(errors.New("flat")).Error("test")
+	}, func(fields Fields) {
+		assert.Equal(t, "flat", fields["error.message"])
+		_, ok := fields["error.causes"]
+		assert.False(t, ok)
+		_, ok = fields["error.stack"]
+		assert.False(t, ok)
+	})
+}
+
+func TestWithErrorWithoutUnwrapping(t *testing.T) {
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.WithError(&wrappedError{"read config", errors.New("no such file")}).Error("test")
+	}, func(fields Fields) {
+		assert.Equal(t, "read config: no such file", fields["error"])
+	})
+}
@@ -0,0 +1,114 @@
+package logrus
+
+// Keys of the fields classifying errors, see WithErrorKind.
+const (
+	FieldKeyErrorKind      = "error.kind"
+	FieldKeyErrorRetryable = "error.retryable"
+	FieldKeyErrorSource    = "error.source"
+)
+
+// Kinds of errors, a taxonomy shared by programs so their errors aggregate
+// uniformly, see WithErrorKind.
+const (
+	// Invalid input, e.g. a malformed request
+	ErrorKindValidation = "validation"
+	// Missing or invalid credentials
+	ErrorKindUnauthenticated = "unauthenticated"
+	// Valid credentials lacking permissions
+	ErrorKindForbidden = "forbidden"
+	// A missing resource
+	ErrorKindNotFound = "not_found"
+	// A conflict with the state of a resource, e.g. a duplicate
+	ErrorKindConflict = "conflict"
+	// Too many requests
+	ErrorKindRateLimited = "rate_limited"
+	// An operation running out of time
+	ErrorKindTimeout = "timeout"
+	// A dependency failing or unreachable
+	ErrorKindUnavailable = "unavailable"
+	// A bug or unexpected state
+	ErrorKindInternal = "internal"
+)
+
+// Sources of errors, see WithErrorSource.
+const (
+	// The caller, e.g. sending an invalid request
+	ErrorSourceClient = "client"
+	// The program itself
+	ErrorSourceServer = "server"
+	// A service or resource the program depends on
+	ErrorSourceDependency = "dependency"
+)
+
+// ErrorClass classifies an error, see WithErrorClass.
+type ErrorClass struct {
+	// Kind of the error, e.g. ErrorKindTimeout
+	Kind string
+	// Whether the operation may succeed if retried
+	Retryable bool
+	// Source of the error, e.g. ErrorSourceDependency, omitted if empty
+	Source string
+}
+
+// IsRetryableKind reports whether errors of kind are retryable by default:
+// rate limits, timeouts and unavailable dependencies are.
+func IsRetryableKind(kind string) bool {
+	switch kind {
+	case ErrorKindRateLimited, ErrorKindTimeout, ErrorKindUnavailable:
+		return true
+	}
+	return false
+}
+
+// WithErrorKind adds the kind of the entry's error, e.g. ErrorKindTimeout, in
+// the FieldKeyErrorKind field, and whether it's retryable by default for its
+// kind, see IsRetryableKind, in the FieldKeyErrorRetryable field:
+//
+//	log.WithError(err).WithErrorKind(logrus.ErrorKindTimeout).Error("Failed to fetch the profile")
+func (entry *Entry) WithErrorKind(kind string) *Entry {
+	return entry.WithFields(Fields{
+		FieldKeyErrorKind:      kind,
+		FieldKeyErrorRetryable: IsRetryableKind(kind),
+	})
+}
+
+// WithRetryable sets whether the entry's error is retryable, in the
+// FieldKeyErrorRetryable field.
+func (entry *Entry) WithRetryable(retryable bool) *Entry {
+	return entry.WithField(FieldKeyErrorRetryable, retryable)
+}
+
+// WithErrorSource adds the source of the entry's error, e.g.
+// ErrorSourceDependency, in the FieldKeyErrorSource field.
+func (entry *Entry) WithErrorSource(source string) *Entry {
+	return entry.WithField(FieldKeyErrorSource, source)
+}
+
+// WithErrorClass adds the classification of the entry's error, see
+// WithErrorKind.
+func (entry *Entry) WithErrorClass(class ErrorClass) *Entry {
+	fields := Fields{
+		FieldKeyErrorKind:      class.Kind,
+		FieldKeyErrorRetryable: class.Retryable,
+	}
+	if class.Source != "" {
+		fields[FieldKeyErrorSource] = class.Source
+	}
+	return entry.WithFields(fields)
+}
+
+// WithErrorKind creates an entry with the kind of an error, see
+// Entry.WithErrorKind.
+func (logger *Logger) WithErrorKind(kind string) *Entry {
+	entry := logger.newEntry()
+	defer logger.releaseEntry(entry)
+	return entry.WithErrorKind(kind)
+}
+
+// WithErrorClass creates an entry with the classification of an error, see
+// Entry.WithErrorClass.
+func (logger *Logger) WithErrorClass(class ErrorClass) *Entry {
+	entry := logger.newEntry()
+	defer logger.releaseEntry(entry)
+	return entry.WithErrorClass(class)
+}
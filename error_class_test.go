@@ -0,0 +1,72 @@
+package logrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithErrorKind(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = &JSONFormatter{}
+
+	log.WithErrorKind(ErrorKindTimeout).Error("a")
+	log.WithErrorKind(ErrorKindValidation).WithErrorSource(ErrorSourceClient).Error("b")
+	log.WithErrorKind(ErrorKindInternal).WithRetryable(true).Error("c")
+
+	fields := logLines(t, &buffer)
+	if assert.Len(t, fields, 3) {
+		assert.Equal(t, "timeout", fields[0][FieldKeyErrorKind])
+		assert.Equal(t, true, fields[0][FieldKeyErrorRetryable])
+		assert.NotContains(t, fields[0], FieldKeyErrorSource)
+
+		assert.Equal(t, "validation", fields[1][FieldKeyErrorKind])
+		assert.Equal(t, false, fields[1][FieldKeyErrorRetryable])
+		assert.Equal(t, "client", fields[1][FieldKeyErrorSource])
+
+		assert.Equal(t, true, fields[2][FieldKeyErrorRetryable])
+	}
+}
+
+func TestWithErrorClass(t *testing.T) {
+	entry := WithErrorClass(ErrorClass{Kind: "quota", Source: ErrorSourceClient})
+	assert.Equal(t, Fields{
+		FieldKeyErrorKind:      "quota",
+		FieldKeyErrorRetryable: false,
+		FieldKeyErrorSource:    "client",
+	}, entry.Data)
+
+	entry = WithErrorClass(ErrorClass{Kind: ErrorKindUnavailable, Retryable: true})
+	assert.NotContains(t, entry.Data, FieldKeyErrorSource)
+}
+
+func TestIsRetryableKind(t *testing.T) {
+	for _, kind := range []string{ErrorKindRateLimited, ErrorKindTimeout, ErrorKindUnavailable} {
+		assert.True(t, IsRetryableKind(kind), kind)
+	}
+	for _, kind := range []string{ErrorKindValidation, ErrorKindNotFound, ErrorKindInternal, "other"} {
+		assert.False(t, IsRetryableKind(kind), kind)
+	}
+}
+
+func TestErrorClassECS(t *testing.T) {
+	entry := NewEntry(New()).WithError(errors.New("refused")).
+		WithErrorKind(ErrorKindUnavailable).WithErrorSource(ErrorSourceDependency)
+	b, err := (&ECSFormatter{DisableTimestamp: true}).Format(entry)
+	assert.NoError(t, err)
+	var fields Fields
+	assert.NoError(t, json.Unmarshal(b, &fields))
+	assert.Equal(t, map[string]interface{}{
+		"message":   "refused",
+		"type":      "*errors.errorString",
+		"kind":      "unavailable",
+		"retryable": true,
+		"source":    "dependency",
+	}, fields["error"])
+	assert.NotContains(t, fields, FieldKeyErrorKind)
+}
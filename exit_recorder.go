@@ -0,0 +1,51 @@
+package logrus
+
+import "sync"
+
+// ExitRecorder records the exit codes of a logger instead of exiting, so
+// Fatal paths can be tested, see Logger.RecordExits.
+type ExitRecorder struct {
+	mu    sync.Mutex
+	codes []int
+}
+
+// RecordExits makes the logger record its exit codes, e.g. of Fatal entries,
+// in the returned recorder instead of exiting. The exit handlers still run,
+// and Fatal returns, so the code after it runs too:
+//
+//	exits := log.RecordExits()
+//	run(log)
+//	if code, ok := exits.Exited(); !ok || code != 1 {
+//		t.Error("run didn't exit")
+//	}
+func (logger *Logger) RecordExits() *ExitRecorder {
+	r := &ExitRecorder{}
+	logger.SetExitFunc(r.record)
+	return r
+}
+
+func (r *ExitRecorder) record(code int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.codes = append(r.codes, code)
+}
+
+// Exited returns the first exit code recorded, and whether there is one.
+func (r *ExitRecorder) Exited() (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.codes) == 0 {
+		return 0, false
+	}
+	return r.codes[0], true
+}
+
+// Codes returns the exit codes recorded.
+func (r *ExitRecorder) Codes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]int(nil), r.codes...)
+}
@@ -1,7 +1,9 @@
 package logrus
 
 import (
+	"context"
 	"io"
+	"time"
 )
 
 var (
@@ -34,6 +36,71 @@ func SetLevel(level Level) {
 	std.SetLevel(level)
 }
 
+// SetTraceIDGeneration sets whether the standard logger generates trace IDs
+// for contexts without one, see Logger.SetTraceIDGeneration.
+func SetTraceIDGeneration(enabled bool) {
+	std.SetTraceIDGeneration(enabled)
+}
+
+// SetReportCaller sets whether the standard logger reports callers.
+func SetReportCaller(reportCaller bool) {
+	std.SetReportCaller(reportCaller)
+}
+
+// SetSampling sets the sampling of the standard logger, see
+// Logger.SetSampling.
+func SetSampling(initial, thereafter int, keyField string) {
+	std.SetSampling(initial, thereafter, keyField)
+}
+
+// SetRateLimit limits the rate of entries of the standard logger, see
+// Logger.SetRateLimit.
+func SetRateLimit(eventsPerSecond float64, burst int) {
+	std.SetRateLimit(eventsPerSecond, burst)
+}
+
+// SetDuplicateSuppression collapses duplicate entries of the standard logger,
+// see Logger.SetDuplicateSuppression.
+func SetDuplicateSuppression(window time.Duration) {
+	std.SetDuplicateSuppression(window)
+}
+
+// SetAsyncHooks makes the standard logger fire hooks in the background, see
+// Logger.SetAsyncHooks.
+func SetAsyncHooks(queueSize int) {
+	std.SetAsyncHooks(queueSize)
+}
+
+// SetRedaction sets the redaction of the standard logger, see
+// Logger.SetRedaction.
+func SetRedaction(redaction Redaction) error {
+	return std.SetRedaction(redaction)
+}
+
+// SetExitFunc sets the function called by the standard logger to exit, see
+// Logger.ExitFunc.
+func SetExitFunc(exitFunc func(int)) {
+	std.SetExitFunc(exitFunc)
+}
+
+// SetDemoteFatal sets whether the standard logger logs Fatal entries at Error
+// level instead of exiting, see Logger.SetDemoteFatal.
+func SetDemoteFatal(demote bool) {
+	std.SetDemoteFatal(demote)
+}
+
+// SetPanicFlushTimeout bounds the time the standard logger fires the hooks
+// before panicking, see Logger.SetPanicFlushTimeout.
+func SetPanicFlushTimeout(timeout time.Duration) {
+	std.SetPanicFlushTimeout(timeout)
+}
+
+// Flush flushes the hooks and outputs of the standard logger, see
+// Logger.Flush.
+func Flush(ctx context.Context) error {
+	return std.Flush(ctx)
+}
+
 // GetLevel returns the standard logger level.
 func GetLevel() Level {
 	std.mu.Lock()
@@ -48,9 +115,56 @@ func AddHook(hook Hook) {
 	std.Hooks.Add(hook)
 }
 
+// AddContextExtractor adds a context extractor to the standard logger.
+func AddContextExtractor(extractor ContextExtractor) {
+	std.AddContextExtractor(extractor)
+}
+
 // WithError creates an entry from the standard logger and adds an error to it, using the value defined in ErrorKey as key.
 func WithError(err error) *Entry {
-	return std.WithField(ErrorKey, err)
+	return std.WithError(err)
+}
+
+// WithCode creates an entry from the standard logger and adds an event code
+// to it, see Entry.WithCode.
+func WithCode(code string) *Entry {
+	return std.WithCode(code)
+}
+
+// WithErrorKind creates an entry from the standard logger and adds the kind
+// of an error to it, see Entry.WithErrorKind.
+func WithErrorKind(kind string) *Entry {
+	return std.WithErrorKind(kind)
+}
+
+// WithErrorClass creates an entry from the standard logger and adds the
+// classification of an error to it, see Entry.WithErrorClass.
+func WithErrorClass(class ErrorClass) *Entry {
+	return std.WithErrorClass(class)
+}
+
+// WithDuration creates an entry from the standard logger and adds a duration
+// to it, see Entry.WithDuration.
+func WithDuration(key string, d time.Duration) *Entry {
+	return std.WithDuration(key, d)
+}
+
+// WithBytes creates an entry from the standard logger and adds a size to it,
+// see Entry.WithBytes.
+func WithBytes(key string, n int64) *Entry {
+	return std.WithBytes(key, n)
+}
+
+// WithTime creates an entry from the standard logger and sets its time, see
+// Entry.WithTime.
+func WithTime(t time.Time) *Entry {
+	return std.WithTime(t)
+}
+
+// WithContext creates an entry from the standard logger and adds a context to
+// it.
+func WithContext(ctx context.Context) *Entry {
+	return std.WithContext(ctx)
 }
 
 // WithField creates an entry from the standard logger and adds a field to
@@ -191,3 +305,66 @@ func Panicln(args ...interface{}) {
 func Fatalln(args ...interface{}) {
 	std.Fatalln(args...)
 }
+
+// DebugFn logs a message computed by fn at level Debug on the standard logger,
+// calling fn only if the level is enabled.
+func DebugFn(fn func() string) {
+	std.DebugFn(fn)
+}
+
+// InfoFn logs a message computed by fn at level Info on the standard logger,
+// calling fn only if the level is enabled.
+func InfoFn(fn func() string) {
+	std.InfoFn(fn)
+}
+
+// PrintFn logs a message computed by fn at level Info on the standard logger,
+// calling fn only if the level is enabled.
+func PrintFn(fn func() string) {
+	std.PrintFn(fn)
+}
+
+// WarnFn logs a message computed by fn at level Warn on the standard logger,
+// calling fn only if the level is enabled.
+func WarnFn(fn func() string) {
+	std.WarnFn(fn)
+}
+
+// WarningFn logs a message computed by fn at level Warn on the standard logger,
+// calling fn only if the level is enabled.
+func WarningFn(fn func() string) {
+	std.WarningFn(fn)
+}
+
+// ErrorFn logs a message computed by fn at level Error on the standard logger,
+// calling fn only if the level is enabled.
+func ErrorFn(fn func() string) {
+	std.ErrorFn(fn)
+}
+
+// FatalFn logs a message computed by fn at level Fatal on the standard logger,
+// calling fn only if the level is enabled.
+func FatalFn(fn func() string) {
+	std.FatalFn(fn)
+}
+
+// PanicFn logs a message computed by fn at level Panic on the standard logger,
+// calling fn only if the level is enabled.
+func PanicFn(fn func() string) {
+	std.PanicFn(fn)
+}
+
+// Log logs a message at level on the standard logger, see Logger.Log.
+func Log(level Level, args ...interface{}) {
+	std.Log(level, args...)
+}
+
+// Logf logs a message at level on the standard logger, see Logger.Logf.
+func Logf(level Level, format string, args ...interface{}) {
+	std.Logf(level, format, args...)
+}
+
+// Logln logs a message at level on the standard logger, see Logger.Logln.
+func Logln(level Level, args ...interface{}) {
+	std.Logln(level, args...)
+}
@@ -0,0 +1,85 @@
+package logrus
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// SetDemoteFatal sets whether Fatal entries are logged at Error level
+// instead, after which the hooks are flushed and Fatal returns instead of
+// exiting. It suits libraries embedded in long-running hosts, which must not
+// take the host down.
+func (logger *Logger) SetDemoteFatal(demote bool) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	logger.DemoteFatal = demote
+}
+
+// SetPanicFlushTimeout makes Panic fire the hooks, including the entries
+// queued for async hooks, for at most timeout before panicking, so a stuck
+// hook can't hang the panic. Hooks still firing then keep going in the
+// background. A non-positive timeout fires the hooks without a deadline.
+func (logger *Logger) SetPanicFlushTimeout(timeout time.Duration) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	logger.PanicFlushTimeout = timeout
+}
+
+// fatal logs the message built by msg at Fatal level and exits, or at Error
+// level if the logger demotes Fatal.
+func (entry *Entry) fatal(msg func() string) {
+	entry.Logger.mu.Lock()
+	demote := entry.Logger.DemoteFatal
+	entry.Logger.mu.Unlock()
+
+	if demote {
+		if entry.enabled(ErrorLevel) {
+			entry.log(ErrorLevel, msg())
+		}
+		entry.Logger.flushHooks()
+		return
+	}
+
+	if entry.enabled(FatalLevel) {
+		entry.log(FatalLevel, msg())
+	}
+	entry.Logger.Exit(1)
+}
+
+// flushHooks waits until the entries queued for async hooks are fired.
+func (logger *Logger) flushHooks() {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	if logger.asyncHooks != nil {
+		logger.asyncHooks.flush()
+	}
+}
+
+// firePanicHooks fires the hooks for a Panic entry after the queued entries,
+// giving up after timeout.
+// Must be called with the logger's mu held.
+func (logger *Logger) firePanicHooks(entry *Entry, timeout time.Duration) {
+	wait := logger.asyncHooks.waiter()
+	hooks := logger.Hooks[entry.Level]
+	// the hooks may still fire the entry after it's formatted
+	entry = copyEntry(entry)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait()
+		for _, hook := range hooks {
+			logger.fireHook(hook, entry)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		fmt.Fprintf(os.Stderr, "Failed to fire hooks within %v before panicking\n", timeout)
+	}
+}
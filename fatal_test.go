@@ -0,0 +1,86 @@
+package logrus
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDemoteFatal(t *testing.T) {
+	hook := &blockingHook{release: make(chan struct{})}
+	close(hook.release)
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = &JSONFormatter{}
+	log.AddHook(hook)
+	log.SetAsyncHooks(10)
+	defer log.SetAsyncHooks(0)
+	exits := log.RecordExits()
+	log.SetDemoteFatal(true)
+
+	log.WithField("key", "value").Fatal("one")
+	log.Fatalf("two %d", 2)
+	log.FatalFn(func() string { return "three" })
+
+	_, exited := exits.Exited()
+	assert.False(t, exited)
+	// flushed before returning
+	assert.Equal(t, []string{"one", "two 2", "three"}, hook.Messages())
+	lines := logLines(t, &buffer)
+	assert.Len(t, lines, 3)
+	for _, line := range lines {
+		assert.Equal(t, "error", line["level"])
+	}
+	assert.Equal(t, "value", lines[0]["key"])
+}
+
+func TestDemoteFatalDisabledErrorLevel(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.SetLevel(FatalLevel)
+	log.SetDemoteFatal(true)
+	exits := log.RecordExits()
+
+	log.Fatal("demoted")
+
+	_, exited := exits.Exited()
+	assert.False(t, exited)
+	assert.Empty(t, buffer.String())
+}
+
+func TestPanicFlushTimeout(t *testing.T) {
+	hook := &blockingHook{release: make(chan struct{})}
+	defer close(hook.release)
+	log := New()
+	log.Out = ioutil.Discard
+	log.AddHook(hook)
+	log.SetAsyncHooks(10)
+	log.SetPanicFlushTimeout(10 * time.Millisecond)
+
+	log.Info("queued")
+	start := time.Now()
+	assert.Panics(t, func() { log.Panic("stuck") })
+	assert.True(t, time.Since(start) < time.Second, "panicked after %v", time.Since(start))
+	assert.Empty(t, hook.Messages())
+}
+
+func TestPanicFlushTimeoutFiresInOrder(t *testing.T) {
+	hook := &blockingHook{release: make(chan struct{})}
+	close(hook.release)
+	log := New()
+	log.Out = ioutil.Discard
+	log.AddHook(hook)
+	log.SetAsyncHooks(10)
+	defer log.SetAsyncHooks(0)
+	log.SetPanicFlushTimeout(time.Second)
+
+	log.Info("one")
+	log.Info("two")
+	assert.Panics(t, func() { log.Panic("three") })
+	assert.Equal(t, []string{"one", "two", "three"}, hook.Messages())
+}
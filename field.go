@@ -0,0 +1,165 @@
+package logrus
+
+import (
+	"math"
+	"time"
+)
+
+type fieldKind uint8
+
+const (
+	anyField fieldKind = iota
+	intField
+	uintField
+	floatField
+	boolField
+	stringField
+	durationField
+	errorField
+)
+
+// Field is a typed field, created with Int, Str, Err and the other field
+// constructors, see WithTypedFields. Numbers and strings are held without
+// converting them to interface{} values.
+type Field struct {
+	Key string
+
+	kind   fieldKind
+	number uint64
+	str    string
+	value  interface{}
+}
+
+// Int returns a field of an int.
+func Int(key string, n int) Field {
+	return Field{Key: key, kind: intField, number: uint64(n)}
+}
+
+// Int64 returns a field of an int64.
+func Int64(key string, n int64) Field {
+	return Field{Key: key, kind: intField, number: uint64(n)}
+}
+
+// Uint64 returns a field of a uint64.
+func Uint64(key string, n uint64) Field {
+	return Field{Key: key, kind: uintField, number: n}
+}
+
+// Float64 returns a field of a float64.
+func Float64(key string, f float64) Field {
+	return Field{Key: key, kind: floatField, number: math.Float64bits(f)}
+}
+
+// Bool returns a field of a bool.
+func Bool(key string, b bool) Field {
+	f := Field{Key: key, kind: boolField}
+	if b {
+		f.number = 1
+	}
+	return f
+}
+
+// Str returns a field of a string.
+func Str(key, s string) Field {
+	return Field{Key: key, kind: stringField, str: s}
+}
+
+// Duration returns a field of a time.Duration.
+func Duration(key string, d time.Duration) Field {
+	return Field{Key: key, kind: durationField, number: uint64(d)}
+}
+
+// Err returns a field of an error in the ErrorKey field, see WithError.
+func Err(err error) Field {
+	return Field{Key: ErrorKey, kind: errorField, value: err}
+}
+
+// Any returns a field of any value.
+func Any(key string, v interface{}) Field {
+	return Field{Key: key, kind: anyField, value: v}
+}
+
+// Value returns the value of the field.
+func (f Field) Value() interface{} {
+	switch f.kind {
+	case intField:
+		return int64(f.number)
+	case uintField:
+		return f.number
+	case floatField:
+		return math.Float64frombits(f.number)
+	case boolField:
+		return f.number == 1
+	case stringField:
+		return f.str
+	case durationField:
+		return time.Duration(f.number)
+	default:
+		return f.value
+	}
+}
+
+// WithTypedFields adds typed fields to the Entry, like WithFields without
+// building a map of interface{} values: the fields are added to Data when
+// the entry is logged, so entries of disabled levels cost no map, or when
+// fields are added to it otherwise.
+//
+//	log.WithTypedFields(logrus.Str("user", user), logrus.Int("attempt", n)).Info("Login failed")
+func (entry *Entry) WithTypedFields(fields ...Field) *Entry {
+	if entry.disabled {
+		return entry
+	}
+	if len(entry.Data) > 0 {
+		data := entry.copyData(len(fields))
+		entry.addTypedFields(data, fields)
+		return &Entry{
+			Logger:  entry.Logger,
+			Data:    data,
+			Time:    entry.Time,
+			Context: entry.Context,
+		}
+	}
+
+	// the caller may modify fields after, when passing a slice
+	typed := make([]Field, 0, len(entry.typed)+len(fields))
+	typed = append(append(typed, entry.typed...), fields...)
+	return &Entry{
+		Logger:  entry.Logger,
+		Time:    entry.Time,
+		Context: entry.Context,
+		typed:   typed,
+	}
+}
+
+// WithTypedFields creates an entry with typed fields, see
+// Entry.WithTypedFields.
+func (logger *Logger) WithTypedFields(fields ...Field) *Entry {
+	entry := logger.newEntry()
+	defer logger.releaseEntry(entry)
+	return entry.WithTypedFields(fields...)
+}
+
+// copyData returns a copy of the entry's fields with its typed fields added,
+// with room for extra more.
+func (entry *Entry) copyData(extra int) Fields {
+	data := make(Fields, len(entry.Data)+len(entry.typed)+extra)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	entry.addTypedFields(data, entry.typed)
+	return data
+}
+
+// addTypedFields adds fields to data, unwrapping errors like WithError.
+func (entry *Entry) addTypedFields(data Fields, fields []Field) {
+	unwrap := entry.Logger != nil && entry.Logger.UnwrapErrors
+	for _, f := range fields {
+		if err, ok := f.value.(error); ok && f.kind == errorField && unwrap {
+			for k, v := range errorChainFields(err) {
+				data[k] = v
+			}
+			continue
+		}
+		data[f.Key] = f.Value()
+	}
+}
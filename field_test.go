@@ -0,0 +1,96 @@
+package logrus
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTypedFields(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := New()
+	logger.Out = &buffer
+	logger.Formatter = new(JSONFormatter)
+
+	logger.WithTypedFields(
+		Int("int", -3),
+		Int64("int64", 1<<40),
+		Uint64("uint64", 7),
+		Float64("float64", 1.5),
+		Bool("bool", true),
+		Str("str", "a"),
+		Duration("duration", time.Second),
+		Err(errors.New("failed")),
+		Any("any", []int{1}),
+	).Info("typed")
+
+	fields := logLines(t, &buffer)
+	if assert.Len(t, fields, 1) {
+		assert.Equal(t, float64(-3), fields[0]["int"])
+		assert.Equal(t, float64(1<<40), fields[0]["int64"])
+		assert.Equal(t, float64(7), fields[0]["uint64"])
+		assert.Equal(t, 1.5, fields[0]["float64"])
+		assert.Equal(t, true, fields[0]["bool"])
+		assert.Equal(t, "a", fields[0]["str"])
+		assert.Equal(t, float64(time.Second), fields[0]["duration"])
+		assert.Equal(t, "failed", fields[0]["error"])
+		assert.Equal(t, []interface{}{float64(1)}, fields[0]["any"])
+	}
+}
+
+func TestWithTypedFieldsDerived(t *testing.T) {
+	entry := NewEntry(New()).WithTypedFields(Str("a", "1")).WithTypedFields(Int("b", 2))
+	assert.Empty(t, entry.Data)
+
+	derived := entry.WithField("c", 3)
+	assert.Equal(t, Fields{"a": "1", "b": int64(2), "c": 3}, derived.Data)
+	assert.Empty(t, derived.typed)
+
+	derived = derived.WithTypedFields(Bool("d", false), Str("a", "replaced"))
+	assert.Equal(t, Fields{"a": "replaced", "b": int64(2), "c": 3, "d": false}, derived.Data)
+
+	assert.Equal(t, Fields{"a": "1", "b": int64(2)}, entry.WithTime(time.Now()).Data)
+}
+
+func TestWithTypedFieldsUnwrapErrors(t *testing.T) {
+	logger := New()
+	logger.UnwrapErrors = true
+	err := errors.New("failed")
+
+	entry := logger.WithField("a", 1).WithTypedFields(Err(err))
+	assert.Equal(t, "failed", entry.Data["error.message"])
+	assert.NotContains(t, entry.Data, ErrorKey)
+}
+
+func TestWithTypedFieldsDisabledAllocs(t *testing.T) {
+	logger := New()
+	logger.Out = &bytes.Buffer{}
+
+	typed := testing.AllocsPerRun(100, func() {
+		logger.WithTypedFields(Str("user", "alice"), Int("status", 200)).Debug("disabled")
+	})
+	untyped := testing.AllocsPerRun(100, func() {
+		logger.WithFields(Fields{"user": "alice", "status": 200}).Debug("disabled")
+	})
+	assert.True(t, typed < untyped, "%v allocations, %v with a map", typed, untyped)
+}
+
+func TestWithTypedFieldsCopiesFields(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := New()
+	logger.Out = &buffer
+	logger.Formatter = new(JSONFormatter)
+
+	fields := []Field{Str("user", "alice")}
+	entry := logger.WithTypedFields(fields...)
+	fields[0] = Str("user", "mallory")
+	entry.Info("test")
+
+	lines := logLines(t, &buffer)
+	if assert.Len(t, lines, 1) {
+		assert.Equal(t, "alice", lines[0]["user"])
+	}
+}
@@ -0,0 +1,89 @@
+package logrus
+
+import (
+	"context"
+	"io"
+	"reflect"
+)
+
+// Flusher is implemented by hooks and writers buffering entries, e.g. the
+// lfslog hook or a bufio.Writer, to write them out, see Logger.Flush.
+type Flusher interface {
+	Flush() error
+}
+
+// Flush waits until the entries queued for async hooks are fired, then
+// flushes the hooks, Out and the outputs implementing Flusher, e.g. before
+// the program exits. It returns the first error of the flushers, or the error
+// of ctx if it's done first, in which case flushing goes on in the
+// background.
+func (logger *Logger) Flush(ctx context.Context) error {
+	logger.mu.Lock()
+	wait := logger.asyncHooks.waiter()
+	hooks := flushingHooks(logger.Hooks)
+	logger.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		wait()
+		var err error
+		for _, hook := range hooks {
+			if ferr := hook.Flush(); ferr != nil && err == nil {
+				err = ferr
+			}
+		}
+		// writers are written with the lock held
+		logger.mu.Lock()
+		defer logger.mu.Unlock()
+		for _, w := range logger.writers() {
+			if f, ok := w.(Flusher); ok {
+				if ferr := f.Flush(); ferr != nil && err == nil {
+					err = ferr
+				}
+			}
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flushingHooks returns the hooks implementing Flusher, once each.
+func flushingHooks(hooks LevelHooks) []Flusher {
+	var flushers []Flusher
+	seen := map[Hook]bool{}
+	for _, levelHooks := range hooks {
+		for _, hook := range levelHooks {
+			f, ok := hook.(Flusher)
+			if !ok {
+				continue
+			}
+			if reflect.TypeOf(hook).Comparable() {
+				if seen[hook] {
+					continue
+				}
+				seen[hook] = true
+			}
+			flushers = append(flushers, f)
+		}
+	}
+	return flushers
+}
+
+// writers returns Out, unless nil, and the writers of the outputs.
+// Must be called with the logger's mu held.
+func (logger *Logger) writers() []io.Writer {
+	var writers []io.Writer
+	if logger.Out != nil {
+		writers = append(writers, logger.Out)
+	}
+	for _, o := range logger.outputs {
+		writers = append(writers, o.writer)
+	}
+	return writers
+}
@@ -0,0 +1,73 @@
+package logrus
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flushingHook counts its flushes, failing with err.
+type flushingHook struct {
+	TestHook
+	flushes int
+	err     error
+}
+
+func (hook *flushingHook) Flush() error {
+	hook.flushes++
+	return hook.err
+}
+
+func TestFlush(t *testing.T) {
+	var out, output bytes.Buffer
+	hook := &flushingHook{}
+	log := New()
+	log.Out = bufio.NewWriter(&out)
+	log.AddOutput(bufio.NewWriter(&output), InfoLevel, nil)
+	log.AddHook(hook)
+
+	log.Info("buffered")
+	assert.Empty(t, out.String())
+	assert.Empty(t, output.String())
+
+	assert.NoError(t, log.Flush(context.Background()))
+	assert.Contains(t, out.String(), "buffered")
+	assert.Contains(t, output.String(), "buffered")
+	// once, though the hook is added for all levels
+	assert.Equal(t, 1, hook.flushes)
+}
+
+func TestFlushError(t *testing.T) {
+	hook := &flushingHook{err: errors.New("disk full")}
+	log := New()
+	log.Out = ioutil.Discard
+	log.AddHook(hook)
+
+	assert.EqualError(t, log.Flush(context.Background()), "disk full")
+}
+
+func TestFlushAsyncHooks(t *testing.T) {
+	hook := &blockingHook{release: make(chan struct{})}
+	log := New()
+	log.Out = ioutil.Discard
+	log.AddHook(hook)
+	log.SetAsyncHooks(10)
+	defer log.SetAsyncHooks(0)
+
+	log.Info("one")
+	log.Info("two")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, log.Flush(ctx))
+
+	close(hook.release)
+	assert.NoError(t, log.Flush(context.Background()))
+	assert.Equal(t, []string{"one", "two"}, hook.Messages())
+}
@@ -1,9 +1,45 @@
 package logrus
 
-import "time"
+import (
+	"bytes"
+	"sort"
+	"time"
+)
 
 const defaultTimestampFormat = time.RFC3339
 
+// RFC3339Milli is a timestamp layout like time.RFC3339 with milliseconds.
+const RFC3339Milli = "2006-01-02T15:04:05.000Z07:00"
+
+// TimestampMode selects how TextFormatter and JSONFormatter log timestamps.
+type TimestampMode int
+
+const (
+	// TimestampLayout formats timestamps with the TimestampFormat layout.
+	TimestampLayout TimestampMode = iota
+	// TimestampEpochSeconds logs timestamps as seconds since the Unix epoch.
+	TimestampEpochSeconds
+	// TimestampEpochMillis logs timestamps as milliseconds since the Unix epoch.
+	TimestampEpochMillis
+	// TimestampEpochNanos logs timestamps as nanoseconds since the Unix epoch.
+	TimestampEpochNanos
+)
+
+// timestamp returns t as an epoch number in the epoch modes, or formatted
+// with layout.
+func (mode TimestampMode) timestamp(t time.Time, layout string) interface{} {
+	switch mode {
+	case TimestampEpochSeconds:
+		return t.Unix()
+	case TimestampEpochMillis:
+		return t.UnixNano() / int64(time.Millisecond)
+	case TimestampEpochNanos:
+		return t.UnixNano()
+	default:
+		return t.Format(layout)
+	}
+}
+
 // The Formatter interface is used to implement a custom Formatter. It takes an
 // `Entry`. It exposes all the fields, including the default ones:
 //
@@ -14,10 +50,25 @@ const defaultTimestampFormat = time.RFC3339
 // Any additional fields added with `WithField` or `WithFields` are also in
 // `entry.Data`. Format is expected to return an array of bytes which are then
 // logged to `logger.Out`.
+//
+// When an entry is logged, `entry.Buffer` is an empty buffer from a pool which
+// Format should write into instead of allocating one. The returned bytes may
+// be those of the buffer, as they are only used until the entry is written,
+// after which the buffer is reused. When Format is called outside of logging,
+// e.g. by hooks, `entry.Buffer` is nil.
 type Formatter interface {
 	Format(*Entry) ([]byte, error)
 }
 
+// entryBuffer returns the pooled buffer of entry, or a new one if entry isn't
+// being logged.
+func entryBuffer(entry *Entry) *bytes.Buffer {
+	if entry.Buffer != nil {
+		return entry.Buffer
+	}
+	return &bytes.Buffer{}
+}
+
 // This is to not silently overwrite `time`, `msg` and `level` fields when
 // dumping it. If this code wasn't there doing:
 //
@@ -30,7 +81,7 @@ type Formatter interface {
 //
 // It's not exported because it's still using Data in an opinionated way. It's to
 // avoid code duplication between the two default formatters.
-func prefixFieldClashes(data Fields, fieldMap FieldMap) {
+func prefixFieldClashes(data Fields, fieldMap FieldMap, reportCaller bool) {
 	timeKey := fieldMap.resolve(FieldKeyTime)
 	if t, ok := data[timeKey]; ok {
 		data["fields."+timeKey] = t
@@ -45,4 +96,38 @@ func prefixFieldClashes(data Fields, fieldMap FieldMap) {
 	if l, ok := data[levelKey]; ok {
 		data["fields."+levelKey] = l
 	}
+
+	if reportCaller {
+		funcKey := fieldMap.resolve(FieldKeyFunc)
+		if f, ok := data[funcKey]; ok {
+			data["fields."+funcKey] = f
+		}
+
+		fileKey := fieldMap.resolve(FieldKeyFile)
+		if f, ok := data[fileKey]; ok {
+			data["fields."+fileKey] = f
+		}
+	}
+}
+
+// sortKeys sorts keys with sortingFunc, alphabetically by default, and then
+// moves the priorityKeys present in keys to the front, in their order.
+func sortKeys(keys []string, priorityKeys []string, sortingFunc func([]string)) {
+	if sortingFunc != nil {
+		sortingFunc(keys)
+	} else {
+		sort.Strings(keys)
+	}
+
+	front := 0
+	for _, p := range priorityKeys {
+		for i := front; i < len(keys); i++ {
+			if keys[i] == p {
+				copy(keys[front+1:i+1], keys[front:i])
+				keys[front] = p
+				front++
+				break
+			}
+		}
+	}
 }
@@ -0,0 +1,34 @@
+package logrus
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormattersUseEntryBuffer(t *testing.T) {
+	for _, formatter := range []Formatter{
+		new(TextFormatter),
+		new(JSONFormatter),
+		&JSONFormatter{PrettyPrint: true},
+		new(ECSFormatter),
+		new(CEFFormatter),
+		new(CBORFormatter),
+		new(MsgpackFormatter),
+	} {
+		entry := WithField("a", 1)
+		entry.Message = "test"
+		entry.Buffer = bytes.NewBuffer(make([]byte, 0, 1024))
+
+		b, err := formatter.Format(entry)
+		assert.NoError(t, err)
+		assert.True(t, &b[0] == &entry.Buffer.Bytes()[0], "%T doesn't write into the entry buffer", formatter)
+		assert.Equal(t, entry.Buffer.Bytes(), b)
+
+		entry.Buffer = nil
+		unbuffered, err := formatter.Format(entry)
+		assert.NoError(t, err)
+		assert.Equal(t, unbuffered, b)
+	}
+}
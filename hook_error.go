@@ -0,0 +1,34 @@
+package logrus
+
+import (
+	"fmt"
+	"os"
+)
+
+// HookErrorHandler handles the errors of hooks, see
+// Logger.SetHookErrorHandler.
+type HookErrorHandler func(hook Hook, entry *Entry, err error)
+
+// SetHookErrorHandler makes the logger call handler with the errors returned
+// by hooks, and with the entries SetAsyncHooks drops, instead of writing them
+// to stderr, e.g. to count or alert on them. The handler may be called
+// concurrently by async hooks and mustn't log to the logger synchronously.
+// A nil handler writes errors to stderr again.
+func (logger *Logger) SetHookErrorHandler(handler HookErrorHandler) {
+	logger.hookErrorHandler.Store(handler)
+}
+
+// fireHook fires hook, handling its error.
+func (logger *Logger) fireHook(hook Hook, entry *Entry) {
+	if err := logger.hookPolicies.fire(hook, entry); err != nil {
+		logger.hookError(hook, entry, err)
+	}
+}
+
+func (logger *Logger) hookError(hook Hook, entry *Entry, err error) {
+	if handler, _ := logger.hookErrorHandler.Load().(HookErrorHandler); handler != nil {
+		handler(hook, entry, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Failed to fire hook: %v\n", err)
+}
@@ -0,0 +1,48 @@
+package logrus
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookErrorHandler(t *testing.T) {
+	hook := &failingHook{failures: 1}
+	log := New()
+	log.Out = ioutil.Discard
+	log.AddHook(hook)
+
+	var errs []string
+	log.SetHookErrorHandler(func(h Hook, entry *Entry, err error) {
+		assert.True(t, h == hook)
+		assert.Equal(t, "test", entry.Message)
+		errs = append(errs, err.Error())
+	})
+
+	log.Info("test")
+	log.Info("test")
+	assert.Equal(t, []string{"unavailable"}, errs)
+}
+
+func TestHookErrorHandlerAsyncDrop(t *testing.T) {
+	hook := &blockingHook{release: make(chan struct{})}
+	log := New()
+	log.Out = ioutil.Discard
+	log.AddHook(hook)
+	log.SetAsyncHooks(1)
+
+	dropped := make(chan string, 10)
+	log.SetHookErrorHandler(func(h Hook, entry *Entry, err error) {
+		dropped <- entry.Message + ": " + err.Error()
+	})
+
+	for _, msg := range []string{"one", "two", "three"} {
+		log.Info(msg)
+	}
+	// "two" is dropped too unless the worker took "one" off the queue
+	assert.Regexp(t, "^(two|three): hook queue full, entry dropped$", <-dropped)
+
+	close(hook.release)
+	log.SetAsyncHooks(0)
+}
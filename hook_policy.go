@@ -0,0 +1,174 @@
+package logrus
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// HookPolicy sets how the failures of a hook are handled, see
+// Logger.SetHookPolicy.
+type HookPolicy struct {
+	// Maximum duration of a Fire call, unlimited if zero. A call over it
+	// fails, while it goes on in the background with a copy of the entry, so
+	// the hook can't modify the entry.
+	Timeout time.Duration
+	// Number of times a failed Fire call is retried, none by default, i.e.
+	// the entry is skipped.
+	Retries int
+	// Wait before the first retry, doubled for each following one.
+	Backoff time.Duration
+	// Number of consecutive failed entries after which the hook is disabled,
+	// never if zero.
+	DisableAfter int
+}
+
+// HookState is the state of a hook with a policy, see Logger.HookState.
+type HookState struct {
+	// Number of consecutive failed entries
+	Failures int
+	// Number of failed entries
+	TotalFailures int
+	// Error of the last failed entry
+	LastError error
+	// Whether the hook was disabled after DisableAfter failures
+	Disabled bool
+}
+
+// hookPolicies holds the policies of the hooks of a logger. It has its own
+// lock as hooks fired by SetAsyncHooks workers don't hold the logger's.
+type hookPolicies struct {
+	mu       sync.RWMutex
+	policies map[Hook]*hookPolicy
+}
+
+type hookPolicy struct {
+	policy HookPolicy
+
+	mu    sync.Mutex
+	state HookState
+}
+
+// SetHookPolicy sets the policy for the failures of hook, which must be
+// comparable, e.g. a pointer, replacing its previous policy and resetting its
+// state. Retries wait in the logging goroutine, unless SetAsyncHooks is used.
+//
+//	log.SetHookPolicy(hook, logrus.HookPolicy{
+//		Timeout:      time.Second,
+//		Retries:      2,
+//		Backoff:      100 * time.Millisecond,
+//		DisableAfter: 10,
+//	})
+func (logger *Logger) SetHookPolicy(hook Hook, policy HookPolicy) {
+	p := &logger.hookPolicies
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.policies == nil {
+		p.policies = map[Hook]*hookPolicy{}
+	}
+	p.policies[hook] = &hookPolicy{policy: policy}
+}
+
+// HookState returns the state of hook, and false if it has no policy.
+func (logger *Logger) HookState(hook Hook) (HookState, bool) {
+	h := logger.hookPolicies.get(hook)
+	if h == nil {
+		return HookState{}, false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.state, true
+}
+
+// EnableHook enables hook again after it was disabled by its policy, and
+// resets its consecutive failures.
+func (logger *Logger) EnableHook(hook Hook) {
+	h := logger.hookPolicies.get(hook)
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.state.Disabled = false
+	h.state.Failures = 0
+}
+
+func (p *hookPolicies) get(hook Hook) *hookPolicy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.policies) == 0 || !reflect.TypeOf(hook).Comparable() {
+		return nil
+	}
+	return p.policies[hook]
+}
+
+// fire fires hook with its policy, if any.
+func (p *hookPolicies) fire(hook Hook, entry *Entry) error {
+	h := p.get(hook)
+	if h == nil {
+		return hook.Fire(entry)
+	}
+	return h.fire(hook, entry)
+}
+
+func (h *hookPolicy) fire(hook Hook, entry *Entry) error {
+	h.mu.Lock()
+	disabled := h.state.Disabled
+	h.mu.Unlock()
+	if disabled {
+		return nil
+	}
+
+	err := h.attempt(hook, entry)
+	backoff := h.policy.Backoff
+	for i := 0; err != nil && i < h.policy.Retries; i++ {
+		time.Sleep(backoff)
+		backoff *= 2
+		err = h.attempt(hook, entry)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err == nil {
+		h.state.Failures = 0
+		return nil
+	}
+
+	h.state.Failures++
+	h.state.TotalFailures++
+	h.state.LastError = err
+	if h.policy.DisableAfter > 0 && h.state.Failures >= h.policy.DisableAfter {
+		h.state.Disabled = true
+		return fmt.Errorf("%v, hook %T disabled after %d failures", err, hook, h.state.Failures)
+	}
+	return err
+}
+
+// attempt fires hook once, within the timeout of the policy.
+func (h *hookPolicy) attempt(hook Hook, entry *Entry) error {
+	if h.policy.Timeout <= 0 {
+		return hook.Fire(entry)
+	}
+
+	entry = copyEntry(entry)
+	done := make(chan error, 1)
+	go func() {
+		done <- hook.Fire(entry)
+	}()
+
+	timer := time.NewTimer(h.policy.Timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("hook %T timed out after %v", hook, h.policy.Timeout)
+	}
+}
@@ -0,0 +1,96 @@
+package logrus
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingHook fails the first failures Fire calls, sleeping delay in each.
+type failingHook struct {
+	failures int
+	delay    time.Duration
+	calls    int
+}
+
+func (hook *failingHook) Fire(entry *Entry) error {
+	hook.calls++
+	time.Sleep(hook.delay)
+	if hook.calls <= hook.failures {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func (hook *failingHook) Levels() []Level {
+	return AllLevels
+}
+
+func (hook *failingHook) Close() error {
+	return nil
+}
+
+func TestHookPolicyRetries(t *testing.T) {
+	hook := &failingHook{failures: 2}
+	log := New()
+	log.Out = ioutil.Discard
+	log.AddHook(hook)
+	log.SetHookPolicy(hook, HookPolicy{Retries: 2, Backoff: time.Millisecond})
+
+	log.Info("test")
+	assert.Equal(t, 3, hook.calls)
+	state, ok := log.HookState(hook)
+	assert.True(t, ok)
+	assert.Equal(t, HookState{}, state)
+}
+
+func TestHookPolicyDisableAfter(t *testing.T) {
+	hook := &failingHook{failures: 3}
+	other := new(TestHook)
+	log := New()
+	log.Out = ioutil.Discard
+	log.AddHook(hook)
+	log.AddHook(other)
+	log.SetHookPolicy(hook, HookPolicy{DisableAfter: 2})
+
+	log.Info("one")
+	assert.True(t, other.Fired, "other hooks are fired after a failure")
+	log.Info("two")
+	log.Info("three")
+	assert.Equal(t, 2, hook.calls)
+
+	state, _ := log.HookState(hook)
+	assert.Equal(t, 2, state.Failures)
+	assert.Equal(t, 2, state.TotalFailures)
+	assert.EqualError(t, state.LastError, "unavailable")
+	assert.True(t, state.Disabled)
+
+	log.EnableHook(hook)
+	log.Info("four")
+	log.Info("five")
+	assert.Equal(t, 4, hook.calls)
+	state, _ = log.HookState(hook)
+	assert.Equal(t, HookState{TotalFailures: 3, LastError: state.LastError}, state)
+}
+
+func TestHookPolicyTimeout(t *testing.T) {
+	hook := &blockingHook{release: make(chan struct{})}
+	defer close(hook.release)
+	log := New()
+	log.Out = ioutil.Discard
+	log.AddHook(hook)
+	log.SetHookPolicy(hook, HookPolicy{Timeout: 10 * time.Millisecond})
+
+	log.Info("test")
+	state, _ := log.HookState(hook)
+	assert.Equal(t, 1, state.Failures)
+	assert.Contains(t, state.LastError.Error(), "timed out after 10ms")
+}
+
+func TestHookStateWithoutPolicy(t *testing.T) {
+	_, ok := New().HookState(new(TestHook))
+	assert.False(t, ok)
+}
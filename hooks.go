@@ -2,9 +2,9 @@ package logrus
 
 // A hook to be fired when logging on the logging levels returned from
 // `Levels()` on your implementation of the interface. Note that this is not
-// fired in a goroutine or a channel with workers, you should handle such
-// functionality yourself if your call is non-blocking and you don't wish for
-// the logging calls for levels returned from `Levels()` to block.
+// fired in a goroutine or a channel with workers unless `Logger.SetAsyncHooks`
+// is used, so by default the logging calls for levels returned from
+// `Levels()` block on it. See `Logger.SetHookPolicy` to handle its failures.
 type Hook interface {
 	Levels() []Level
 	Fire(*Entry) error
@@ -34,17 +34,18 @@ func (hooks LevelHooks) Fire(level Level, entry *Entry) error {
 	return nil
 }
 
-// Close close all hooks.
+// Close closes all hooks, once each though added for several levels, and
+// returns their errors.
 func (hooks LevelHooks) Close() error {
-	records := make(map[Level]bool, len(AllLevels))
-	for level, allHooks := range hooks {
-		for _, hook := range allHooks {
-			if _, ok := records[level]; !ok {
-				records[level] = true
-				hook.Close()
-			}
+	var c closer
+	hooks.close(&c)
+	return c.err()
+}
+
+func (hooks LevelHooks) close(c *closer) {
+	for _, levelHooks := range hooks {
+		for _, hook := range levelHooks {
+			c.close(hook)
 		}
 	}
-
-	return nil
 }
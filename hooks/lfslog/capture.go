@@ -0,0 +1,87 @@
+package lfslog
+
+import (
+	"bytes"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// NewMemoryHook returns new LFS hook routing levels like NewPathHook, but
+// capturing the formatted output in memory instead of writing the files, so
+// unit tests can assert on file-bound log content without temp dirs.
+func NewMemoryHook(paths PathMap, formatter logrus.Formatter) *LfsHook {
+	hook := NewPathHook(paths, formatter)
+	hook.SetCapture(true)
+
+	return hook
+}
+
+// SetCapture enables a dry-run mode in which entries are formatted and routed
+// as usual, but captured in memory instead of being written to any file or
+// writer. Use Captured and CapturedFile to inspect the output. Enabling
+// capture discards anything captured before.
+func (hook *LfsHook) SetCapture(enabled bool) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	hook.capture = enabled
+	hook.captured = nil
+	hook.capturedFiles = nil
+}
+
+// Captured returns a copy of the output captured for level.
+func (hook *LfsHook) Captured(level logrus.Level) []byte {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	return copyBuffer(hook.captured[level])
+}
+
+// CapturedFile returns a copy of the output captured for the file at path.
+// Templates and the instance suffix are applied to paths before capturing, so
+// path is the name the file would have on disk.
+func (hook *LfsHook) CapturedFile(path string) []byte {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	return copyBuffer(hook.capturedFiles[path])
+}
+
+// captureWrite records msg for the entry's level and for every file it would
+// have been written to.
+// Must be called with hook.lock held.
+func (hook *LfsHook) captureWrite(paths []string, msg []byte, entry *logrus.Entry) error {
+	if hook.captured == nil {
+		hook.captured = make(map[logrus.Level]*bytes.Buffer)
+	}
+	if hook.captured[entry.Level] == nil {
+		hook.captured[entry.Level] = new(bytes.Buffer)
+	}
+	hook.captured[entry.Level].Write(msg)
+
+	for _, path := range paths {
+		path, err := hook.resolvePath(path, entry)
+		if err != nil {
+			return err
+		}
+		path = hook.instancePath(path)
+
+		if hook.capturedFiles == nil {
+			hook.capturedFiles = make(map[string]*bytes.Buffer)
+		}
+		if hook.capturedFiles[path] == nil {
+			hook.capturedFiles[path] = new(bytes.Buffer)
+		}
+		hook.capturedFiles[path].Write(msg)
+	}
+
+	return nil
+}
+
+func copyBuffer(b *bytes.Buffer) []byte {
+	if b == nil {
+		return nil
+	}
+
+	return append([]byte(nil), b.Bytes()...)
+}
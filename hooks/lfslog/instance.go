@@ -0,0 +1,39 @@
+package lfslog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SetInstanceSuffix makes the hook append `-<hostname>-<pid>` before the
+// extension of every path, e.g. `/var/log/app.log` becomes
+// `/var/log/app-web1-4242.log`, so replicas writing into a shared volume
+// don't interleave writes into the same file.
+func (hook *LfsHook) SetInstanceSuffix(enabled bool) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	if !enabled {
+		hook.instanceSuffix = ""
+		return
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	hook.instanceSuffix = fmt.Sprintf("-%s-%d", sanitizePathComponent(hostname), os.Getpid())
+}
+
+// instancePath inserts the instance suffix before the extension of path.
+// Must be called with hook.lock held.
+func (hook *LfsHook) instancePath(path string) string {
+	if hook.instanceSuffix == "" {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + hook.instanceSuffix + ext
+}
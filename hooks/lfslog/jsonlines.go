@@ -0,0 +1,37 @@
+package lfslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// SetStrictJSONLines makes the hook guarantee that every entry is written as
+// exactly one line of valid JSON, so files are always safe to ingest with
+// line-oriented collectors. Entries the formatter doesn't render as JSON are
+// rejected with an error.
+func (hook *LfsHook) SetStrictJSONLines(enabled bool) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	hook.strictJSONLines = enabled
+}
+
+// jsonLine compacts a formatted entry into a single line terminated by exactly
+// one newline. Newlines inside JSON strings are always escaped, so removing
+// insignificant whitespace is enough to drop every raw newline.
+func jsonLine(msg []byte) ([]byte, error) {
+	msg = bytes.TrimSpace(msg)
+	if !json.Valid(msg) {
+		return nil, errors.New("formatter did not produce valid JSON")
+	}
+
+	var b bytes.Buffer
+	b.Grow(len(msg) + 1)
+	if err := json.Compact(&b, msg); err != nil {
+		return nil, err
+	}
+	b.WriteByte('\n')
+
+	return b.Bytes(), nil
+}
@@ -2,27 +2,38 @@
 package lfslog
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"github.com/dorofeevsa/logrus"
 	"io"
 	"log"
 	"os"
-	"path/filepath"
 	"reflect"
 	"sync"
+	"time"
 )
 
+// defaultFlushInterval is how often buffered output is flushed unless changed
+// with SetFlushInterval.
+const defaultFlushInterval = time.Second
+
+// defaultMaxOpenFiles is how many files are kept open unless changed with
+// SetMaxOpenFiles.
+const defaultMaxOpenFiles = 64
+
 // We are logging to file, strip colors to make the output more readable.
 var defaultFormatter = &logrus.TextFormatter{DisableColors: true}
 
 // PathMap is map for mapping a log level to a file's path.
-// Multiple levels may share a file, but multiple files may not be used for one level.
+// Multiple levels may share a file, use AddPath to add more files to one level.
+// A path may be a template evaluated against the entry, e.g.
+// `/var/log/app/{{.Data.service}}/{{.Level}}.log`; see resolvePath.
 type PathMap map[logrus.Level]string
 
-// WriterMap is map for mapping a log level to an io.WriteCloser.
-// Multiple levels may share a writer, but multiple writers may not be used for one level.
-type WriterMap map[logrus.Level]io.WriteCloser
+// WriterMap is map for mapping a log level to an io.Writer.
+// Multiple levels may share a writer, use AddWriter to add more writers to one level.
+type WriterMap map[logrus.Level]io.Writer
 
 // LfsHook is a hook to handle writing to local log files.
 type LfsHook struct {
@@ -33,44 +44,153 @@ type LfsHook struct {
 	formatter logrus.Formatter
 
 	defaultPath      string
-	defaultWriter    io.WriteCloser
+	defaultWriter    io.Writer
 	hasDefaultPath   bool
 	hasDefaultWriter bool
+
+	defaultOutputFormatter logrus.Formatter
+
+	fallbackWriter io.Writer
+	fallbackLock   sync.Mutex
+
+	// uncomparableLock serializes writes to writers that can't be cached.
+	uncomparableLock sync.Mutex
+
+	templates map[string]*pathTemplate
+
+	files      map[string]*target
+	buffers    map[io.Writer]*target
+	bufferSize int
+	flushStop  chan struct{}
+	gzip       bool
+	gzipPaths  map[string]bool
+
+	uses         uint64
+	maxOpenFiles int
+
+	maxFileSize int64
+
+	filter func(*logrus.Entry) bool
+
+	extraWriters map[logrus.Level][]io.Writer
+	extraPaths   map[logrus.Level][]string
+
+	instanceSuffix string
+
+	strictJSONLines bool
+
+	capture       bool
+	captured      map[logrus.Level]*bytes.Buffer
+	capturedFiles map[string]*bytes.Buffer
+
+	owner *owner
+
+	writeTimeout time.Duration
+
+	projections map[logrus.Level]*projection
+
+	routeField string
+	routes     map[string]string
+
+	stats     map[logrus.Level]*LevelStats
+	statsLock sync.Mutex
 }
 
 // NewHook returns new LFS hook.
-// Output can be a string, io.WriteCloser, WriterMap or PathMap.
-// If using io.WriteCloser or WriterMap, user is responsible for closing the used io.WriteCloser.
+// Output can be a string, io.Writer, WriterMap (or a plain map[logrus.Level]io.Writer) or PathMap.
+// Writers are closed when the hook is closed.
+// Prefer the typed constructors NewSinglePathHook, NewSingleWriterHook,
+// NewPathHook and NewWriterHook, which reject misconfiguration at compile time.
 func NewHook(output interface{}, formatter logrus.Formatter) (*LfsHook, error) {
+	switch output.(type) {
+	case string:
+		return NewSinglePathHook(output.(string), formatter), nil
+	case PathMap:
+		return NewPathHook(output.(PathMap), formatter), nil
+	case WriterMap:
+		return NewWriterHook(output.(WriterMap), formatter), nil
+	case map[logrus.Level]io.Writer:
+		return NewWriterHook(WriterMap(output.(map[logrus.Level]io.Writer)), formatter), nil
+	case io.Writer:
+		return NewSingleWriterHook(output.(io.Writer), formatter), nil
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported level map type: %v", reflect.TypeOf(output)))
+	}
+}
+
+// NewSinglePathHook returns new LFS hook writing every level to the file at path.
+func NewSinglePathHook(path string, formatter logrus.Formatter) *LfsHook {
+	hook := newHook(formatter)
+	hook.SetDefaultPath(path)
+
+	return hook
+}
+
+// NewSingleWriterHook returns new LFS hook writing every level to writer.
+func NewSingleWriterHook(writer io.Writer, formatter logrus.Formatter) *LfsHook {
+	hook := newHook(formatter)
+	hook.SetDefaultWriter(writer)
+
+	return hook
+}
+
+// NewPathHook returns new LFS hook writing the levels of paths to their files.
+func NewPathHook(paths PathMap, formatter logrus.Formatter) *LfsHook {
+	hook := newHook(formatter)
+	hook.paths = paths
+	for level := range paths {
+		hook.levels = append(hook.levels, level)
+	}
+
+	return hook
+}
+
+// NewWriterHook returns new LFS hook writing the levels of writers to their writers.
+// Writers are closed when the hook is closed.
+func NewWriterHook(writers WriterMap, formatter logrus.Formatter) *LfsHook {
+	hook := newHook(formatter)
+	hook.writers = writers
+	for level := range writers {
+		hook.levels = append(hook.levels, level)
+	}
+
+	return hook
+}
+
+func newHook(formatter logrus.Formatter) *LfsHook {
 	hook := &LfsHook{
 		lock: new(sync.Mutex),
 	}
 
 	hook.SetFormatter(formatter)
 
+	return hook
+}
+
+// NewHookWithDefaults returns new LFS hook writing the levels of output, a
+// PathMap or WriterMap, to their files or writers, and every other level to
+// defaultOutput, a string path or io.Writer.
+func NewHookWithDefaults(output interface{}, defaultOutput interface{}, formatter logrus.Formatter) (*LfsHook, error) {
 	switch output.(type) {
-	case string:
-		hook.SetDefaultPath(output.(string))
-		break
-	case io.WriteCloser:
-		hook.SetDefaultWriter(output.(io.WriteCloser))
-		break
-	case PathMap:
-		hook.paths = output.(PathMap)
-		for level := range output.(PathMap) {
-			hook.levels = append(hook.levels, level)
-		}
-		break
-	case WriterMap:
-		hook.writers = output.(WriterMap)
-		for level := range output.(WriterMap) {
-			hook.levels = append(hook.levels, level)
-		}
-		break
+	case PathMap, WriterMap, map[logrus.Level]io.Writer:
 	default:
 		return nil, errors.New(fmt.Sprintf("unsupported level map type: %v", reflect.TypeOf(output)))
 	}
 
+	hook, err := NewHook(output, formatter)
+	if err != nil {
+		return nil, err
+	}
+
+	switch defaultOutput.(type) {
+	case string:
+		hook.SetDefaultPath(defaultOutput.(string))
+	case io.Writer:
+		hook.SetDefaultWriter(defaultOutput.(io.Writer))
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported default output type: %v", reflect.TypeOf(defaultOutput)))
+	}
+
 	return hook, nil
 }
 
@@ -80,111 +200,334 @@ func (hook *LfsHook) SetFormatter(formatter logrus.Formatter) {
 	if formatter == nil {
 		formatter = defaultFormatter
 	} else {
-		switch formatter.(type) {
-		case *logrus.TextFormatter:
-			textFormatter := formatter.(*logrus.TextFormatter)
-			textFormatter.DisableColors = true
-		}
+		disableColors(formatter)
 	}
 
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
 	hook.formatter = formatter
 }
 
-// SetDefaultPath sets default path for levels that don't have any defined output path.
+// SetDefaultFormatter sets the formatter used for entries written to the
+// default path or writer, e.g. a human-readable format for everything that
+// isn't covered by the JSON files of the level map. A nil formatter uses the
+// hook's formatter for the default output too.
+func (hook *LfsHook) SetDefaultFormatter(formatter logrus.Formatter) {
+	if formatter != nil {
+		disableColors(formatter)
+	}
+
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	hook.defaultOutputFormatter = formatter
+}
+
+func disableColors(formatter logrus.Formatter) {
+	switch formatter.(type) {
+	case *logrus.TextFormatter:
+		textFormatter := formatter.(*logrus.TextFormatter)
+		textFormatter.DisableColors = true
+	}
+}
+
+// SetDefaultPath sets default path for levels that don't have any defined output path or writer.
 func (hook *LfsHook) SetDefaultPath(defaultPath string) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
 	hook.defaultPath = defaultPath
 	hook.hasDefaultPath = true
 }
 
-// SetDefaultWriter sets default writer for levels that don't have any defined writer.
-func (hook *LfsHook) SetDefaultWriter(defaultWriter io.WriteCloser) {
+// SetDefaultWriter sets default writer for levels that don't have any defined output path or writer.
+// It takes precedence over the default path.
+// The writer is closed with the hook if it implements io.Closer, unless it's
+// os.Stdout or os.Stderr.
+func (hook *LfsHook) SetDefaultWriter(defaultWriter io.Writer) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
 	hook.defaultWriter = defaultWriter
 	hook.hasDefaultWriter = true
 }
 
-// Fire writes the log file to defined path or using the defined writer.
-// User who run this function needs write permissions to the file or directory if the file does not yet exist.
-func (hook *LfsHook) Fire(entry *logrus.Entry) error {
-	if hook.writers != nil || hook.hasDefaultWriter {
-		return hook.ioWrite(entry)
-	} else if hook.paths != nil || hook.hasDefaultPath {
-		return hook.fileWrite(entry)
+// SetFallbackWriter sets the writer that receives a message when opening or
+// writing the level's file (or writer) fails, e.g. on a read-only or full
+// filesystem, so the message is not lost.
+func (hook *LfsHook) SetFallbackWriter(fallbackWriter io.Writer) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	hook.fallbackWriter = fallbackWriter
+}
+
+// SetFilter sets a predicate evaluated before an entry is formatted. Entries
+// for which it returns false are not written, e.g. health check requests.
+// A nil filter writes every entry.
+func (hook *LfsHook) SetFilter(filter func(*logrus.Entry) bool) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	hook.filter = filter
+}
+
+// AddWriter adds writer as an additional output of level, so an entry can go
+// to several outputs while being formatted only once.
+func (hook *LfsHook) AddWriter(level logrus.Level, writer io.Writer) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	if hook.extraWriters == nil {
+		hook.extraWriters = make(map[logrus.Level][]io.Writer)
 	}
+	hook.extraWriters[level] = append(hook.extraWriters[level], writer)
+}
 
-	return nil
+// AddPath adds the file at path as an additional output of level, e.g. a
+// combined file next to the level's own file.
+func (hook *LfsHook) AddPath(level logrus.Level, path string) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	if hook.extraPaths == nil {
+		hook.extraPaths = make(map[logrus.Level][]string)
+	}
+	hook.extraPaths[level] = append(hook.extraPaths[level], path)
 }
 
-// Write a log line to an io.WriteCloser.
-func (hook *LfsHook) ioWrite(entry *logrus.Entry) error {
-	var (
-		writer io.WriteCloser
-		msg    []byte
-		err    error
-		ok     bool
-	)
+// SetPath routes level to the file at path, replacing the level's current
+// path. It's safe to call while the hook is in use, e.g. from an admin
+// endpoint; files opened before stay open with their buffered output.
+func (hook *LfsHook) SetPath(level logrus.Level, path string) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	paths := hook.copyPaths()
+	paths[level] = path
+	hook.paths = paths
+}
 
+// RemovePath removes the path of level. Entries of level are then written
+// only to its other outputs, or to the default output if it has none.
+// It's safe to call while the hook is in use.
+func (hook *LfsHook) RemovePath(level logrus.Level) {
 	hook.lock.Lock()
 	defer hook.lock.Unlock()
 
-	if writer, ok = hook.writers[entry.Level]; !ok {
-		if hook.hasDefaultWriter {
-			writer = hook.defaultWriter
-		} else {
-			return nil
-		}
+	paths := hook.copyPaths()
+	delete(paths, level)
+	hook.paths = paths
+}
+
+// copyPaths returns a copy of the path map, so the map given by the user is
+// never modified.
+// Must be called with hook.lock held.
+func (hook *LfsHook) copyPaths() PathMap {
+	paths := make(PathMap, len(hook.paths)+1)
+	for level, path := range hook.paths {
+		paths[level] = path
+	}
+
+	return paths
+}
+
+// Fire writes the log file to defined path or using the defined writer.
+// Entries routed by a field value go to the route's path. Otherwise the
+// level's writers and paths are used, levels without any fall through to the
+// default writer or default path.
+// Entries are formatted without holding any lock, and every file and writer
+// has its own lock, so writes to one output don't block writes to another.
+// User who run this function needs write permissions to the file or directory if the file does not yet exist.
+func (hook *LfsHook) Fire(entry *logrus.Entry) error {
+	hook.lock.Lock()
+	if hook.filter != nil && !hook.filter(entry) {
+		hook.lock.Unlock()
+		return nil
+	}
+	writers, paths, isDefault := hook.outputs(entry)
+	formatter := hook.formatter
+	if isDefault && hook.defaultOutputFormatter != nil {
+		formatter = hook.defaultOutputFormatter
+	}
+	strictJSONLines := hook.strictJSONLines
+	capture := hook.capture
+	projection := hook.projections[entry.Level]
+	hook.lock.Unlock()
+
+	if len(writers) == 0 && len(paths) == 0 {
+		return nil
 	}
 
 	// use our formatter instead of entry.String()
-	msg, err = hook.formatter.Format(entry)
+	msg, err := formatter.Format(projection.project(entry))
 
+	if err == nil && strictJSONLines {
+		msg, err = jsonLine(msg)
+	}
 	if err != nil {
 		log.Println("failed to generate string for entry:", err)
 		return err
 	}
-	_, err = writer.Write(msg)
+
+	if capture {
+		hook.lock.Lock()
+		defer hook.lock.Unlock()
+
+		return hook.captureWrite(paths, msg, entry)
+	}
+
+	for _, writer := range writers {
+		if werr := hook.ioWrite(writer, msg, entry.Level); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	for _, path := range paths {
+		if werr := hook.fileWrite(path, msg, entry); werr != nil && err == nil {
+			err = werr
+		}
+	}
+
 	return err
 }
 
+// outputs returns the writers and paths entry is written to, and whether
+// it falls through to the default output.
+// Must be called with hook.lock held.
+func (hook *LfsHook) outputs(entry *logrus.Entry) (writers []io.Writer, paths []string, isDefault bool) {
+	if path, ok := hook.route(entry); ok {
+		return nil, []string{path}, false
+	}
+
+	level := entry.Level
+	if writer, ok := hook.writers[level]; ok {
+		writers = append(writers, writer)
+	}
+	if path, ok := hook.paths[level]; ok {
+		paths = append(paths, path)
+	}
+	writers = append(writers, hook.extraWriters[level]...)
+	paths = append(paths, hook.extraPaths[level]...)
+
+	if len(writers) == 0 && len(paths) == 0 {
+		if hook.hasDefaultWriter {
+			writers = append(writers, hook.defaultWriter)
+			isDefault = true
+		} else if hook.hasDefaultPath {
+			paths = append(paths, hook.defaultPath)
+			isDefault = true
+		}
+	}
+
+	return writers, paths, isDefault
+}
+
+// Write a log line to an io.Writer.
+// Must be called without hook.lock held.
+func (hook *LfsHook) ioWrite(writer io.Writer, msg []byte, level logrus.Level) error {
+	if writer == nil {
+		return nil
+	}
+
+	err := hook.withTarget(func() (*target, error) {
+		return hook.writerTarget(writer), nil
+	}, func(t *target) error {
+		return t.write(msg, level)
+	})
+	if err != nil {
+		log.Println("failed to write to log writer:", err)
+		hook.countWriteError(level)
+		return hook.fallbackWrite(msg, err)
+	}
+	hook.countWrite(level, len(msg))
+	return nil
+}
+
 // Write a log line directly to a file.
-func (hook *LfsHook) fileWrite(entry *logrus.Entry) error {
+// Must be called without hook.lock held.
+func (hook *LfsHook) fileWrite(pathTemplate string, msg []byte, entry *logrus.Entry) error {
 	var (
-		fd   *os.File
-		path string
-		msg  []byte
-		err  error
-		ok   bool
+		path        string
+		maxFileSize int64
+		resolveErr  error
+		openErr     error
+		failed      *target
 	)
 
-	hook.lock.Lock()
-	defer hook.lock.Unlock()
+	err := hook.withTarget(func() (*target, error) {
+		path, resolveErr = hook.resolvePath(pathTemplate, entry)
+		if resolveErr != nil {
+			return nil, resolveErr
+		}
+		path = hook.instancePath(path)
+		maxFileSize = hook.maxFileSize
+
+		return hook.fileTarget(path, hook.gzip || hook.gzipPaths[pathTemplate]), nil
+	}, func(t *target) error {
+		if t.file == nil {
+			if openErr = t.open(path); openErr != nil {
+				return openErr
+			}
+		}
 
-	if path, ok = hook.paths[entry.Level]; !ok {
-		if hook.hasDefaultPath {
-			path = hook.defaultPath
-		} else {
-			return nil
+		err := t.rollover(path, maxFileSize, len(msg))
+		if err == nil {
+			err = t.write(msg, entry.Level)
+		}
+		if err != nil {
+			// reopen the file on the next write
+			t.Close()
+			failed = t
+			return err
 		}
-	}
 
-	dir := filepath.Dir(path)
-	os.MkdirAll(dir, os.ModePerm)
+		if !t.keepOpen() {
+			return t.closeFile()
+		}
+		return nil
+	})
+	if resolveErr != nil {
+		log.Println("failed to resolve logfile path:", resolveErr)
+		return resolveErr
+	}
+	if err != ErrWriteTimeout && openErr != nil {
+		log.Println("failed to open logfile:", path, openErr)
+		hook.countOpenError(entry.Level)
+		return hook.fallbackWrite(msg, openErr)
+	}
 
-	fd, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
 	if err != nil {
-		log.Println("failed to open logfile:", path, err)
-		return err
+		log.Println("failed to write to logfile:", path, err)
+		if err != ErrWriteTimeout {
+			hook.lock.Lock()
+			hook.dropFile(path, failed)
+			hook.lock.Unlock()
+		}
+		hook.countWriteError(entry.Level)
+		return hook.fallbackWrite(msg, err)
 	}
-	defer fd.Close()
+	hook.countWrite(entry.Level, len(msg))
+	return nil
+}
 
-	// use our formatter instead of entry.String()
-	msg, err = hook.formatter.Format(entry)
+// Write a log line to the fallback writer after the primary output failed with err.
+// Returns err untouched when no fallback writer is set.
+// Must be called without hook.lock held.
+func (hook *LfsHook) fallbackWrite(msg []byte, err error) error {
+	hook.lock.Lock()
+	fallbackWriter := hook.fallbackWriter
+	hook.lock.Unlock()
 
-	if err != nil {
-		log.Println("failed to generate string for entry:", err)
+	if fallbackWriter == nil {
 		return err
 	}
-	fd.Write(msg)
-	return nil
+
+	hook.fallbackLock.Lock()
+	defer hook.fallbackLock.Unlock()
+
+	_, err = fallbackWriter.Write(msg)
+	return err
 }
 
 // Levels returns configured log levels.
@@ -192,17 +535,111 @@ func (hook *LfsHook) Levels() []logrus.Level {
 	return logrus.AllLevels
 }
 
+// Close flushes buffered output, closes the files opened by the hook and
+// closes every distinct writer the hook was given exactly once.
 func (hook *LfsHook) Close() error {
 	hook.lock.Lock()
 	defer hook.lock.Unlock()
 
-	if hook.defaultWriter != nil {
-		if err := hook.defaultWriter.Close(); err != nil {
-			return err
+	hook.stopFlushing()
+	err := hook.closeTargets()
+
+	closed := make(map[io.Writer]bool)
+	for _, writer := range hook.allWriters() {
+		if reflect.TypeOf(writer).Comparable() {
+			if closed[writer] {
+				continue
+			}
+			closed[writer] = true
 		}
 
-		hook.defaultWriter = nil
+		if closer, ok := closable(writer); ok {
+			if cerr := closer.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
 	}
 
-	return nil
+	hook.writers = nil
+	hook.extraWriters = nil
+	hook.defaultWriter = nil
+
+	return err
+}
+
+// Flush writes buffered output and syncs file backed writers to disk.
+// The targets are flushed without holding hook.lock, so a slow file doesn't
+// block writes to the others.
+func (hook *LfsHook) Flush() error {
+	hook.lock.Lock()
+	files, buffers := hook.targets()
+	writers := hook.allWriters()
+	timeout := hook.writeTimeout
+	hook.lock.Unlock()
+
+	var err error
+	for _, t := range files {
+		t := t
+		ferr := t.do(timeout, func() error {
+			if err := t.Flush(); err != nil {
+				return err
+			}
+			if t.file != nil {
+				return t.file.Sync()
+			}
+			return nil
+		})
+		if ferr != nil && err == nil {
+			err = ferr
+		}
+	}
+	for _, t := range buffers {
+		if ferr := t.do(timeout, t.Flush); ferr != nil && err == nil {
+			err = ferr
+		}
+	}
+	for _, writer := range writers {
+		if syncer, ok := writer.(interface{ Sync() error }); ok {
+			if serr := syncer.Sync(); serr != nil && err == nil {
+				err = serr
+			}
+		}
+	}
+
+	return err
+}
+
+// allWriters returns every non-nil writer the hook was given, including duplicates.
+// Must be called with hook.lock held.
+func (hook *LfsHook) allWriters() []io.Writer {
+	var writers []io.Writer
+	for _, writer := range hook.writers {
+		writers = append(writers, writer)
+	}
+	for _, extra := range hook.extraWriters {
+		writers = append(writers, extra...)
+	}
+	writers = append(writers, hook.defaultWriter)
+
+	// drop unset writers
+	n := 0
+	for _, writer := range writers {
+		if writer != nil {
+			writers[n] = writer
+			n++
+		}
+	}
+
+	return writers[:n]
+}
+
+// closable returns the io.Closer of a writer the hook may close. The standard
+// streams are never closed.
+func closable(w io.Writer) (io.Closer, bool) {
+	if w == io.Writer(os.Stdout) || w == io.Writer(os.Stderr) {
+		return nil, false
+	}
+
+	closer, ok := w.(io.Closer)
+	return closer, ok
 }
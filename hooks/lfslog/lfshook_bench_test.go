@@ -0,0 +1,57 @@
+package lfslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// slowWriter simulates a destination with write latency, e.g. a busy disk.
+// The name keeps pointers to writers distinct, as they may be equal for
+// zero-size values.
+type slowWriter struct {
+	name string
+}
+
+func (*slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(10 * time.Microsecond)
+	return len(p), nil
+}
+
+// BenchmarkSharedWriter writes debug and error entries to the same writer,
+// so every write contends for the same lock.
+func BenchmarkSharedWriter(b *testing.B) {
+	w := &slowWriter{name: "shared"}
+	doHookBenchmark(b, NewWriterHook(WriterMap{
+		logrus.DebugLevel: w,
+		logrus.ErrorLevel: w,
+	}, &logrus.TextFormatter{}))
+}
+
+// BenchmarkShardedWriters writes debug and error entries to separate writers,
+// which don't block each other.
+func BenchmarkShardedWriters(b *testing.B) {
+	doHookBenchmark(b, NewWriterHook(WriterMap{
+		logrus.DebugLevel: &slowWriter{name: "debug"},
+		logrus.ErrorLevel: &slowWriter{name: "error"},
+	}, &logrus.TextFormatter{}))
+}
+
+// doHookBenchmark fires the hook directly, as the logger serializes the hooks
+// it fires.
+func doHookBenchmark(b *testing.B, hook *LfsHook) {
+	logger := logrus.New()
+	entries := []*logrus.Entry{
+		{Logger: logger, Level: logrus.DebugLevel, Message: "debug", Data: logrus.Fields{}},
+		{Logger: logger, Level: logrus.ErrorLevel, Message: "error", Data: logrus.Fields{}},
+	}
+
+	// writers sleep, so more goroutines than CPUs contend for them
+	b.SetParallelism(4)
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			hook.Fire(entries[i%len(entries)])
+		}
+	})
+}
@@ -2,10 +2,18 @@ package lfslog
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
 	"github.com/dorofeevsa/logrus"
+	"io"
 	"io/ioutil"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 const expectedMsg = "This is the expected test message."
@@ -54,3 +62,1016 @@ func TestLogEntryWritten(t *testing.T) {
 	}
 
 }
+
+// Tests that templated paths are evaluated against the entry and that
+// field values can't escape the configured directory.
+func TestPathTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook, err := NewHook(filepath.Join(dir, "{{.Data.tenant}}", "{{.Level}}.log"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Hooks.Add(hook)
+
+	log.WithField("tenant", "acme").Info(expectedMsg)
+	log.WithField("tenant", "../evil").Warn(expectedMsg)
+
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "acme", "info.log"))
+	if err != nil {
+		t.Fatalf("Error while reading templated logfile: %s", err)
+	}
+	if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", contents, expectedMsg)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".._evil", "warning.log")); err != nil {
+		t.Errorf("Expected sanitized path component to be used: %s", err)
+	}
+
+	// missing and empty fields must not collapse into the parent directory
+	log.Error(expectedMsg)
+	log.SetLevel(logrus.DebugLevel)
+	log.WithField("tenant", "").Debug(expectedMsg)
+
+	for _, name := range []string{"error.log", "debug.log"} {
+		if _, err := os.Stat(filepath.Join(dir, "_", name)); err != nil {
+			t.Errorf("Expected placeholder path component to be used: %s", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("Logfile %s written to the parent directory", name)
+		}
+	}
+}
+
+// Tests that a message goes to the fallback writer when the logfile can't be opened.
+func TestFallbackWriter(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to generate logfile due to err: %s", err)
+	}
+	defer func() {
+		tmpfile.Close()
+		os.Remove(tmpfile.Name())
+	}()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	// a regular file can't be used as a directory
+	hook, err := NewHook(filepath.Join(tmpfile.Name(), "sub", "info.log"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fallback bytes.Buffer
+	hook.SetFallbackWriter(&fallback)
+	log.Hooks.Add(hook)
+
+	log.Info(expectedMsg)
+
+	if !bytes.Contains(fallback.Bytes(), []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", fallback.String(), expectedMsg)
+	}
+}
+
+// Tests that buffered output is held back until an error level entry is logged.
+func TestBufferedOutput(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to generate logfile due to err: %s", err)
+	}
+	fname := tmpfile.Name()
+	defer func() {
+		tmpfile.Close()
+		os.Remove(fname)
+	}()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook, err := NewHook(fname, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook.SetBufferSize(4096)
+	hook.SetFlushInterval(0)
+	defer hook.Close()
+	log.Hooks.Add(hook)
+
+	log.Info(expectedMsg)
+
+	contents, _ := ioutil.ReadFile(fname)
+	if len(contents) != 0 {
+		t.Errorf("Expected buffered output to be held back, got (%s)", contents)
+	}
+
+	log.Error(expectedMsg)
+
+	contents, _ = ioutil.ReadFile(fname)
+	if bytes.Count(contents, []byte("msg=\""+expectedMsg+"\"")) != 2 {
+		t.Errorf("Expected both messages to be flushed, got (%s)", contents)
+	}
+}
+
+// Tests that Reopen makes the hook recreate a logfile moved away by logrotate.
+func TestReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fname := filepath.Join(dir, "info.log")
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook, err := NewHook(fname, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	// buffered files are kept open until reopened
+	hook.SetBufferSize(1024)
+	log.Hooks.Add(hook)
+
+	log.Info(unexpectedMsg)
+
+	if err := os.Rename(fname, fname+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := hook.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+
+	log.Info(expectedMsg)
+	hook.Flush()
+
+	contents, err := ioutil.ReadFile(fname)
+	if err != nil {
+		t.Fatalf("Error while reading reopened logfile: %s", err)
+	}
+	if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", contents, expectedMsg)
+	}
+	if bytes.Contains(contents, []byte("msg=\""+unexpectedMsg+"\"")) {
+		t.Errorf("Message read (%s) contains message written before rotation (%s)", contents, unexpectedMsg)
+	}
+}
+
+// Tests that unbuffered files are opened for every write, so a moved file is
+// recreated without reopening.
+func TestOpenPerWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fname := filepath.Join(dir, "info.log")
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook := NewSinglePathHook(fname, nil)
+	defer hook.Close()
+	log.Hooks.Add(hook)
+
+	log.Info(unexpectedMsg)
+
+	if err := os.Rename(fname, fname+".1"); err != nil {
+		t.Fatal(err)
+	}
+
+	log.Info(expectedMsg)
+
+	contents, err := ioutil.ReadFile(fname)
+	if err != nil {
+		t.Fatalf("Error while reading recreated logfile: %s", err)
+	}
+	if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", contents, expectedMsg)
+	}
+	if bytes.Contains(contents, []byte("msg=\""+unexpectedMsg+"\"")) {
+		t.Errorf("Message read (%s) contains message written before the move (%s)", contents, unexpectedMsg)
+	}
+}
+
+// Tests that the number of files kept open is limited.
+func TestMaxOpenFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook := NewSinglePathHook(filepath.Join(dir, "{{.Data.tenant}}.log"), nil)
+	defer hook.Close()
+	hook.SetBufferSize(1024)
+	hook.SetMaxOpenFiles(10)
+	log.Hooks.Add(hook)
+
+	for i := 0; i < 100; i++ {
+		log.WithField("tenant", i).Info(expectedMsg)
+	}
+
+	if n := len(hook.files); n > 10 {
+		t.Errorf("%d files kept open, expected at most 10", n)
+	}
+
+	// evicted files are flushed
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "0.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", contents, expectedMsg)
+	}
+}
+
+// Tests that a logfile is renamed with a numeric suffix once it exceeds the maximum size.
+func TestMaxFileSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fname := filepath.Join(dir, "info.log")
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook, err := NewHook(fname, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook.SetMaxFileSize(int64(len(expectedMsg)))
+	defer hook.Close()
+	log.Hooks.Add(hook)
+
+	log.Info(unexpectedMsg)
+	log.Info(expectedMsg)
+
+	contents, err := ioutil.ReadFile(fname + ".1")
+	if err != nil {
+		t.Fatalf("Error while reading rolled over logfile: %s", err)
+	}
+	if !bytes.Contains(contents, []byte("msg=\""+unexpectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", contents, unexpectedMsg)
+	}
+
+	contents, err = ioutil.ReadFile(fname)
+	if err != nil {
+		t.Fatalf("Error while reading logfile: %s", err)
+	}
+	if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", contents, expectedMsg)
+	}
+}
+
+// Tests that levels not covered by the PathMap fall through to the default output.
+func TestNewHookWithDefaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	errorFile := filepath.Join(dir, "error.log")
+	var defaultOut bytes.Buffer
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook, err := NewHookWithDefaults(PathMap{
+		logrus.ErrorLevel: errorFile,
+	}, &defaultOut, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	log.Hooks.Add(hook)
+
+	log.Error(expectedMsg)
+	log.Info(unexpectedMsg)
+
+	contents, err := ioutil.ReadFile(errorFile)
+	if err != nil {
+		t.Fatalf("Error while reading logfile: %s", err)
+	}
+	if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) || bytes.Contains(contents, []byte("msg=\""+unexpectedMsg+"\"")) {
+		t.Errorf("Unexpected error logfile contents (%s)", contents)
+	}
+	if !bytes.Contains(defaultOut.Bytes(), []byte("msg=\""+unexpectedMsg+"\"")) || bytes.Contains(defaultOut.Bytes(), []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Unexpected default output contents (%s)", defaultOut.String())
+	}
+}
+
+// Tests that entries rejected by the filter are not written.
+func TestFilter(t *testing.T) {
+	var out bytes.Buffer
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook, err := NewHook(&out, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook.SetFilter(func(entry *logrus.Entry) bool {
+		return entry.Data["component"] != "healthcheck"
+	})
+	log.Hooks.Add(hook)
+
+	log.WithField("component", "healthcheck").Info(unexpectedMsg)
+	log.WithField("component", "api").Info(expectedMsg)
+
+	if !bytes.Contains(out.Bytes(), []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", out.String(), expectedMsg)
+	}
+	if bytes.Contains(out.Bytes(), []byte("msg=\""+unexpectedMsg+"\"")) {
+		t.Errorf("Message read (%s) contains filtered message (%s)", out.String(), unexpectedMsg)
+	}
+}
+
+// Tests that plain io.Writer level maps are accepted.
+func TestPlainWriterMap(t *testing.T) {
+	var infoOut, errorOut bytes.Buffer
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook, err := NewHook(map[logrus.Level]io.Writer{
+		logrus.InfoLevel:  &infoOut,
+		logrus.ErrorLevel: &errorOut,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Hooks.Add(hook)
+
+	log.Info(expectedMsg)
+
+	if !bytes.Contains(infoOut.Bytes(), []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", infoOut.String(), expectedMsg)
+	}
+	if errorOut.Len() != 0 {
+		t.Errorf("Expected no error output, got (%s)", errorOut.String())
+	}
+
+	if err := hook.Close(); err != nil {
+		t.Errorf("Unexpected error closing hook with non-closable writers: %s", err)
+	}
+}
+
+// Tests that the typed constructors configure the same outputs as NewHook.
+func TestTypedConstructors(t *testing.T) {
+	var infoOut, defaultOut bytes.Buffer
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(NewWriterHook(WriterMap{logrus.InfoLevel: &infoOut}, nil))
+	log.Hooks.Add(NewSingleWriterHook(&defaultOut, &logrus.JSONFormatter{}))
+
+	log.Info(expectedMsg)
+
+	if !bytes.Contains(infoOut.Bytes(), []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", infoOut.String(), expectedMsg)
+	}
+	if !bytes.Contains(defaultOut.Bytes(), []byte(`"msg":"`+expectedMsg+`"`)) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", defaultOut.String(), expectedMsg)
+	}
+}
+
+// Tests that an entry is written to every output of its level.
+func TestMultipleOutputsPerLevel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	errorFile := filepath.Join(dir, "error.log")
+	combinedFile := filepath.Join(dir, "combined.log")
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook := NewPathHook(PathMap{logrus.ErrorLevel: errorFile}, nil)
+	hook.AddPath(logrus.ErrorLevel, combinedFile)
+	hook.AddPath(logrus.InfoLevel, combinedFile)
+	defer hook.Close()
+	log.Hooks.Add(hook)
+
+	log.Error(expectedMsg)
+	log.Info(unexpectedMsg)
+
+	contents, err := ioutil.ReadFile(errorFile)
+	if err != nil {
+		t.Fatalf("Error while reading logfile: %s", err)
+	}
+	if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) || bytes.Contains(contents, []byte("msg=\""+unexpectedMsg+"\"")) {
+		t.Errorf("Unexpected error logfile contents (%s)", contents)
+	}
+
+	contents, err = ioutil.ReadFile(combinedFile)
+	if err != nil {
+		t.Fatalf("Error while reading logfile: %s", err)
+	}
+	if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) || !bytes.Contains(contents, []byte("msg=\""+unexpectedMsg+"\"")) {
+		t.Errorf("Unexpected combined logfile contents (%s)", contents)
+	}
+}
+
+// Tests that the hostname and pid are inserted before the extension.
+func TestInstanceSuffix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skip("hostname not available")
+	}
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook := NewSinglePathHook(filepath.Join(dir, "app.log"), nil)
+	hook.SetInstanceSuffix(true)
+	defer hook.Close()
+	log.Hooks.Add(hook)
+
+	log.Info(expectedMsg)
+
+	fname := filepath.Join(dir, fmt.Sprintf("app-%s-%d.log", sanitizePathComponent(hostname), os.Getpid()))
+	contents, err := ioutil.ReadFile(fname)
+	if err != nil {
+		t.Fatalf("Error while reading logfile: %s", err)
+	}
+	if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", contents, expectedMsg)
+	}
+}
+
+type countingCloser struct {
+	bytes.Buffer
+	closed int
+}
+
+func (c *countingCloser) Close() error {
+	c.closed++
+	return nil
+}
+
+// Tests that every distinct writer is closed exactly once.
+func TestCloseWriters(t *testing.T) {
+	shared := &countingCloser{}
+	info := &countingCloser{}
+
+	hook := NewWriterHook(WriterMap{
+		logrus.InfoLevel:  info,
+		logrus.WarnLevel:  shared,
+		logrus.ErrorLevel: shared,
+	}, nil)
+	hook.AddWriter(logrus.DebugLevel, shared)
+
+	if err := hook.Flush(); err != nil {
+		t.Errorf("Unexpected error flushing hook: %s", err)
+	}
+	if err := hook.Close(); err != nil {
+		t.Errorf("Unexpected error closing hook: %s", err)
+	}
+
+	if info.closed != 1 {
+		t.Errorf("Expected info writer to be closed once, was closed %d times", info.closed)
+	}
+	if shared.closed != 1 {
+		t.Errorf("Expected shared writer to be closed once, was closed %d times", shared.closed)
+	}
+}
+
+// Tests that compressed files can be read back after the hook is closed.
+func TestGzip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fname := filepath.Join(dir, "info.log.gz")
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook := NewSinglePathHook(fname, nil)
+	hook.SetGzip(true)
+	log.Hooks.Add(hook)
+
+	log.Info(expectedMsg)
+
+	if err := hook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(fname)
+	if err != nil {
+		t.Fatalf("Error while opening logfile: %s", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("Error while reading gzip header: %s", err)
+	}
+	contents, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("Error while reading compressed logfile: %s", err)
+	}
+	if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", contents, expectedMsg)
+	}
+}
+
+// Tests that compressed output is flushed periodically, not only on close.
+func TestGzipFlush(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fname := filepath.Join(dir, "info.log.gz")
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook := NewSinglePathHook(fname, nil)
+	defer hook.Close()
+	hook.SetGzip(true)
+	log.Hooks.Add(hook)
+
+	log.Info(expectedMsg)
+	time.Sleep(defaultFlushInterval + 500*time.Millisecond)
+
+	f, err := os.Open(fname)
+	if err != nil {
+		t.Fatalf("Error while opening logfile: %s", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("Error while reading gzip header: %s", err)
+	}
+	// the stream has no trailer before the hook is closed
+	contents, _ := ioutil.ReadAll(gz)
+	if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", contents, expectedMsg)
+	}
+}
+
+// Tests that compression can be enabled for a single file.
+func TestGzipPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	plain := filepath.Join(dir, "info.log")
+	compressed := filepath.Join(dir, "archive.log.gz")
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook := NewPathHook(PathMap{logrus.InfoLevel: plain}, nil)
+	hook.AddPath(logrus.InfoLevel, compressed)
+	hook.SetGzipPath(compressed)
+	log.Hooks.Add(hook)
+
+	log.Info(expectedMsg)
+
+	if err := hook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := ioutil.ReadFile(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", contents, expectedMsg)
+	}
+
+	f, err := os.Open(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("Error while reading gzip header: %s", err)
+	}
+	contents, err = ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("Error while reading compressed logfile: %s", err)
+	}
+	if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", contents, expectedMsg)
+	}
+}
+
+type indentingFormatter struct{}
+
+func (indentingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	return json.MarshalIndent(map[string]string{"msg": entry.Message}, "", "  ")
+}
+
+// Tests that strict JSON lines mode writes every entry as a single line.
+func TestStrictJSONLines(t *testing.T) {
+	var out bytes.Buffer
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook := NewSingleWriterHook(&out, indentingFormatter{})
+	hook.SetStrictJSONLines(true)
+	log.Hooks.Add(hook)
+
+	log.Info("first line\nsecond line")
+	log.Info(expectedMsg)
+
+	lines := bytes.Split(bytes.TrimSuffix(out.Bytes(), []byte("\n")), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d (%s)", len(lines), out.String())
+	}
+	for _, line := range lines {
+		if !json.Valid(line) {
+			t.Errorf("Expected valid JSON, got (%s)", line)
+		}
+	}
+
+	hook.SetFormatter(&logrus.TextFormatter{})
+	if err := hook.Fire(logrus.NewEntry(log)); err == nil {
+		t.Errorf("Expected error for non-JSON formatter")
+	}
+}
+
+// Tests that a memory hook captures output per level and per file without
+// touching the filesystem.
+func TestMemoryHook(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "test_lfshook_memory")
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook := NewMemoryHook(PathMap{
+		logrus.InfoLevel:  filepath.Join(dir, "{{.Data.tenant}}.log"),
+		logrus.ErrorLevel: filepath.Join(dir, "error.log"),
+	}, nil)
+	log.Hooks.Add(hook)
+
+	log.WithField("tenant", "acme").Info(expectedMsg)
+	log.Error(expectedMsg)
+	log.Warn(unexpectedMsg)
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Memory hook created %s", dir)
+	}
+
+	for _, contents := range [][]byte{
+		hook.Captured(logrus.InfoLevel),
+		hook.Captured(logrus.ErrorLevel),
+		hook.CapturedFile(filepath.Join(dir, "acme.log")),
+		hook.CapturedFile(filepath.Join(dir, "error.log")),
+	} {
+		if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) {
+			t.Errorf("Captured output (%s) doesnt contain message written (%s)", contents, expectedMsg)
+		}
+	}
+
+	if contents := hook.Captured(logrus.WarnLevel); contents != nil {
+		t.Errorf("Captured output (%s) for level without output", contents)
+	}
+}
+
+// Tests that levels can be rerouted and silenced at runtime.
+func TestSetPath(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	paths := PathMap{logrus.InfoLevel: "info.log"}
+	hook := NewMemoryHook(paths, nil)
+	log.Hooks.Add(hook)
+
+	hook.SetPath(logrus.InfoLevel, "rerouted.log")
+	log.Info(expectedMsg)
+
+	hook.RemovePath(logrus.InfoLevel)
+	log.Info(unexpectedMsg)
+
+	if contents := hook.CapturedFile("info.log"); contents != nil {
+		t.Errorf("Message written to old path: %s", contents)
+	}
+
+	contents := hook.CapturedFile("rerouted.log")
+	if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", contents, expectedMsg)
+	}
+	if bytes.Contains(contents, []byte("msg=\""+unexpectedMsg+"\"")) {
+		t.Errorf("Message read (%s) contains message written after RemovePath (%s)", contents, unexpectedMsg)
+	}
+
+	if paths[logrus.InfoLevel] != "info.log" {
+		t.Errorf("SetPath modified the given PathMap: %v", paths)
+	}
+}
+
+// Tests that writes and open errors are counted per level.
+func TestStats(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to generate logfile due to err: %s", err)
+	}
+	defer func() {
+		tmpfile.Close()
+		os.Remove(tmpfile.Name())
+	}()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	var buf bytes.Buffer
+	hook := NewWriterHook(WriterMap{logrus.InfoLevel: &buf}, nil)
+	// a regular file can't be used as a directory
+	hook.SetPath(logrus.ErrorLevel, filepath.Join(tmpfile.Name(), "sub", "error.log"))
+	log.Hooks.Add(hook)
+
+	log.Info(expectedMsg)
+	log.Info(expectedMsg)
+	log.Error(expectedMsg)
+
+	stats := hook.Stats()
+	if s := stats[logrus.InfoLevel]; s.EntriesWritten != 2 || s.BytesWritten != uint64(buf.Len()) {
+		t.Errorf("Unexpected info stats %+v for %d bytes written", s, buf.Len())
+	}
+	if s := stats[logrus.ErrorLevel]; s.OpenErrors != 1 || s.EntriesWritten != 0 || s.LastError.IsZero() {
+		t.Errorf("Unexpected error stats %+v", s)
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	var buf bytes.Buffer
+	hook := NewWriterHook(WriterMap{logrus.InfoLevel: &buf}, nil)
+	log.Hooks.Add(hook)
+
+	log.Info(expectedMsg)
+	log.Info(expectedMsg)
+
+	rec := httptest.NewRecorder()
+	hook.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE lfshook_written_entries_total counter",
+		`lfshook_written_entries_total{level="info"} 2`,
+		fmt.Sprintf(`lfshook_written_bytes_total{level="info"} %d`, buf.Len()),
+		`lfshook_write_errors_total{level="info"} 0`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Expected the metrics to contain %q, got %q", line, body)
+		}
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected content type %q", ct)
+	}
+}
+
+// Tests that entries are routed by field value and fall back to the level map.
+func TestFieldRouting(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook := NewMemoryHook(PathMap{logrus.InfoLevel: "info.log"}, nil)
+	hook.SetFieldRouting("component", map[string]string{"db": "db.log"})
+	log.Hooks.Add(hook)
+
+	log.WithField("component", "db").Info(expectedMsg)
+	log.WithField("component", "http").Info(unexpectedMsg)
+
+	contents := hook.CapturedFile("db.log")
+	if !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", contents, expectedMsg)
+	}
+	if bytes.Contains(contents, []byte("msg=\""+unexpectedMsg+"\"")) {
+		t.Errorf("Message read (%s) contains message of another component (%s)", contents, unexpectedMsg)
+	}
+
+	contents = hook.CapturedFile("info.log")
+	if !bytes.Contains(contents, []byte("msg=\""+unexpectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt contain unrouted message (%s)", contents, unexpectedMsg)
+	}
+	if bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) contains routed message (%s)", contents, expectedMsg)
+	}
+}
+
+// blockingWriter blocks every write until unblock is closed.
+type blockingWriter struct {
+	unblock chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.unblock
+	return len(p), nil
+}
+
+// Tests that a stalled writer times out and doesn't block other outputs.
+func TestWriteTimeout(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	stalled := &blockingWriter{unblock: make(chan struct{})}
+	var buf, fallback bytes.Buffer
+	hook := NewWriterHook(WriterMap{
+		logrus.InfoLevel: stalled,
+		logrus.WarnLevel: &buf,
+	}, nil)
+	hook.SetWriteTimeout(10 * time.Millisecond)
+	hook.SetFallbackWriter(&fallback)
+	log.Hooks.Add(hook)
+
+	log.Info(expectedMsg)
+	log.Info(expectedMsg)
+	log.Warn(expectedMsg)
+
+	if n := bytes.Count(fallback.Bytes(), []byte("msg=\""+expectedMsg+"\"")); n != 2 {
+		t.Errorf("Fallback got %d messages, expected 2: %s", n, fallback.String())
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", buf.String(), expectedMsg)
+	}
+	if s := hook.Stats()[logrus.InfoLevel]; s.WriteErrors != 2 {
+		t.Errorf("Unexpected info stats %+v", s)
+	}
+
+	close(stalled.unblock)
+}
+
+// Tests that the default output uses the default formatter.
+func TestDefaultFormatter(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook := NewMemoryHook(PathMap{logrus.ErrorLevel: "error.log"}, &logrus.JSONFormatter{})
+	hook.SetDefaultPath("app.log")
+	hook.SetDefaultFormatter(&logrus.TextFormatter{})
+	log.Hooks.Add(hook)
+
+	log.Error(expectedMsg)
+	log.Info(expectedMsg)
+
+	if contents := hook.CapturedFile("error.log"); !bytes.Contains(contents, []byte(`"msg":"`+expectedMsg+`"`)) {
+		t.Errorf("Message read (%s) isn't formatted as JSON", contents)
+	}
+	if contents := hook.CapturedFile("app.log"); !bytes.Contains(contents, []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) isn't formatted as text", contents)
+	}
+}
+
+// Tests that fields are projected per level without modifying the entry.
+func TestFieldProjection(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook := NewMemoryHook(PathMap{
+		logrus.InfoLevel:  "info.log",
+		logrus.ErrorLevel: "error.log",
+		logrus.WarnLevel:  "warn.log",
+	}, nil)
+	hook.SetFieldBlacklist(logrus.InfoLevel, "stacktrace")
+	hook.SetFieldWhitelist(logrus.WarnLevel, "request")
+	log.Hooks.Add(hook)
+
+	entry := log.WithFields(logrus.Fields{"stacktrace": "trace", "request": "id"})
+	entry.Info(expectedMsg)
+	entry.Error(expectedMsg)
+	entry.Warn(expectedMsg)
+
+	if contents := hook.CapturedFile("info.log"); bytes.Contains(contents, []byte("stacktrace")) || !bytes.Contains(contents, []byte("request=id")) {
+		t.Errorf("Unexpected fields in info file: %s", contents)
+	}
+	if contents := hook.CapturedFile("warn.log"); bytes.Contains(contents, []byte("stacktrace")) || !bytes.Contains(contents, []byte("request=id")) {
+		t.Errorf("Unexpected fields in warn file: %s", contents)
+	}
+	if contents := hook.CapturedFile("error.log"); !bytes.Contains(contents, []byte("stacktrace=trace")) {
+		t.Errorf("Missing field in error file: %s", contents)
+	}
+	if len(entry.Data) != 2 {
+		t.Errorf("Projection modified the entry: %v", entry.Data)
+	}
+}
+
+// Tests that flushing a stalled writer doesn't block writes to other outputs.
+func TestFlushDoesNotBlock(t *testing.T) {
+	stalled := &blockingWriter{unblock: make(chan struct{})}
+	var buf bytes.Buffer
+	hook := NewWriterHook(WriterMap{
+		logrus.DebugLevel: stalled,
+		logrus.ErrorLevel: &buf,
+	}, nil)
+	hook.SetBufferSize(1024)
+	hook.SetFlushInterval(10 * time.Millisecond)
+
+	logger := logrus.New()
+	hook.Fire(&logrus.Entry{Logger: logger, Level: logrus.DebugLevel, Message: unexpectedMsg, Data: logrus.Fields{}})
+	// let the flusher get stuck on the stalled writer
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		hook.Fire(&logrus.Entry{Logger: logger, Level: logrus.ErrorLevel, Message: expectedMsg, Data: logrus.Fields{}})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Write blocked by the flush of another output")
+	}
+
+	close(stalled.unblock)
+	<-done
+	hook.Close()
+}
+
+// Tests that opening a file on a stalled filesystem times out without
+// blocking other outputs.
+func TestOpenTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	openLogFile = func(name string, flag int, perm os.FileMode) (*os.File, error) {
+		<-unblock
+		return os.OpenFile(name, flag, perm)
+	}
+	defer func() { openLogFile = os.OpenFile }()
+
+	dir, err := ioutil.TempDir("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	var buf, fallback bytes.Buffer
+	hook := NewPathHook(PathMap{logrus.InfoLevel: filepath.Join(dir, "info.log")}, nil)
+	hook.AddWriter(logrus.WarnLevel, &buf)
+	hook.SetWriteTimeout(10 * time.Millisecond)
+	hook.SetFallbackWriter(&fallback)
+	log.Hooks.Add(hook)
+
+	done := make(chan struct{})
+	go func() {
+		log.Info(expectedMsg)
+		log.Warn(expectedMsg)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Logging blocked by a stalled open")
+	}
+
+	if !bytes.Contains(fallback.Bytes(), []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Fallback read (%s) doesnt match message written (%s)", fallback.String(), expectedMsg)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("msg=\""+expectedMsg+"\"")) {
+		t.Errorf("Message read (%s) doesnt match message written (%s)", buf.String(), expectedMsg)
+	}
+
+	// wait for the stalled open before restoring the opener
+	close(unblock)
+	hook.SetWriteTimeout(0)
+	hook.Close()
+}
@@ -0,0 +1,51 @@
+package lfslog
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// MetricsHandler returns an http.Handler serving the counters of Stats in the
+// Prometheus text format, labeled by level, so Prometheus can scrape them
+// without a client library:
+//
+//	http.Handle("/metrics/lfshook", hook.MetricsHandler())
+func (hook *LfsHook) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		stats := hook.Stats()
+		levels := make([]logrus.Level, 0, len(stats))
+		for level := range stats {
+			levels = append(levels, level)
+		}
+		sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+
+		b := bufio.NewWriter(w)
+		for _, metric := range []struct {
+			name, help, kind string
+			value            func(LevelStats) float64
+		}{
+			{"lfshook_written_bytes_total", "Formatted bytes written.", "counter", func(s LevelStats) float64 { return float64(s.BytesWritten) }},
+			{"lfshook_written_entries_total", "Entries written, once per output.", "counter", func(s LevelStats) float64 { return float64(s.EntriesWritten) }},
+			{"lfshook_open_errors_total", "Files that couldn't be opened.", "counter", func(s LevelStats) float64 { return float64(s.OpenErrors) }},
+			{"lfshook_write_errors_total", "Failed writes.", "counter", func(s LevelStats) float64 { return float64(s.WriteErrors) }},
+			{"lfshook_last_error_timestamp_seconds", "Time of the most recent open or write error.", "gauge", func(s LevelStats) float64 {
+				if s.LastError.IsZero() {
+					return 0
+				}
+				return float64(s.LastError.UnixNano()) / 1e9
+			}},
+		} {
+			fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind)
+			for _, level := range levels {
+				fmt.Fprintf(b, "%s{level=%q} %v\n", metric.name, level.String(), metric.value(stats[level]))
+			}
+		}
+		b.Flush()
+	})
+}
@@ -0,0 +1,18 @@
+package lfslog
+
+// owner is the user and group files created by the hook are given.
+type owner struct {
+	uid, gid int
+}
+
+// SetOwner makes the hook change the owner of every file it creates to uid
+// and gid, so services that start as root but drop privileges still produce
+// files readable by the log-shipping user. A uid or gid of -1 leaves it
+// unchanged. Existing files keep their owner. Changing the owner is not
+// supported on Windows and Plan 9.
+func (hook *LfsHook) SetOwner(uid, gid int) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	hook.owner = &owner{uid: uid, gid: gid}
+}
@@ -0,0 +1,46 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package lfslog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// Tests that files created by the hook are given the configured owner.
+func TestSetOwner(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("changing the owner of a file requires root")
+	}
+
+	dir, err := ioutil.TempDir("", "test_lfshook")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	fname := filepath.Join(dir, "info.log")
+	hook := NewSinglePathHook(fname, nil)
+	hook.SetOwner(1, 1)
+	log.Hooks.Add(hook)
+
+	log.Info(expectedMsg)
+	hook.Close()
+
+	fi, err := os.Stat(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := fi.Sys().(*syscall.Stat_t); st.Uid != 1 || st.Gid != 1 {
+		t.Errorf("Logfile is owned by %d:%d, expected 1:1", st.Uid, st.Gid)
+	}
+}
@@ -0,0 +1,89 @@
+package lfslog
+
+import (
+	"github.com/dorofeevsa/logrus"
+)
+
+// projection selects the fields of an entry that are written.
+type projection struct {
+	whitelist map[string]bool
+	blacklist map[string]bool
+}
+
+// SetFieldWhitelist makes the hook write only the given fields of entries of
+// level, e.g. to keep request bodies out of the info file. Calling it without
+// fields removes the whitelist.
+func (hook *LfsHook) SetFieldWhitelist(level logrus.Level, fields ...string) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	p := hook.projection(level)
+	p.whitelist = fieldSet(fields)
+	hook.projections[level] = p
+}
+
+// SetFieldBlacklist makes the hook drop the given fields from entries of
+// level, e.g. to drop stack traces from the info file while keeping them in
+// the error file. Calling it without fields removes the blacklist.
+func (hook *LfsHook) SetFieldBlacklist(level logrus.Level, fields ...string) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	p := hook.projection(level)
+	p.blacklist = fieldSet(fields)
+	hook.projections[level] = p
+}
+
+// projection returns a copy of the projection of level to be modified, as
+// Fire uses the current one without holding hook.lock.
+// Must be called with hook.lock held.
+func (hook *LfsHook) projection(level logrus.Level) *projection {
+	if hook.projections == nil {
+		hook.projections = make(map[logrus.Level]*projection)
+	}
+
+	p := new(projection)
+	if current, ok := hook.projections[level]; ok {
+		*p = *current
+	}
+
+	return p
+}
+
+func fieldSet(fields []string) map[string]bool {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	set := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		set[field] = true
+	}
+
+	return set
+}
+
+// project returns a copy of entry holding only the fields selected by p. The
+// entry itself is returned when nothing is dropped, the logger's entry is
+// never modified.
+func (p *projection) project(entry *logrus.Entry) *logrus.Entry {
+	if p == nil || (p.whitelist == nil && p.blacklist == nil) {
+		return entry
+	}
+
+	data := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		if p.whitelist != nil && !p.whitelist[k] {
+			continue
+		}
+		if p.blacklist[k] {
+			continue
+		}
+		data[k] = v
+	}
+
+	projected := *entry
+	projected.Data = data
+
+	return &projected
+}
@@ -0,0 +1,49 @@
+package lfslog
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Reopen flushes and closes every file opened by the hook. Files are opened
+// again on the next write, so external tools like logrotate can move the
+// current files away and signal the process afterwards.
+func (hook *LfsHook) Reopen() error {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	return hook.closeTargets()
+}
+
+// ReopenOnSignal installs a signal handler that calls Reopen whenever one of
+// sigs is received, SIGHUP if none are given. The returned function removes
+// the handler.
+func (hook *LfsHook) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				if err := hook.Reopen(); err != nil {
+					log.Println("failed to reopen logfiles:", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
@@ -0,0 +1,47 @@
+package lfslog
+
+import (
+	"fmt"
+	"os"
+)
+
+// SetMaxFileSize sets the size in bytes a log file may grow to. When a write
+// would exceed it, the file is renamed with a numeric suffix of the form
+// ".1", ".2" and so forth, and a new file is started. Compressed files are
+// rolled over once their compressed size on disk reaches the limit. A size
+// of 0 disables the limit.
+func (hook *LfsHook) SetMaxFileSize(size int64) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	hook.maxFileSize = size
+}
+
+// rollover starts a new file at path if writing n more bytes to t would
+// exceed maxSize. The target is reopened in place, so it stays valid for
+// concurrent writers waiting for its lock.
+func (t *target) rollover(path string, maxSize int64, n int) error {
+	if t.compress {
+		// the compressed size of msg isn't known before writing it
+		n = 0
+	}
+	if maxSize <= 0 || t.size == 0 || t.size+int64(n) <= maxSize {
+		return nil
+	}
+
+	if err := t.closeFile(); err != nil {
+		return err
+	}
+
+	for generation := 1; ; generation++ {
+		name := fmt.Sprintf("%s.%d", path, generation)
+		if _, err := os.Stat(name); err != nil {
+			if err := os.Rename(path, name); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	return t.open(path)
+}
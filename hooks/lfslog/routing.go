@@ -0,0 +1,42 @@
+package lfslog
+
+import (
+	"fmt"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// SetFieldRouting routes entries by the value of field, e.g.
+//
+//	hook.SetFieldRouting("component", map[string]string{"db": "/var/log/db.log"})
+//
+// writes every entry with `component=db` to /var/log/db.log instead of its
+// level's outputs. Entries without the field, or with a value that isn't in
+// routes, fall back to the level map. Route paths may be templates. A nil
+// routes map disables routing.
+func (hook *LfsHook) SetFieldRouting(field string, routes map[string]string) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	hook.routeField = field
+	hook.routes = make(map[string]string, len(routes))
+	for value, path := range routes {
+		hook.routes[value] = path
+	}
+}
+
+// route returns the path entry is routed to by its field value.
+// Must be called with hook.lock held.
+func (hook *LfsHook) route(entry *logrus.Entry) (string, bool) {
+	if len(hook.routes) == 0 {
+		return "", false
+	}
+
+	value, ok := entry.Data[hook.routeField]
+	if !ok {
+		return "", false
+	}
+
+	path, ok := hook.routes[fmt.Sprint(value)]
+	return path, ok
+}
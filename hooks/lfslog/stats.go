@@ -0,0 +1,78 @@
+package lfslog
+
+import (
+	"time"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// LevelStats holds the write counters of a level, summed over all of its
+// outputs.
+type LevelStats struct {
+	// BytesWritten is the number of formatted bytes written.
+	BytesWritten uint64
+	// EntriesWritten is the number of entries written, counted once per output.
+	EntriesWritten uint64
+	// OpenErrors is the number of times a file couldn't be opened.
+	OpenErrors uint64
+	// WriteErrors is the number of failed writes to a file or writer.
+	WriteErrors uint64
+	// LastError is the time of the most recent open or write error.
+	LastError time.Time
+}
+
+// Stats returns a snapshot of the write counters of every level that has
+// been written to, so operators can notice a level's output that has been
+// failing for a long time.
+func (hook *LfsHook) Stats() map[logrus.Level]LevelStats {
+	hook.statsLock.Lock()
+	defer hook.statsLock.Unlock()
+
+	stats := make(map[logrus.Level]LevelStats, len(hook.stats))
+	for level, s := range hook.stats {
+		stats[level] = *s
+	}
+
+	return stats
+}
+
+// Must be called with hook.statsLock held.
+func (hook *LfsHook) levelStats(level logrus.Level) *LevelStats {
+	if hook.stats == nil {
+		hook.stats = make(map[logrus.Level]*LevelStats)
+	}
+	s, ok := hook.stats[level]
+	if !ok {
+		s = new(LevelStats)
+		hook.stats[level] = s
+	}
+
+	return s
+}
+
+func (hook *LfsHook) countWrite(level logrus.Level, n int) {
+	hook.statsLock.Lock()
+	defer hook.statsLock.Unlock()
+
+	s := hook.levelStats(level)
+	s.BytesWritten += uint64(n)
+	s.EntriesWritten++
+}
+
+func (hook *LfsHook) countOpenError(level logrus.Level) {
+	hook.statsLock.Lock()
+	defer hook.statsLock.Unlock()
+
+	s := hook.levelStats(level)
+	s.OpenErrors++
+	s.LastError = time.Now()
+}
+
+func (hook *LfsHook) countWriteError(level logrus.Level) {
+	hook.statsLock.Lock()
+	defer hook.statsLock.Unlock()
+
+	s := hook.levelStats(level)
+	s.WriteErrors++
+	s.LastError = time.Now()
+}
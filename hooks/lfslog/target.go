@@ -0,0 +1,463 @@
+package lfslog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// target is a destination formatted entries are written to: a file opened by
+// the hook or a writer supplied by the user, optionally buffered. Files may be
+// gzip compressed, size then counts the compressed bytes on disk.
+//
+// Every target has its own lock, so a slow destination doesn't block writes
+// to the others. It must be held while using the target, see do. When
+// hook.lock is needed as well, it's always acquired first.
+type target struct {
+	lock    *sync.Mutex
+	closed  bool
+	stalled int32
+
+	w    io.Writer
+	file *os.File
+	gz   *gzip.Writer
+	buf  *bufio.Writer
+	size int64
+
+	bufferSize int
+	compress   bool
+	owner      *owner
+
+	// lastUse orders file targets by their last use, guarded by hook.lock.
+	lastUse uint64
+}
+
+func newTarget(w io.Writer, bufferSize int) *target {
+	t := &target{lock: new(sync.Mutex), bufferSize: bufferSize}
+	t.reset(w)
+
+	return t
+}
+
+// reset points the target to w, wrapped in a new buffer if buffering is enabled.
+func (t *target) reset(w io.Writer) {
+	t.w = w
+	t.buf = nil
+	if t.bufferSize > 0 {
+		t.buf = bufio.NewWriterSize(w, t.bufferSize)
+	}
+}
+
+// openLogFile opens log files, replaced by tests.
+var openLogFile = os.OpenFile
+
+// open opens the file at path for appending, creating it and its directory
+// if needed.
+func (t *target) open(path string) error {
+	dir := filepath.Dir(path)
+	os.MkdirAll(dir, os.ModePerm)
+
+	_, statErr := os.Stat(path)
+	fd, err := openLogFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+
+	if t.owner != nil && os.IsNotExist(statErr) {
+		if err := fd.Chown(t.owner.uid, t.owner.gid); err != nil {
+			log.Println("failed to change owner of logfile:", path, err)
+		}
+	}
+
+	t.file = fd
+	t.size = 0
+	if fi, err := fd.Stat(); err == nil {
+		t.size = fi.Size()
+	}
+
+	if t.compress {
+		t.gz = gzip.NewWriter(&countingWriter{w: fd, n: &t.size})
+		t.reset(t.gz)
+	} else {
+		t.reset(fd)
+	}
+
+	return nil
+}
+
+// keepOpen reports whether the file stays open between writes. Unbuffered,
+// uncompressed files are opened for every write, so files moved or deleted
+// by other tools are recreated right away.
+func (t *target) keepOpen() bool {
+	return t.bufferSize > 0 || t.compress
+}
+
+func (t *target) Write(p []byte) (n int, err error) {
+	if t.buf != nil {
+		n, err = t.buf.Write(p)
+	} else {
+		n, err = t.w.Write(p)
+	}
+	if !t.compress {
+		t.size += int64(n)
+	}
+
+	return n, err
+}
+
+// countingWriter adds the number of bytes written to w to n.
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	*cw.n += int64(n)
+
+	return n, err
+}
+
+// write writes msg, flushing right away for entries at error level or above.
+func (t *target) write(msg []byte, level logrus.Level) error {
+	if _, err := t.Write(msg); err != nil {
+		return err
+	}
+
+	if level <= logrus.ErrorLevel {
+		return t.Flush()
+	}
+
+	return nil
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (t *target) Flush() error {
+	if t.buf != nil {
+		if err := t.buf.Flush(); err != nil {
+			return err
+		}
+	}
+	if t.gz != nil {
+		return t.gz.Flush()
+	}
+
+	return nil
+}
+
+// Close flushes the target, writes the gzip trailer and closes the file if
+// it's owned by the hook. A closed target is never written to again.
+func (t *target) Close() error {
+	t.closed = true
+
+	return t.closeFile()
+}
+
+func (t *target) closeFile() error {
+	err := t.Flush()
+	if t.gz != nil {
+		if cerr := t.gz.Close(); err == nil {
+			err = cerr
+		}
+		t.gz = nil
+	}
+	if t.file != nil {
+		if cerr := t.file.Close(); err == nil {
+			err = cerr
+		}
+		t.file = nil
+	}
+
+	return err
+}
+
+// errTargetClosed is returned by withTarget's fn when the target was closed
+// before its lock could be acquired.
+var errTargetClosed = errors.New("target closed")
+
+// withTarget calls fn with the target returned by get and its lock held. get
+// is called with hook.lock held, and again if the target was closed by Reopen
+// or a configuration change before it could be locked.
+// Must be called without hook.lock held.
+func (hook *LfsHook) withTarget(get func() (*target, error), fn func(*target) error) error {
+	for {
+		hook.lock.Lock()
+		t, err := get()
+		timeout := hook.writeTimeout
+		hook.lock.Unlock()
+		if err != nil {
+			return err
+		}
+
+		err = t.do(timeout, func() error {
+			if t.closed {
+				return errTargetClosed
+			}
+			return fn(t)
+		})
+		if err != errTargetClosed {
+			return err
+		}
+	}
+}
+
+// SetBufferSize enables buffering of every file and writer the hook writes to.
+// A size of 0 disables buffering. Buffers are flushed every flush interval
+// (one second unless changed with SetFlushInterval), after every entry at
+// error level or above, and when the hook is closed.
+func (hook *LfsHook) SetBufferSize(size int) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	hook.closeTargets()
+	hook.bufferSize = size
+
+	if size > 0 && hook.flushStop == nil {
+		hook.startFlushing(defaultFlushInterval)
+	}
+}
+
+// SetFlushInterval sets how often buffered output is flushed.
+// An interval of 0 disables periodic flushing.
+func (hook *LfsHook) SetFlushInterval(interval time.Duration) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	hook.stopFlushing()
+	if interval > 0 {
+		hook.startFlushing(interval)
+	}
+}
+
+// Must be called with hook.lock held.
+func (hook *LfsHook) startFlushing(interval time.Duration) {
+	stop := make(chan struct{})
+	hook.flushStop = stop
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				hook.lock.Lock()
+				files, buffers := hook.targets()
+				timeout := hook.writeTimeout
+				hook.lock.Unlock()
+
+				// flush without hook.lock, so a slow target doesn't block Fire
+				for _, t := range append(files, buffers...) {
+					t.do(timeout, t.Flush)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Must be called with hook.lock held.
+func (hook *LfsHook) stopFlushing() {
+	if hook.flushStop != nil {
+		close(hook.flushStop)
+		hook.flushStop = nil
+	}
+}
+
+// fileTarget returns the cached target for path. The file itself is opened
+// by the first write, outside of hook.lock. When the cache is full, the least
+// recently used target is closed.
+// Must be called with hook.lock held.
+func (hook *LfsHook) fileTarget(path string, compress bool) *target {
+	hook.uses++
+
+	if t, ok := hook.files[path]; ok {
+		t.lastUse = hook.uses
+		return t
+	}
+
+	max := hook.maxOpenFiles
+	if max == 0 {
+		max = defaultMaxOpenFiles
+	}
+	if max > 0 && len(hook.files) >= max {
+		hook.evictFile()
+	}
+
+	t := &target{
+		lock:       new(sync.Mutex),
+		bufferSize: hook.bufferSize,
+		compress:   compress,
+		owner:      hook.owner,
+		lastUse:    hook.uses,
+	}
+
+	if hook.files == nil {
+		hook.files = make(map[string]*target)
+	}
+	hook.files[path] = t
+
+	return t
+}
+
+// evictFile closes the least recently used file target.
+// Must be called with hook.lock held.
+func (hook *LfsHook) evictFile() {
+	var (
+		lruPath string
+		lru     *target
+	)
+	for path, t := range hook.files {
+		if lru == nil || t.lastUse < lru.lastUse {
+			lruPath, lru = path, t
+		}
+	}
+
+	if lru != nil {
+		hook.closeTarget(lru)
+		delete(hook.files, lruPath)
+	}
+}
+
+// SetMaxOpenFiles sets how many files the hook keeps open, 64 unless changed.
+// Files are kept open while buffering or compression is enabled, and with
+// path templates every distinct path is a file of its own. When the limit is
+// reached, the least recently used file is closed. A limit below 0 disables
+// it.
+func (hook *LfsHook) SetMaxOpenFiles(max int) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	hook.maxOpenFiles = max
+	for max > 0 && len(hook.files) > max {
+		hook.evictFile()
+	}
+}
+
+// dropFile removes the closed target t of path from the cache, so the file
+// is opened again on the next write.
+// Must be called with hook.lock held.
+func (hook *LfsHook) dropFile(path string, t *target) {
+	if hook.files[path] == t {
+		delete(hook.files, path)
+	}
+}
+
+// writerTarget returns the target wrapping a user supplied writer. Writers that
+// can't be used as map keys are written to directly, without buffering, and
+// share a single lock.
+// Must be called with hook.lock held.
+func (hook *LfsHook) writerTarget(w io.Writer) *target {
+	if !reflect.TypeOf(w).Comparable() {
+		t := newTarget(w, 0)
+		t.lock = &hook.uncomparableLock
+		return t
+	}
+
+	if t, ok := hook.buffers[w]; ok {
+		return t
+	}
+
+	t := newTarget(w, hook.bufferSize)
+	if hook.buffers == nil {
+		hook.buffers = make(map[io.Writer]*target)
+	}
+	hook.buffers[w] = t
+
+	return t
+}
+
+// targets returns the cached file and writer targets.
+// Must be called with hook.lock held.
+func (hook *LfsHook) targets() (files, buffers []*target) {
+	for _, t := range hook.files {
+		files = append(files, t)
+	}
+	for _, t := range hook.buffers {
+		buffers = append(buffers, t)
+	}
+
+	return files, buffers
+}
+
+// closeTargets flushes all targets and closes the files opened by the hook.
+// Must be called with hook.lock held.
+func (hook *LfsHook) closeTargets() error {
+	var err error
+	for path, t := range hook.files {
+		if cerr := hook.closeTarget(t); cerr != nil && err == nil {
+			err = cerr
+		}
+		delete(hook.files, path)
+	}
+	for w, t := range hook.buffers {
+		if cerr := hook.closeTarget(t); cerr != nil && err == nil {
+			err = cerr
+		}
+		delete(hook.buffers, w)
+	}
+
+	return err
+}
+
+// closeTarget closes t within the write timeout. Stalled targets are closed
+// in the background once their pending writes complete.
+// Must be called with hook.lock held.
+func (hook *LfsHook) closeTarget(t *target) error {
+	err := t.do(hook.writeTimeout, t.Close)
+	if err == ErrWriteTimeout {
+		go func() {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+
+			t.Close()
+		}()
+	}
+
+	return err
+}
+
+// SetGzip enables gzip compression of every file the hook writes to, for
+// archival files that are only ever read by tooling. Each time a file is
+// opened a new gzip member is appended, which standard tools read as one
+// stream. Compressed output is flushed with a gzip sync marker every flush
+// interval and after every entry at error level or above, and closing the
+// hook writes the trailer. Writers are never compressed; use SetGzipPath to
+// compress only some of the files.
+func (hook *LfsHook) SetGzip(enabled bool) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	hook.closeTargets()
+	hook.gzip = enabled
+	if enabled && hook.flushStop == nil {
+		hook.startFlushing(defaultFlushInterval)
+	}
+}
+
+// SetGzipPath enables gzip compression of the file at path, which must be
+// given exactly like in the PathMap or AddPath, e.g. for an archival copy
+// next to a plain file. See SetGzip.
+func (hook *LfsHook) SetGzipPath(path string) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	hook.closeTargets()
+	if hook.gzipPaths == nil {
+		hook.gzipPaths = make(map[string]bool)
+	}
+	hook.gzipPaths[path] = true
+	if hook.flushStop == nil {
+		hook.startFlushing(defaultFlushInterval)
+	}
+}
@@ -0,0 +1,153 @@
+package lfslog
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+	"unicode"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// pathTemplateData is the value a path template is executed against.
+// Every value that comes from the entry is sanitized so it can't escape
+// the directory layout described by the template.
+type pathTemplateData struct {
+	Level string
+	Time  time.Time
+	Data  map[string]string
+}
+
+// pathTemplate is a parsed path template and the entry fields it uses.
+type pathTemplate struct {
+	tmpl   *template.Template
+	fields []string
+}
+
+// newPathTemplateData returns the data for entry. The fields used by the
+// template that the entry doesn't have render as a placeholder, so a
+// missing field can't collapse a path element.
+func newPathTemplateData(entry *logrus.Entry, fields []string) *pathTemplateData {
+	data := make(map[string]string, len(entry.Data)+len(fields))
+	for _, k := range fields {
+		data[k] = sanitizePathComponent("")
+	}
+	for k, v := range entry.Data {
+		data[k] = sanitizePathComponent(fmt.Sprint(v))
+	}
+
+	return &pathTemplateData{
+		Level: entry.Level.String(),
+		Time:  entry.Time,
+		Data:  data,
+	}
+}
+
+// isPathTemplate reports whether path has to be evaluated against the entry.
+func isPathTemplate(path string) bool {
+	return strings.Contains(path, "{{")
+}
+
+// sanitizePathComponent replaces separators and control characters, and
+// neutralizes relative and empty components, so a field value always stays a
+// single path element.
+func sanitizePathComponent(s string) string {
+	if s == "" {
+		return "_"
+	}
+
+	s = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, s)
+
+	if strings.Trim(s, ".") == "" {
+		return strings.Repeat("_", len(s))
+	}
+
+	return s
+}
+
+// resolvePath returns the file path for the entry, evaluating path as a
+// template such as `/var/log/app/{{.Data.service}}/{{.Level}}.log` when needed.
+// Fields missing from the entry, or empty, render as "_".
+// Must be called with hook.lock held.
+func (hook *LfsHook) resolvePath(path string, entry *logrus.Entry) (string, error) {
+	if !isPathTemplate(path) {
+		return path, nil
+	}
+
+	pt, ok := hook.templates[path]
+	if !ok {
+		tmpl, err := template.New("path").Option("missingkey=zero").Parse(path)
+		if err != nil {
+			return "", fmt.Errorf("invalid path template %q: %v", path, err)
+		}
+		pt = &pathTemplate{tmpl: tmpl, fields: templateFields(tmpl.Tree.Root)}
+
+		if hook.templates == nil {
+			hook.templates = make(map[string]*pathTemplate)
+		}
+		hook.templates[path] = pt
+	}
+
+	var b bytes.Buffer
+	if err := pt.tmpl.Execute(&b, newPathTemplateData(entry, pt.fields)); err != nil {
+		return "", fmt.Errorf("failed to execute path template %q: %v", path, err)
+	}
+
+	return filepath.Clean(b.String()), nil
+}
+
+// templateFields returns the names of the `.Data.<field>` references in the
+// template tree rooted at node.
+func templateFields(node parse.Node) []string {
+	var fields []string
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			fields = append(fields, templateFields(child)...)
+		}
+	case *parse.ActionNode:
+		fields = templateFields(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			fields = append(fields, templateFields(cmd)...)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			fields = append(fields, templateFields(arg)...)
+		}
+	case *parse.IfNode:
+		fields = templateBranchFields(&n.BranchNode)
+	case *parse.RangeNode:
+		fields = templateBranchFields(&n.BranchNode)
+	case *parse.WithNode:
+		fields = templateBranchFields(&n.BranchNode)
+	case *parse.FieldNode:
+		if len(n.Ident) == 2 && n.Ident[0] == "Data" {
+			fields = append(fields, n.Ident[1])
+		}
+	}
+
+	return fields
+}
+
+func templateBranchFields(n *parse.BranchNode) []string {
+	fields := templateFields(n.Pipe)
+	fields = append(fields, templateFields(n.List)...)
+	return append(fields, templateFields(n.ElseList)...)
+}
@@ -0,0 +1,74 @@
+package lfslog
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrWriteTimeout is returned when writing to a file or writer took longer
+// than the write timeout.
+var ErrWriteTimeout = errors.New("write timed out")
+
+// SetWriteTimeout sets how long opening, writing, flushing or closing a single
+// file or writer may take, so a stalled NFS or FUSE mount backing one file can't block
+// the hook, and therefore all application logging, indefinitely. A write that
+// times out is reported as ErrWriteTimeout and the message goes to the
+// fallback writer, while the stalled write keeps running in the background
+// and may still complete later. Until it does, further writes to the same
+// output fail right away. A timeout of 0 disables the deadline.
+func (hook *LfsHook) SetWriteTimeout(timeout time.Duration) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	hook.writeTimeout = timeout
+}
+
+// do calls fn with t.lock held. With a timeout, fn runs on a watchdog
+// goroutine and ErrWriteTimeout is returned if it doesn't finish in time.
+// The target is then considered stalled until fn returns.
+func (t *target) do(timeout time.Duration, fn func() error) error {
+	if timeout <= 0 {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+
+		return fn()
+	}
+
+	if atomic.LoadInt32(&t.stalled) > 0 {
+		return ErrWriteTimeout
+	}
+
+	const (
+		running int32 = iota
+		finished
+		timedOut
+	)
+	var state int32
+	done := make(chan error, 1)
+
+	go func() {
+		t.lock.Lock()
+		err := fn()
+		t.lock.Unlock()
+
+		if !atomic.CompareAndSwapInt32(&state, running, finished) {
+			atomic.AddInt32(&t.stalled, -1)
+		}
+		done <- err
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		if atomic.CompareAndSwapInt32(&state, running, timedOut) {
+			atomic.AddInt32(&t.stalled, 1)
+			return ErrWriteTimeout
+		}
+		return <-done
+	}
+}
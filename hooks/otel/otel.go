@@ -0,0 +1,40 @@
+// Package otel correlates log entries with OpenTelemetry traces.
+package otel
+
+import (
+	"context"
+
+	"github.com/dorofeevsa/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Field names of the trace context, as in the OpenTelemetry log data model.
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+// Extractor is a context extractor adding the trace ID, span ID and trace
+// flags of the active span of the entry's context, so logs and traces can be
+// joined in the backend. Entries without a valid span get no fields.
+//
+//	logger.AddContextExtractor(otel.Extractor)
+//	logger.WithContext(ctx).Info("handled request")
+func Extractor(ctx context.Context) logrus.Fields {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+
+	return logrus.Fields{
+		TraceIDKey:    sc.TraceID().String(),
+		SpanIDKey:     sc.SpanID().String(),
+		TraceFlagsKey: sc.TraceFlags().String(),
+	}
+}
+
+// Register adds Extractor to logger.
+func Register(logger *logrus.Logger) {
+	logger.AddContextExtractor(Extractor)
+}
@@ -0,0 +1,44 @@
+package otel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/dorofeevsa/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestExtractor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &buf
+	logger.Formatter = &logrus.JSONFormatter{}
+	Register(logger)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	logger.WithContext(trace.ContextWithSpanContext(context.Background(), sc)).Info("traced")
+	logger.WithContext(context.Background()).Info("untraced")
+
+	dec := json.NewDecoder(&buf)
+	var fields logrus.Fields
+	if err := dec.Decode(&fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields[TraceIDKey] != "4bf92f3577b34da6a3ce929d0e0e4736" || fields[SpanIDKey] != "00f067aa0ba902b7" || fields[TraceFlagsKey] != "01" {
+		t.Errorf("Unexpected trace fields %v", fields)
+	}
+
+	fields = nil
+	if err := dec.Decode(&fields); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fields[TraceIDKey]; ok {
+		t.Errorf("Expected no trace fields without a span, got %v", fields)
+	}
+}
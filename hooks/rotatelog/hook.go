@@ -80,6 +80,11 @@ func (hook *RotatelogHook) SetFormatter(formatter logrus.Formatter) *RotatelogHo
 	return hook
 }
 
+// Flush syncs the current file to disk, see logrus.Logger.Flush.
+func (hook *RotatelogHook) Flush() error {
+	return hook.w.Flush()
+}
+
 func (hook *RotatelogHook) Close() error {
 	return hook.w.Close()
 }
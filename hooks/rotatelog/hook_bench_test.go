@@ -0,0 +1,36 @@
+package rotatelog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+func BenchmarkHook(b *testing.B) {
+	dir, err := ioutil.TempDir("", "rotatelog")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hook, err := NewHook(filepath.Join(dir, "access_log.%Y%m%d%H%M"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer hook.Close()
+	hook.SetFormatter(&logrus.JSONFormatter{})
+
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	logger.AddHook(hook)
+	entry := logger.WithField("status", 200)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		entry.Info("request handled")
+	}
+}
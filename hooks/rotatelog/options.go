@@ -1,8 +1,9 @@
 package rotatelog
 
 import (
-	"logrus/hooks/rotatelog/internal/option"
 	"time"
+
+	"github.com/dorofeevsa/logrus/hooks/rotatelog/internal/option"
 )
 
 const (
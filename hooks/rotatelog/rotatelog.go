@@ -319,6 +319,19 @@ func (rl *RotateLog) rotate_nolock(filename string) error {
 	return nil
 }
 
+// Flush syncs the current file to disk, satisfying the logrus.Flusher
+// interface.
+func (rl *RotateLog) Flush() error {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	if rl.outFh == nil {
+		return nil
+	}
+
+	return rl.outFh.Sync()
+}
+
 // Close satisfies the io.Closer interface. You must
 // call this method if you performed any writes to
 // the object.
@@ -0,0 +1,192 @@
+// Package secrets is a hook detecting credentials in log entries, as a safety
+// net against secrets leaking into logs.
+package secrets
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// Mode selects what the hook does with secrets.
+type Mode int
+
+const (
+	// Mask replaces secrets in the entry, and reports them to OnSecret.
+	Mask Mode = iota
+	// Alert only reports secrets to OnSecret.
+	Alert
+)
+
+// Finding is a secret found in an entry.
+type Finding struct {
+	// Field holding the secret, empty for the message
+	Field string
+	// Kind of secret, e.g. "aws-access-key" or "high-entropy"
+	Kind string
+}
+
+// credential is a known shape of credentials.
+type credential struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+var credentials = []credential{
+	{"aws-access-key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)},
+	{"github-token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36}\b`)},
+	{"slack-token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{"private-key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+}
+
+// token splits values into candidates for the entropy check.
+var token = regexp.MustCompile(`[A-Za-z0-9+/=_\-]+`)
+
+// Hook detects credentials of known shapes, e.g. AWS access keys and JWTs,
+// and long random-looking strings in the message and fields of entries,
+// formatting values which aren't strings. It must be fired synchronously, not
+// with Logger.SetAsyncHooks, for masking to apply to the logged entry.
+type Hook struct {
+	// What to do with secrets, Mask by default
+	Mode Mode
+	// Called with the secrets found in an entry, e.g. to alert
+	OnSecret func(entry *logrus.Entry, findings []Finding)
+	// Replacement of masked secrets, "[SECRET]" by default
+	Replacement string
+	// Minimum length of strings checked for entropy, 20 by default
+	MinLength int
+	// Shannon entropy, in bits per character, above which strings are
+	// reported as secrets, 4 by default, the maximum of hex identifiers. A
+	// negative value disables the entropy check.
+	MinEntropy float64
+}
+
+// NewHook returns a hook with mode, calling onSecret, which may be nil, with
+// the secrets found:
+//
+//	log.AddHook(secrets.NewHook(secrets.Mask, func(entry *logrus.Entry, findings []secrets.Finding) {
+//		leakedSecrets.Inc()
+//	}))
+func NewHook(mode Mode, onSecret func(entry *logrus.Entry, findings []Finding)) *Hook {
+	return &Hook{Mode: mode, OnSecret: onSecret}
+}
+
+func (hook *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (hook *Hook) Close() error {
+	return nil
+}
+
+// Fire scans the entry, masking the secrets found in Mask mode.
+func (hook *Hook) Fire(entry *logrus.Entry) error {
+	var findings []Finding
+
+	msg, kinds := hook.scan(entry.Message)
+	for _, kind := range kinds {
+		findings = append(findings, Finding{Kind: kind})
+	}
+
+	var data logrus.Fields
+	for k, v := range entry.Data {
+		s, ok := v.(string)
+		if !ok {
+			s = fmt.Sprint(v)
+		}
+		masked, kinds := hook.scan(s)
+		if len(kinds) == 0 {
+			continue
+		}
+		for _, kind := range kinds {
+			findings = append(findings, Finding{Field: k, Kind: kind})
+		}
+
+		if data == nil {
+			data = make(logrus.Fields, len(entry.Data))
+			for k, v := range entry.Data {
+				data[k] = v
+			}
+		}
+		data[k] = masked
+	}
+
+	if len(findings) == 0 {
+		return nil
+	}
+	if hook.Mode == Mask {
+		entry.Message = msg
+		if data != nil {
+			entry.Data = data
+		}
+	}
+	if hook.OnSecret != nil {
+		hook.OnSecret(entry, findings)
+	}
+	return nil
+}
+
+// scan returns s with its secrets masked, and their kinds.
+func (hook *Hook) scan(s string) (string, []string) {
+	replacement := hook.Replacement
+	if replacement == "" {
+		replacement = "[SECRET]"
+	}
+
+	var kinds []string
+	for _, c := range credentials {
+		if c.re.MatchString(s) {
+			kinds = append(kinds, c.kind)
+			s = c.re.ReplaceAllLiteralString(s, replacement)
+		}
+	}
+
+	minLength, minEntropy := hook.MinLength, hook.MinEntropy
+	if minLength <= 0 {
+		minLength = 20
+	}
+	if minEntropy == 0 {
+		minEntropy = 4
+	}
+	if minEntropy > 0 {
+		highEntropy := false
+		s = token.ReplaceAllStringFunc(s, func(t string) string {
+			if len(t) < minLength || entropy(t) <= minEntropy {
+				return t
+			}
+			highEntropy = true
+			return replacement
+		})
+		if highEntropy {
+			kinds = append(kinds, "high-entropy")
+		}
+	}
+	return s, kinds
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+
+	n := float64(len(s))
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// String returns the kind and field of the finding.
+func (f Finding) String() string {
+	if f.Field == "" {
+		return f.Kind + " in message"
+	}
+	return f.Kind + " in " + f.Field
+}
@@ -0,0 +1,112 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package syslog
+
+import (
+	"fmt"
+	"os"
+)
+
+// OverflowPolicy defines what an asynchronous hook does with a message when
+// its queue is full.
+type OverflowPolicy int
+
+const (
+	// Block waits until the queue has room for the message.
+	Block OverflowPolicy = iota
+	// DropOldest drops the oldest queued message to make room.
+	DropOldest
+	// DropNew drops the message.
+	DropNew
+)
+
+type asyncQueue struct {
+	writes chan func() error
+	policy OverflowPolicy
+	done   chan struct{}
+}
+
+// SetAsync makes Fire queue the formatted messages instead of writing them,
+// so a slow syslog server can't stall the caller. The messages are written by
+// a goroutine in the order they were fired; up to size messages are queued,
+// the overflow is handled according to policy. Dropped messages are counted
+// in Stats. A size of 0 makes the hook synchronous again after writing the
+// queued messages, as does Close.
+func (hook *SyslogHook) SetAsync(size int, policy OverflowPolicy) {
+	hook.stopAsync()
+	if size <= 0 {
+		return
+	}
+
+	q := &asyncQueue{
+		writes: make(chan func() error, size),
+		policy: policy,
+		done:   make(chan struct{}),
+	}
+	go q.run()
+
+	hook.asyncLock.Lock()
+	hook.async = q
+	hook.asyncLock.Unlock()
+}
+
+// dispatch runs write, or queues it in asynchronous mode.
+func (hook *SyslogHook) dispatch(write func() error) error {
+	hook.asyncLock.RLock()
+	defer hook.asyncLock.RUnlock()
+
+	q := hook.async
+	if q == nil {
+		return write()
+	}
+
+	for {
+		switch q.policy {
+		case DropNew:
+			select {
+			case q.writes <- write:
+			default:
+				hook.countDropped()
+			}
+			return nil
+		case DropOldest:
+			select {
+			case q.writes <- write:
+				return nil
+			default:
+			}
+			select {
+			case <-q.writes:
+				hook.countDropped()
+			default:
+			}
+		default:
+			q.writes <- write
+			return nil
+		}
+	}
+}
+
+// stopAsync writes the queued messages and stops the writer goroutine.
+func (hook *SyslogHook) stopAsync() {
+	hook.asyncLock.Lock()
+	q := hook.async
+	hook.async = nil
+	hook.asyncLock.Unlock()
+
+	if q != nil {
+		close(q.writes)
+		<-q.done
+	}
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	for write := range q.writes {
+		if err := write(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write to syslog, %v\n", err)
+		}
+	}
+}
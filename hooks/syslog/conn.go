@@ -0,0 +1,65 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package syslog
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"time"
+)
+
+// localSockets are the paths the local syslog daemon listens on, in the
+// order they're probed.
+var localSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// networkTLS is the network of syslog over TLS as of RFC 5425.
+const networkTLS = "tcp+tls"
+
+// defaultDialTimeout bounds the dials of RFC 5424 and TLS hooks, including
+// the one of their constructor.
+const defaultDialTimeout = 10 * time.Second
+
+// dial connects to the syslog server at raddr, or to the local syslog daemon
+// if network and raddr are empty. config is used for the tcp+tls network.
+func dial(network, raddr string, config *tls.Config, timeout time.Duration) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	if network == "" && raddr == "" {
+		return dialLocal(dialer)
+	}
+
+	if network == networkTLS {
+		return tls.DialWithDialer(dialer, "tcp", raddr, config)
+	}
+
+	return dialer.Dial(network, raddr)
+}
+
+// dialLocal connects to the first local syslog socket that accepts a
+// connection, like log/syslog does. As dropped connections are dialed again,
+// the sockets are probed again after the daemon restarts and recreates its
+// socket, which may be of another type.
+func dialLocal(dialer *net.Dialer) (net.Conn, error) {
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range localSockets {
+			conn, err := dialer.Dial(network, path)
+			if err == nil {
+				return conn, nil
+			}
+		}
+	}
+
+	return nil, errors.New("unix syslog delivery error")
+}
+
+// isStream reports whether conn is a stream connection, whose messages need
+// to be framed.
+func isStream(conn net.Conn) bool {
+	switch conn.RemoteAddr().Network() {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,158 @@
+//go:build windows
+// +build windows
+
+package syslog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/dorofeevsa/logrus"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// Priority mirrors the priority of log/syslog, which doesn't exist on
+// Windows, so code passing it to NewHook builds on every platform.
+type Priority int
+
+const (
+	LOG_EMERG Priority = iota
+	LOG_ALERT
+	LOG_CRIT
+	LOG_ERR
+	LOG_WARNING
+	LOG_NOTICE
+	LOG_INFO
+	LOG_DEBUG
+)
+
+const (
+	LOG_KERN Priority = iota << 3
+	LOG_USER
+	LOG_MAIL
+	LOG_DAEMON
+	LOG_AUTH
+	LOG_SYSLOG
+	LOG_LPR
+	LOG_NEWS
+	LOG_UUCP
+	LOG_CRON
+	LOG_AUTHPRIV
+	LOG_FTP
+	_ // unused
+	_ // unused
+	_ // unused
+	_ // unused
+	LOG_LOCAL0
+	LOG_LOCAL1
+	LOG_LOCAL2
+	LOG_LOCAL3
+	LOG_LOCAL4
+	LOG_LOCAL5
+	LOG_LOCAL6
+	LOG_LOCAL7
+)
+
+// eventID is the ID of the events written by the hook.
+const eventID = 1
+
+// SyslogHook writes to the Windows Event Log, which takes the place of syslog
+// on Windows.
+type SyslogHook struct {
+	SyslogNetwork string
+	SyslogRaddr   string
+
+	mu        sync.Mutex
+	log       *eventlog.Log
+	formatter logrus.Formatter
+	levels    []logrus.Level
+}
+
+// NewHook creates a hook writing to the Event Log of the machine raddr, or of
+// the local machine if raddr is empty, with the tag as the event source. The
+// network and the priority are ignored, as the Event Log has no facilities.
+// The source is registered if needed, which requires administrator rights
+// the first time.
+func NewHook(network, raddr string, priority Priority, tag string) (*SyslogHook, error) {
+	if tag == "" {
+		tag = filepath.Base(os.Args[0])
+	}
+
+	var log *eventlog.Log
+	var err error
+	if raddr == "" {
+		// fails if the source exists already
+		eventlog.InstallAsEventCreate(tag, eventlog.Error|eventlog.Warning|eventlog.Info)
+		log, err = eventlog.Open(tag)
+	} else {
+		log, err = eventlog.OpenRemote(raddr, tag)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &SyslogHook{SyslogNetwork: network, SyslogRaddr: raddr, log: log}, nil
+}
+
+func (hook *SyslogHook) Fire(entry *logrus.Entry) error {
+	hook.mu.Lock()
+	formatter := hook.formatter
+	hook.mu.Unlock()
+
+	var line string
+	var err error
+	if formatter == nil {
+		line, err = entry.String()
+	} else {
+		var serialized []byte
+		serialized, err = formatter.Format(entry)
+		line = string(serialized)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read entry, %v", err)
+		return err
+	}
+
+	switch entry.Level.Builtin() {
+	case logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel:
+		return hook.log.Error(eventID, line)
+	case logrus.WarnLevel:
+		return hook.log.Warning(eventID, line)
+	default:
+		return hook.log.Info(eventID, line)
+	}
+}
+
+// SetFormatter sets the formatter of the event message. By default the
+// logger's formatter is used.
+func (hook *SyslogHook) SetFormatter(formatter logrus.Formatter) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	hook.formatter = formatter
+}
+
+// SetLevels sets the levels the hook is fired for. Call it before adding the
+// hook to a logger.
+func (hook *SyslogHook) SetLevels(levels []logrus.Level) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	hook.levels = append([]logrus.Level(nil), levels...)
+}
+
+func (hook *SyslogHook) Levels() []logrus.Level {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	if hook.levels == nil {
+		return logrus.AllLevels
+	}
+	return append([]logrus.Level(nil), hook.levels...)
+}
+
+func (hook *SyslogHook) Close() error {
+	return hook.log.Close()
+}
@@ -0,0 +1,132 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package syslog
+
+import (
+	"fmt"
+	"log/syslog"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// SetFacilityMap sets the syslog facility of levels, e.g. LOG_AUTH for
+// errors, so collectors can route them separately. Levels missing from
+// facilities use the hook's facility. The facility of SetSeverityMap takes
+// precedence.
+func (hook *SyslogHook) SetFacilityMap(facilities map[logrus.Level]syslog.Priority) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	hook.facilities = make(map[logrus.Level]syslog.Priority, len(facilities))
+	for level, facility := range facilities {
+		hook.facilities[level] = facility & facilityMask
+	}
+}
+
+// SetFacilityRouting sets the syslog facility of entries by the value of
+// field, e.g.
+//
+//	hook.SetFacilityRouting("category", map[string]syslog.Priority{"security": syslog.LOG_AUTHPRIV})
+//
+// logs every entry with `category=security` to LOG_AUTHPRIV. Routing takes
+// precedence over SetFacilityMap and SetSeverityMap. A nil routes map
+// disables routing.
+func (hook *SyslogHook) SetFacilityRouting(field string, routes map[string]syslog.Priority) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	hook.routeField = field
+	hook.routes = make(map[string]syslog.Priority, len(routes))
+	for value, facility := range routes {
+		hook.routes[value] = facility & facilityMask
+	}
+}
+
+// facilityOf returns the syslog facility of entry. A non-zero facility is the
+// one set by the severity map.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) facilityOf(entry *logrus.Entry, facility syslog.Priority) syslog.Priority {
+	if len(hook.routes) > 0 {
+		if value, ok := entry.Data[hook.routeField]; ok {
+			if routed, ok := hook.routes[fmt.Sprint(value)]; ok {
+				return routed
+			}
+		}
+	}
+
+	if facility != 0 {
+		return facility
+	}
+	if facility, ok := hook.facilities[entry.Level]; ok {
+		return facility
+	}
+	return hook.priority & facilityMask
+}
+
+// maxWriters is how many log/syslog Writers of routed facilities and rendered
+// tags are kept open besides the hook's, so tags rendered from fields with
+// many values don't leak connections.
+const maxWriters = 16
+
+// writerKey identifies a log/syslog Writer, which has a fixed facility and
+// tag.
+type writerKey struct {
+	facility syslog.Priority
+	tag      string
+}
+
+// cachedWriter is a log/syslog Writer other than the hook's.
+type cachedWriter struct {
+	*syslog.Writer
+	lastUse uint64
+}
+
+// writer returns the log/syslog Writer of facility and tag. Writers other than
+// the hook's are dialed on first use, closing the least recently used one
+// beyond maxWriters.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) writer(facility syslog.Priority, tag string) (*syslog.Writer, error) {
+	if facility == hook.priority&facilityMask && tag == hook.tag {
+		return hook.Writer, nil
+	}
+
+	hook.writerUses++
+	key := writerKey{facility, tag}
+	if w, ok := hook.writers[key]; ok {
+		w.lastUse = hook.writerUses
+		return w.Writer, nil
+	}
+
+	w, err := syslog.Dial(hook.SyslogNetwork, hook.SyslogRaddr, facility|hook.priority&severityMask, tag)
+	if err != nil {
+		return nil, err
+	}
+	if len(hook.writers) >= maxWriters {
+		hook.evictWriter()
+	}
+	if hook.writers == nil {
+		hook.writers = make(map[writerKey]*cachedWriter)
+	}
+	hook.writers[key] = &cachedWriter{Writer: w, lastUse: hook.writerUses}
+	return w, nil
+}
+
+// evictWriter closes the least recently used Writer other than the hook's.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) evictWriter() {
+	var (
+		lruKey writerKey
+		lru    *cachedWriter
+	)
+	for key, w := range hook.writers {
+		if lru == nil || w.lastUse < lru.lastUse {
+			lruKey, lru = key, w
+		}
+	}
+
+	if lru != nil {
+		lru.Close()
+		delete(hook.writers, lruKey)
+	}
+}
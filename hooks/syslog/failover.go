@@ -0,0 +1,72 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package syslog
+
+import (
+	"net"
+	"time"
+)
+
+const defaultRetryPrimary = 30 * time.Second
+
+// SetFailover sets backup servers for an RFC 5424 or TLS hook. When the
+// connection drops, the hook dials SyslogRaddr and then the backups in order,
+// using the first server that accepts the connection. While connected to a
+// backup, the hook dials SyslogRaddr again every retryPrimary, 30s if 0, and
+// returns to it once it is reachable.
+func (hook *SyslogHook) SetFailover(raddrs []string, retryPrimary time.Duration) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	hook.backups = append([]string(nil), raddrs...)
+	hook.retryPrimary = retryPrimary
+}
+
+// dialServer connects to the first reachable server, the primary one first.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) dialServer() (net.Conn, error) {
+	conn, err := hook.dial(hook.SyslogRaddr)
+	if err == nil {
+		hook.onBackup = false
+		return conn, nil
+	}
+
+	for _, raddr := range hook.backups {
+		if conn, backupErr := hook.dial(raddr); backupErr == nil {
+			hook.onBackup = true
+			hook.stats.Failovers++
+			hook.nextPrimary = time.Now().Add(hook.retryPrimaryInterval())
+			return conn, nil
+		}
+	}
+
+	return nil, err
+}
+
+// returnToPrimary switches from a backup server to the primary one when it
+// is reachable again.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) returnToPrimary() {
+	if !hook.onBackup || hook.conn == nil || time.Now().Before(hook.nextPrimary) {
+		return
+	}
+
+	conn, err := hook.dial(hook.SyslogRaddr)
+	if err != nil {
+		hook.nextPrimary = time.Now().Add(hook.retryPrimaryInterval())
+		return
+	}
+
+	hook.conn.Close()
+	hook.setConn(conn)
+	hook.onBackup = false
+}
+
+// Must be called with hook.mu held.
+func (hook *SyslogHook) retryPrimaryInterval() time.Duration {
+	if hook.retryPrimary <= 0 {
+		return defaultRetryPrimary
+	}
+	return hook.retryPrimary
+}
@@ -0,0 +1,54 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package syslog
+
+import (
+	"github.com/dorofeevsa/logrus"
+)
+
+// SetHostname overrides the HOSTNAME of RFC 5424 messages, as containers
+// often have unhelpful random hostnames. log/syslog always uses the hostname
+// of the machine.
+func (hook *SyslogHook) SetHostname(hostname string) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	hook.hostname = hostname
+}
+
+// SetMetadata sets static fields added to every message, e.g. the
+// environment, region or cluster. Fields of the entry take precedence.
+func (hook *SyslogHook) SetMetadata(fields logrus.Fields) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	hook.metadata = make(logrus.Fields, len(fields))
+	for k, v := range fields {
+		hook.metadata[k] = v
+	}
+}
+
+// withMetadata returns a copy of entry with the metadata added, leaving entry
+// untouched for other hooks.
+func (hook *SyslogHook) withMetadata(entry *logrus.Entry) *logrus.Entry {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	if len(hook.metadata) == 0 {
+		return entry
+	}
+
+	data := make(logrus.Fields, len(hook.metadata)+len(entry.Data))
+	for k, v := range hook.metadata {
+		data[k] = v
+	}
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+
+	e := *entry
+	e.Data = data
+	e.Buffer = nil
+	return &e
+}
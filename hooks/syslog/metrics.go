@@ -0,0 +1,38 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package syslog
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+)
+
+// MetricsHandler returns an http.Handler serving the counters of Stats in the
+// Prometheus text format, so Prometheus can scrape them without a client
+// library:
+//
+//	http.Handle("/metrics/syslog", hook.MetricsHandler())
+func (hook *SyslogHook) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		stats := hook.Stats()
+		b := bufio.NewWriter(w)
+		for _, metric := range []struct {
+			name, help string
+			value      uint64
+		}{
+			{"syslog_hook_sent_total", "Messages written to the connection.", stats.Sent},
+			{"syslog_hook_failed_total", "Failed writes and dials.", stats.Failed},
+			{"syslog_hook_dropped_total", "Messages dropped because a buffer or queue was full.", stats.Dropped},
+			{"syslog_hook_reconnects_total", "Times the connection was dialed again.", stats.Reconnects},
+			{"syslog_hook_failovers_total", "Times a backup server was connected to.", stats.Failovers},
+			{"syslog_hook_suppressed_total", "Messages suppressed by rate limits.", stats.Suppressed},
+		} {
+			fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", metric.name, metric.help, metric.name, metric.name, metric.value)
+		}
+		b.Flush()
+	})
+}
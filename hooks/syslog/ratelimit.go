@@ -0,0 +1,110 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package syslog
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// tokenBucket allows rate messages per second on average, and bursts of up
+// to burst messages.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// refill adds the tokens accumulated since the last refill and reports
+// whether a token is available.
+func (b *tokenBucket) refill(now time.Time) bool {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	return b.tokens >= 1
+}
+
+// SetRateLimit limits the messages of all levels to rate per second, allowing
+// bursts of up to burst messages. Messages over the limit are suppressed, and
+// the next message that is written is preceded by a warning with the number
+// of messages suppressed. A rate of 0 disables the limit.
+func (hook *SyslogHook) SetRateLimit(rate float64, burst int) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	hook.limit = nil
+	if rate > 0 {
+		hook.limit = newTokenBucket(rate, burst)
+	}
+}
+
+// SetLevelRateLimit limits the messages of level like SetRateLimit, e.g. to
+// keep debug messages from using up the global limit. Messages must be within
+// both limits.
+func (hook *SyslogHook) SetLevelRateLimit(level logrus.Level, rate float64, burst int) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	if rate <= 0 {
+		delete(hook.limits, level)
+		return
+	}
+
+	if hook.limits == nil {
+		hook.limits = make(map[logrus.Level]*tokenBucket)
+	}
+	hook.limits[level] = newTokenBucket(rate, burst)
+}
+
+// allow reports whether a message of level is within the rate limits, and
+// if so returns the number of messages suppressed since the last one.
+func (hook *SyslogHook) allow(level logrus.Level) (uint64, bool) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	now := time.Now()
+	levelLimit := hook.limits[level]
+	if hook.limit != nil && !hook.limit.refill(now) || levelLimit != nil && !levelLimit.refill(now) {
+		hook.suppressed++
+		hook.stats.Suppressed++
+		return 0, false
+	}
+
+	if hook.limit != nil {
+		hook.limit.tokens--
+	}
+	if levelLimit != nil {
+		levelLimit.tokens--
+	}
+
+	suppressed := hook.suppressed
+	hook.suppressed = 0
+	return suppressed, true
+}
+
+// suppressedEntry returns the warning about n suppressed messages written
+// before entry.
+func suppressedEntry(entry *logrus.Entry, n uint64) *logrus.Entry {
+	return &logrus.Entry{
+		Logger:  entry.Logger,
+		Data:    logrus.Fields{},
+		Time:    entry.Time,
+		Level:   logrus.WarnLevel,
+		Message: fmt.Sprintf("%d messages suppressed by the syslog rate limit", n),
+	}
+}
@@ -0,0 +1,176 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package syslog
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	defaultMinBackoff      = 100 * time.Millisecond
+	defaultMaxBackoff      = 30 * time.Second
+	defaultRetryBufferSize = 1000
+
+	// maxWriteAttempts is how many times writing a queued message may fail
+	// before it is dropped, as the message itself may be the cause, e.g. a
+	// datagram too large for the network.
+	maxWriteAttempts = 3
+)
+
+// errBackoff is returned for messages not written because the connection is
+// down and the backoff delay hasn't passed.
+var errBackoff = errors.New("syslog: connection is down, waiting to dial again")
+
+// SetReconnectBackoff sets the delays between attempts to dial a dropped
+// connection again. The first attempt is made right away, then the delay
+// doubles from min up to max. The defaults are 100ms and 30s.
+// Hooks created with NewHook drop messages until the delay passed.
+func (hook *SyslogHook) SetReconnectBackoff(min, max time.Duration) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	hook.minBackoff = min
+	hook.maxBackoff = max
+}
+
+// SetRetryBufferSize sets how many messages are kept while the connection is
+// down, 1000 unless changed. When the buffer is full, the oldest message is
+// dropped.
+func (hook *SyslogHook) SetRetryBufferSize(size int) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	hook.retryBufferSize = size
+	hook.trimRetries()
+}
+
+// write queues msg and writes every queued message to the connection. It
+// returns the error of the last failed write or dial, the message staying
+// queued unless it was dropped.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) write(msg []byte) error {
+	hook.retries = append(hook.retries, msg)
+	hook.trimRetries()
+	return hook.drain()
+}
+
+// drain writes the queued messages to the connection, dialing it again with
+// exponential backoff when it was dropped. Messages that can't be written
+// stay queued for the next write, unless writing them failed
+// maxWriteAttempts times or the socket keeps running out of buffer space, see
+// retryTransient. It returns the error of the last message it failed to
+// write, if any.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) drain() error {
+	hook.returnToPrimary()
+
+	var writeErr error
+	for len(hook.retries) > 0 {
+		if hook.conn == nil {
+			if err := hook.redial(); err != nil {
+				if writeErr != nil {
+					return writeErr
+				}
+				return err
+			}
+		}
+
+		msg := hook.frame(hook.retries[0])
+		err := retryTransient(func() error {
+			if hook.writeTimeout > 0 {
+				hook.conn.SetWriteDeadline(time.Now().Add(hook.writeTimeout))
+			}
+			_, err := hook.conn.Write(msg)
+			return err
+		})
+		writeErr = err
+		switch {
+		case isTransient(err):
+			// the connection is fine, but the message can't be sent now
+			writeErr = nil
+			hook.stats.Dropped++
+			hook.failures = 0
+		case err != nil:
+			hook.conn.Close()
+			hook.conn = nil
+			hook.stats.Failed++
+			hook.failed()
+			if hook.attempts++; hook.attempts < maxWriteAttempts {
+				continue
+			}
+			hook.stats.Dropped++
+		default:
+			hook.stats.Sent++
+			hook.failures = 0
+		}
+
+		hook.retries[0] = nil
+		hook.retries = hook.retries[1:]
+		hook.attempts = 0
+	}
+	return writeErr
+}
+
+// redial dials the connection again unless the backoff delay hasn't passed,
+// returning errBackoff then.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) redial() error {
+	if time.Now().Before(hook.nextDial) {
+		return errBackoff
+	}
+
+	conn, err := hook.dialServer()
+	if err != nil {
+		hook.stats.Failed++
+		hook.failed()
+		return err
+	}
+
+	hook.setConn(conn)
+	hook.stats.Reconnects++
+	return nil
+}
+
+// failed schedules the next dial after a failed write or dial.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) failed() {
+	hook.failures++
+	if hook.failures == 1 {
+		// retry right away, the connection may just have been reset
+		hook.nextDial = time.Time{}
+		return
+	}
+
+	min, max := hook.minBackoff, hook.maxBackoff
+	if min <= 0 {
+		min = defaultMinBackoff
+	}
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+
+	backoff := min
+	for i := 2; i < hook.failures && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	hook.nextDial = time.Now().Add(backoff)
+}
+
+// trimRetries drops the oldest queued messages beyond the retry buffer size.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) trimRetries() {
+	size := hook.retryBufferSize
+	if size <= 0 {
+		size = defaultRetryBufferSize
+	}
+
+	if n := len(hook.retries) - size; n > 0 {
+		hook.stats.Dropped += uint64(n)
+		hook.retries = append(hook.retries[:0:0], hook.retries[n:]...)
+	}
+}
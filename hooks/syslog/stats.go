@@ -0,0 +1,38 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package syslog
+
+// Stats holds the delivery counters of a hook, so a silently failing syslog
+// server gets noticed. Export them to your metrics system, or let Prometheus
+// scrape them, see MetricsHandler.
+type Stats struct {
+	// Sent is the number of messages written to the connection.
+	Sent uint64
+	// Failed is the number of failed writes and dials.
+	Failed uint64
+	// Dropped is the number of messages dropped because the retry buffer,
+	// the asynchronous queue or the socket buffer was full, or because they
+	// kept failing to be written or the connection was backing off.
+	Dropped uint64
+	// Reconnects is the number of times the connection was dialed again.
+	Reconnects uint64
+	// Failovers is the number of times a backup server was connected to.
+	Failovers uint64
+	// Suppressed is the number of messages suppressed by rate limits.
+	Suppressed uint64
+}
+
+// Stats returns a snapshot of the delivery counters.
+func (hook *SyslogHook) Stats() Stats {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	return hook.stats
+}
+
+func (hook *SyslogHook) countDropped() {
+	hook.mu.Lock()
+	hook.stats.Dropped++
+	hook.mu.Unlock()
+}
@@ -4,57 +4,346 @@
 package syslog
 
 import (
+	"crypto/tls"
 	"fmt"
 	"log/syslog"
+	"net"
 	"os"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/dorofeevsa/logrus"
 )
 
+const (
+	severityMask = 0x07
+	facilityMask = 0xf8
+)
+
 // SyslogHook to send logs via syslog.
 type SyslogHook struct {
 	Writer        *syslog.Writer
 	SyslogNetwork string
 	SyslogRaddr   string
+
+	mu         sync.Mutex
+	asyncLock  sync.RWMutex
+	async      *asyncQueue
+	rfc5424    bool
+	conn       net.Conn
+	stream     bool
+	tlsConfig  *tls.Config
+	priority   syslog.Priority
+	tag        string
+	hostname   string
+	msgIDField string
+	sdID       string
+	tagTmpl    *template.Template
+	formatter  logrus.Formatter
+	levels     []logrus.Level
+	metadata   logrus.Fields
+	limit      *tokenBucket
+	limits     map[logrus.Level]*tokenBucket
+	suppressed uint64
+	severities map[logrus.Level]syslog.Priority
+	facilities map[logrus.Level]syslog.Priority
+	routeField string
+	routes     map[string]syslog.Priority
+	writers    map[writerKey]*cachedWriter
+	writerUses uint64
+
+	maxMessageSize int
+
+	octetCounting bool
+
+	retries         [][]byte
+	retryBufferSize int
+	failures        int
+	attempts        int
+	nextDial        time.Time
+	minBackoff      time.Duration
+	maxBackoff      time.Duration
+	backups         []string
+	onBackup        bool
+	retryPrimary    time.Duration
+	dialTimeout     time.Duration
+	writeTimeout    time.Duration
+	nextPrimary     time.Time
+	stats           Stats
 }
 
 // Creates a hook to be added to an instance of logger. This is called with
 // `hook, err := NewSyslogHook("udp", "localhost:514", syslog.LOG_DEBUG, "")`
 // `if err == nil { log.Hooks.Add(hook) }`
+// The "tcp+tls" network sends RFC 5424 messages over TLS, see NewTLSHook.
 func NewHook(network, raddr string, priority syslog.Priority, tag string) (*SyslogHook, error) {
+	if network == networkTLS {
+		return NewTLSHook(raddr, nil, priority, tag)
+	}
+
 	w, err := syslog.Dial(network, raddr, priority, tag)
-	return &SyslogHook{w, network, raddr}, err
+	return &SyslogHook{Writer: w, SyslogNetwork: network, SyslogRaddr: raddr, priority: priority, tag: tag}, err
 }
 
 func (hook *SyslogHook) Fire(entry *logrus.Entry) error {
-	line, err := entry.String()
+	if !hook.fires(entry.Level) {
+		return nil
+	}
+
+	suppressed, ok := hook.allow(entry.Level)
+	if !ok {
+		return nil
+	}
+	if suppressed > 0 {
+		hook.fire(suppressedEntry(entry, suppressed))
+	}
+
+	return hook.fire(entry)
+}
+
+// fire writes entry to syslog.
+func (hook *SyslogHook) fire(entry *logrus.Entry) error {
+	entry = hook.withMetadata(entry)
+	line, err := hook.formatEntry(entry)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Unable to read entry, %v", err)
 		return err
 	}
 
-	switch entry.Level {
-	case logrus.PanicLevel:
-		return hook.Writer.Crit(line)
-	case logrus.FatalLevel:
-		return hook.Writer.Crit(line)
+	var write func() error
+	if hook.rfc5424 {
+		msg := hook.format(entry, line)
+		write = func() error { return hook.send(msg) }
+	} else {
+		hook.mu.Lock()
+		priority := hook.priorityOf(entry)
+		tag := hook.tagOf(entry)
+		line = truncate(line, hook.maxSize())
+		hook.mu.Unlock()
+		write = func() error { return hook.writePriority(priority, tag, line) }
+	}
+
+	return hook.dispatch(write)
+}
+
+// writePriority writes line with the log/syslog Writer of the facility of
+// priority and tag at its severity. hook.mu is held while writing, so the
+// Writer isn't closed meanwhile, see writer. log/syslog dials the connection
+// again when a write fails, so after consecutive failures lines are dropped
+// until the backoff delay passed, like dials of RFC 5424 hooks.
+func (hook *SyslogHook) writePriority(priority syslog.Priority, tag string, line string) error {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	if time.Now().Before(hook.nextDial) {
+		hook.stats.Dropped++
+		return errBackoff
+	}
+
+	w, err := hook.writer(priority&facilityMask, tag)
+	if err != nil {
+		hook.stats.Failed++
+		hook.failed()
+		return err
+	}
+
+	err = retryTransient(func() error { return writeSeverity(w, priority, line) })
+	switch {
+	case isTransient(err):
+		hook.stats.Dropped++
+		return nil
+	case err != nil:
+		hook.stats.Failed++
+		hook.failed()
+	default:
+		hook.stats.Sent++
+		hook.failures = 0
+	}
+	return err
+}
+
+// writeSeverity writes line with w at the severity of priority.
+func writeSeverity(w *syslog.Writer, priority syslog.Priority, line string) error {
+	switch priority & severityMask {
+	case syslog.LOG_EMERG:
+		return w.Emerg(line)
+	case syslog.LOG_ALERT:
+		return w.Alert(line)
+	case syslog.LOG_CRIT:
+		return w.Crit(line)
+	case syslog.LOG_ERR:
+		return w.Err(line)
+	case syslog.LOG_WARNING:
+		return w.Warning(line)
+	case syslog.LOG_NOTICE:
+		return w.Notice(line)
+	case syslog.LOG_INFO:
+		return w.Info(line)
+	default:
+		return w.Debug(line)
+	}
+}
+
+// SetFormatter sets the formatter of the message body, e.g. a JSONFormatter
+// for collectors parsing JSON messages. By default the logger's formatter is
+// used.
+func (hook *SyslogHook) SetFormatter(formatter logrus.Formatter) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	hook.formatter = formatter
+}
+
+// formatEntry formats entry with the hook's formatter.
+func (hook *SyslogHook) formatEntry(entry *logrus.Entry) (string, error) {
+	hook.mu.Lock()
+	formatter := hook.formatter
+	hook.mu.Unlock()
+
+	if formatter == nil {
+		return entry.String()
+	}
+
+	serialized, err := formatter.Format(entry)
+	if err != nil {
+		return "", err
+	}
+	return string(serialized), nil
+}
+
+// format formats an RFC 5424 message for entry.
+func (hook *SyslogHook) format(entry *logrus.Entry, line string) []byte {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	return []byte(truncate(hook.formatRFC5424(hook.priorityOf(entry), entry, line), hook.maxSize()))
+}
+
+// frame frames msg for the hook's connection.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) frame(msg []byte) []byte {
+	if !hook.stream {
+		return msg
+	}
+
+	if hook.octetCounting {
+		return append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+	return append(msg[:len(msg):len(msg)], '\n')
+}
+
+// setConn makes conn the hook's connection.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) setConn(conn net.Conn) {
+	hook.conn = conn
+	hook.stream = isStream(conn)
+}
+
+// send writes a message to the hook's connection, see write.
+func (hook *SyslogHook) send(msg []byte) error {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	return hook.write(msg)
+}
+
+// SetSeverityMap overrides the syslog severity of levels, e.g. to log Warn
+// entries as LOG_NOTICE. A priority including a facility, e.g.
+// LOG_LOCAL1|LOG_DEBUG, also overrides the facility, see SetFacilityMap.
+// Levels missing from severities keep their default severity.
+func (hook *SyslogHook) SetSeverityMap(severities map[logrus.Level]syslog.Priority) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	hook.severities = make(map[logrus.Level]syslog.Priority, len(severities))
+	for level, priority := range severities {
+		hook.severities[level] = priority
+	}
+}
+
+// priorityOf returns the syslog priority of entry.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) priorityOf(entry *logrus.Entry) syslog.Priority {
+	priority, ok := hook.severities[entry.Level]
+	if !ok {
+		priority = severity(entry.Level)
+	}
+
+	return hook.facilityOf(entry, priority&facilityMask) | priority&severityMask
+}
+
+// severity returns the default syslog severity of level, the one of its
+// built-in level for custom levels.
+func severity(level logrus.Level) syslog.Priority {
+	switch level.Builtin() {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return syslog.LOG_CRIT
 	case logrus.ErrorLevel:
-		return hook.Writer.Err(line)
+		return syslog.LOG_ERR
 	case logrus.WarnLevel:
-		return hook.Writer.Warning(line)
+		return syslog.LOG_WARNING
 	case logrus.InfoLevel:
-		return hook.Writer.Info(line)
-	case logrus.DebugLevel:
-		return hook.Writer.Debug(line)
+		return syslog.LOG_INFO
 	default:
-		return nil
+		return syslog.LOG_DEBUG
 	}
 }
 
+// SetLevels sets the levels the hook is fired for, e.g.
+//
+//	hook.SetLevels(logrus.AllLevels[:logrus.WarnLevel+1])
+//
+// ships warnings and above only. As loggers ask hooks for their levels when
+// they're added, entries of levels dropped afterwards are ignored by Fire.
+func (hook *SyslogHook) SetLevels(levels []logrus.Level) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	hook.levels = append([]logrus.Level(nil), levels...)
+}
+
 func (hook *SyslogHook) Levels() []logrus.Level {
-	return logrus.AllLevels
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	if hook.levels == nil {
+		return logrus.AllLevels
+	}
+	return append([]logrus.Level(nil), hook.levels...)
+}
+
+// fires reports whether the hook is fired for level.
+func (hook *SyslogHook) fires(level logrus.Level) bool {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	if hook.levels == nil {
+		return true
+	}
+	for _, l := range hook.levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
 }
 
 func (hook *SyslogHook) Close() error {
+	hook.stopAsync()
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	// last attempt to deliver queued messages
+	hook.drain()
+	for key, w := range hook.writers {
+		w.Close()
+		delete(hook.writers, key)
+	}
+	if hook.conn != nil {
+		return hook.conn.Close()
+	}
 	return nil
 }
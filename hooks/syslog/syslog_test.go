@@ -1,8 +1,25 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
 package syslog
 
 import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"log/syslog"
+	"net"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/dorofeevsa/logrus"
 )
@@ -25,3 +42,1157 @@ func TestLocalhostAddAndPrint(t *testing.T) {
 
 	log.Info("Congratulations!")
 }
+
+func TestRFC5424(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook, err := NewRFC5424Hook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	hook.SetMsgIDField("event")
+	log.Hooks.Add(hook)
+
+	log.WithField("event", "login").Warn("Congratulations!")
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+
+	// LOG_LOCAL0 | LOG_WARNING
+	prefix := "<132>1 "
+	if !strings.HasPrefix(msg, prefix) {
+		t.Errorf("Message %q doesn't start with %q", msg, prefix)
+	}
+	header := strings.SplitN(msg, " ", 8)
+	if len(header) != 8 {
+		t.Fatalf("Message %q has no RFC 5424 header", msg)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, header[1]); err != nil {
+		t.Errorf("Invalid timestamp %q: %v", header[1], err)
+	}
+	if header[3] != "app" || header[4] != strconv.Itoa(os.Getpid()) || header[5] != "login" || header[6] != "-" {
+		t.Errorf("Unexpected header fields %q", header[3:7])
+	}
+	if !strings.Contains(header[7], "Congratulations!") {
+		t.Errorf("Message %q doesn't contain the entry", msg)
+	}
+}
+
+func TestTLS(t *testing.T) {
+	// borrow the certificate of httptest, which is valid for example.com
+	ts := httptest.NewUnstartedServer(nil)
+	ts.StartTLS()
+	cert := ts.TLS.Certificates[0]
+	roots := x509.NewCertPool()
+	roots.AddCert(ts.Certificate())
+	ts.Close()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				r := bufio.NewReader(conn)
+				var n int
+				if _, err := fmt.Fscanf(r, "%d ", &n); err != nil {
+					return
+				}
+				buf := make([]byte, n)
+				io.ReadFull(r, buf)
+				received <- string(buf)
+			}()
+		}
+	}()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook, err := NewTLSHook(ln.Addr().String(), &tls.Config{RootCAs: roots, ServerName: "example.com"}, syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	log.Hooks.Add(hook)
+
+	log.Info("Congratulations!")
+
+	select {
+	case msg := <-received:
+		if !strings.HasPrefix(msg, "<134>1 ") || !strings.Contains(msg, "Congratulations!") {
+			t.Errorf("Unexpected message %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("No message received")
+	}
+
+	if _, err := NewTLSHook(ln.Addr().String(), &tls.Config{RootCAs: roots, ServerName: "example.org"}, syslog.LOG_LOCAL0, "app"); err == nil {
+		t.Error("Expected the server name to be verified")
+	}
+}
+
+// listenLines accepts connections on ln and sends the lines received.
+func listenLines(ln net.Listener, received chan<- string) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer conn.Close()
+
+			s := bufio.NewScanner(conn)
+			for s.Scan() {
+				received <- s.Text()
+			}
+		}()
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+
+	hook, err := NewRFC5424Hook("tcp", addr, syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	hook.SetReconnectBackoff(time.Millisecond, 10*time.Millisecond)
+
+	// the collector restarts
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	ln.Close()
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string, 100)
+	go listenLines(ln, received)
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+
+	// writes to the dropped connection may succeed until it is reset
+	deadline := time.After(5 * time.Second)
+	for {
+		log.Info("Congratulations!")
+
+		select {
+		case msg := <-received:
+			if !strings.Contains(msg, "Congratulations!") {
+				t.Errorf("Unexpected message %q", msg)
+			}
+			if stats := hook.Stats(); stats.Reconnects != 1 {
+				t.Errorf("Expected 1 reconnect, got %d", stats.Reconnects)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("No message received")
+		}
+	}
+}
+
+func TestRetryBufferSize(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hook, err := NewRFC5424Hook("tcp", ln.Addr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	ln.Close()
+
+	// drop the connection, the collector is gone
+	hook.mu.Lock()
+	hook.conn.Close()
+	hook.conn = nil
+	hook.mu.Unlock()
+
+	hook.SetRetryBufferSize(2)
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+
+	for i := 0; i < 5; i++ {
+		log.Info("Congratulations!")
+	}
+
+	if stats := hook.Stats(); stats.Dropped != 3 || stats.Sent != 0 || stats.Failed != 2 {
+		t.Errorf("Expected 3 dropped messages and 2 failed dials before backing off, got %+v", stats)
+	}
+}
+
+func TestWriteAttempts(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	hook, err := NewRFC5424Hook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	hook.SetReconnectBackoff(time.Millisecond, time.Millisecond)
+
+	// too large for a datagram, writing it fails every time
+	if err := hook.send(make([]byte, 70000)); err == nil {
+		t.Error("Expected the error of the write")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for hook.send([]byte("Congratulations!")) != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("The message queued after the failing one was never sent")
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg := string(buf[:n]); msg != "Congratulations!" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+	if stats := hook.Stats(); stats.Dropped != 1 || stats.Failed != maxWriteAttempts {
+		t.Errorf("Expected the failing message to be dropped after %d attempts, got %+v", maxWriteAttempts, stats)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hook, err := NewHook("tcp", ln.Addr().String(), syslog.LOG_LOCAL0|syslog.LOG_INFO, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	hook.SetReconnectBackoff(time.Hour, time.Hour)
+
+	// the collector is gone
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	ln.Close()
+
+	// writes to the dropped connection may succeed until it is reset
+	deadline := time.Now().Add(5 * time.Second)
+	for hook.writePriority(syslog.LOG_LOCAL0|syslog.LOG_INFO, "app", "Congratulations!") != errBackoff {
+		if time.Now().After(deadline) {
+			t.Fatal("The hook never backed off")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if stats := hook.Stats(); stats.Failed != 2 || stats.Dropped != 1 {
+		t.Errorf("Expected 2 failed writes before backing off, got %+v", stats)
+	}
+}
+
+// stalledConn is a connection whose writes block until unblock is closed.
+type stalledConn struct {
+	net.Conn
+	unblock chan struct{}
+	written chan []byte
+}
+
+func (c *stalledConn) Write(b []byte) (int, error) {
+	<-c.unblock
+	c.written <- b
+	return len(b), nil
+}
+
+func TestAsync(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	for _, policy := range []OverflowPolicy{DropNew, DropOldest} {
+		hook, err := NewRFC5424Hook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0, "app")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn := &stalledConn{Conn: hook.conn, unblock: make(chan struct{}), written: make(chan []byte, 10)}
+		hook.conn = conn
+		hook.SetAsync(2, policy)
+
+		log := logrus.New()
+		log.Out = ioutil.Discard
+		log.Hooks.Add(hook)
+
+		// the first message may be taken by the stalled writer
+		for i := 0; i < 5; i++ {
+			log.Info(i)
+		}
+		close(conn.unblock)
+		hook.Close()
+		close(conn.written)
+
+		var last string
+		for msg := range conn.written {
+			last = string(msg)
+		}
+		if stats := hook.Stats(); stats.Dropped < 2 {
+			t.Errorf("Expected at least 2 dropped messages, got %d", stats.Dropped)
+		}
+		if policy == DropOldest && !strings.Contains(last, "msg=4") {
+			t.Errorf("Expected the last message to be written, got %q", last)
+		}
+		if policy == DropNew && strings.Contains(last, "msg=4") {
+			t.Errorf("Expected the last message to be dropped, got %q", last)
+		}
+	}
+}
+
+func TestSeverityMap(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Level = logrus.DebugLevel
+
+	hook, err := NewRFC5424Hook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	hook.SetSeverityMap(map[logrus.Level]syslog.Priority{
+		logrus.WarnLevel:  syslog.LOG_NOTICE,
+		logrus.DebugLevel: syslog.LOG_LOCAL1 | syslog.LOG_DEBUG,
+	})
+	log.Hooks.Add(hook)
+
+	log.Warn("Congratulations!")
+	log.Debug("Congratulations!")
+	log.Error("Congratulations!")
+
+	buf := make([]byte, 2048)
+	// LOG_LOCAL0 | LOG_NOTICE, LOG_LOCAL1 | LOG_DEBUG, LOG_LOCAL0 | LOG_ERR
+	for _, prefix := range []string{"<133>1 ", "<143>1 ", "<131>1 "} {
+		pc.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg := string(buf[:n]); !strings.HasPrefix(msg, prefix) {
+			t.Errorf("Message %q doesn't start with %q", msg, prefix)
+		}
+	}
+}
+
+func TestCustomLevelSeverity(t *testing.T) {
+	noticeLevel := logrus.MustRegisterLevel("notice", logrus.WarnLevel)
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Level = logrus.DebugLevel
+
+	hook, err := NewRFC5424Hook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	log.Hooks.Add(hook)
+
+	log.Log(noticeLevel, "Congratulations!")
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// LOG_LOCAL0 | LOG_WARNING, the severity of WarnLevel
+	if msg := string(buf[:n]); !strings.HasPrefix(msg, "<132>1 ") {
+		t.Errorf("Message %q doesn't start with %q", msg, "<132>1 ")
+	}
+}
+
+func TestFacilityRouting(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	rfc5424, err := NewRFC5424Hook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rfc5424.Close()
+	legacy, err := NewHook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0|syslog.LOG_INFO, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer legacy.Close()
+
+	for _, hook := range []*SyslogHook{rfc5424, legacy} {
+		hook.SetFacilityMap(map[logrus.Level]syslog.Priority{logrus.ErrorLevel: syslog.LOG_LOCAL1})
+		hook.SetFacilityRouting("category", map[string]syslog.Priority{"security": syslog.LOG_AUTHPRIV})
+
+		log := logrus.New()
+		log.Out = ioutil.Discard
+		log.Hooks.Add(hook)
+
+		log.WithField("category", "security").Warn("Congratulations!")
+		log.Error("Congratulations!")
+		log.Info("Congratulations!")
+
+		buf := make([]byte, 2048)
+		// LOG_AUTHPRIV | LOG_WARNING, LOG_LOCAL1 | LOG_ERR, LOG_LOCAL0 | LOG_INFO
+		for _, prefix := range []string{"<84>", "<139>", "<134>"} {
+			pc.SetReadDeadline(time.Now().Add(time.Second))
+			n, _, err := pc.ReadFrom(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg := string(buf[:n]); !strings.HasPrefix(msg, prefix) {
+				t.Errorf("Message %q doesn't start with %q", msg, prefix)
+			}
+		}
+	}
+}
+
+func TestMaxMessageSize(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook, err := NewRFC5424Hook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	log.Hooks.Add(hook)
+
+	long := strings.Repeat("x", 3000)
+	buf := make([]byte, 8192)
+	for _, size := range []int{0, 100, -1} {
+		hook.SetMaxMessageSize(size)
+		log.Info(long)
+
+		pc.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := string(buf[:n])
+
+		switch size {
+		case 0:
+			size = 2048
+		case -1:
+			if !strings.Contains(msg, long) {
+				t.Errorf("Expected the message not to be truncated, got %d bytes", n)
+			}
+			continue
+		}
+		if n != size || !strings.HasSuffix(msg, "...[truncated]") {
+			t.Errorf("Expected a message of %d bytes ending with the truncation marker, got %q", size, msg)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, test := range []struct {
+		msg  string
+		max  int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 0, "hello"},
+		{"hello world, hello world", 20, "hello ...[truncated]"},
+		{"héllo world, hello world", 16, "h...[truncated]"},
+		{"hello world", 5, "...[t"},
+	} {
+		if got := truncate(test.msg, test.max); got != test.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", test.msg, test.max, got, test.want)
+		}
+	}
+}
+
+// messageFormatter formats the message only.
+type messageFormatter struct{}
+
+func (f *messageFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	return []byte(entry.Message + "\n"), nil
+}
+
+func TestOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	hook, err := NewRFC5424Hook("tcp", ln.Addr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Formatter = &messageFormatter{}
+	log.Hooks.Add(hook)
+
+	log.Info("first\nsecond")
+	hook.SetOctetCounting(true)
+	log.Info("first\nsecond")
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(line, "first\n") {
+		t.Errorf("Expected a newline terminated message, got %q", line)
+	}
+	if _, err := r.ReadString('\n'); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	if _, err := fmt.Fscanf(r, "%d ", &n); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	if msg := string(buf); !strings.HasPrefix(msg, "<134>1 ") || !strings.Contains(msg, "first\nsecond") {
+		t.Errorf("Unexpected message %q", msg)
+	}
+}
+
+func TestTagTemplate(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	rfc5424, err := NewRFC5424Hook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rfc5424.Close()
+	legacy, err := NewHook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0|syslog.LOG_INFO, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer legacy.Close()
+
+	if err := rfc5424.SetTagTemplate("{{.Data.service"); err == nil {
+		t.Error("Expected an invalid template to fail")
+	}
+
+	for _, hook := range []*SyslogHook{rfc5424, legacy} {
+		if err := hook.SetTagTemplate("{{.Data.service}}"); err != nil {
+			t.Fatal(err)
+		}
+
+		log := logrus.New()
+		log.Out = ioutil.Discard
+		log.Hooks.Add(hook)
+
+		log.WithField("service", "billing").Info("Congratulations!")
+		log.Info("Congratulations!")
+
+		buf := make([]byte, 2048)
+		for _, tag := range []string{"billing", "app"} {
+			pc.SetReadDeadline(time.Now().Add(time.Second))
+			n, _, err := pc.ReadFrom(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := string(buf[:n])
+			if hook == rfc5424 && !strings.Contains(msg, " "+tag+" "+strconv.Itoa(os.Getpid())+" ") ||
+				hook == legacy && !strings.Contains(msg, " "+tag+"[") {
+				t.Errorf("Message %q isn't tagged %q", msg, tag)
+			}
+		}
+	}
+}
+
+func TestTagTemplateWriters(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	hook, err := NewHook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0|syslog.LOG_INFO, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	if err := hook.SetTagTemplate("{{.Data.request}}"); err != nil {
+		t.Fatal(err)
+	}
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+
+	for i := 0; i < 3*maxWriters; i++ {
+		log.WithField("request", i).Info("Congratulations!")
+	}
+
+	if n := len(hook.writers); n != maxWriters {
+		t.Errorf("Expected %d writers, got %d", maxWriters, n)
+	}
+	if _, ok := hook.writers[writerKey{syslog.LOG_LOCAL0, strconv.Itoa(3*maxWriters - 1)}]; !ok {
+		t.Error("Expected the writer of the last tag to be kept")
+	}
+	if stats := hook.Stats(); stats.Sent != 3*maxWriters {
+		t.Errorf("Expected %d sent messages, got %+v", 3*maxWriters, stats)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	primary, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := primary.Addr().String()
+	backup, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backup.Close()
+	backupReceived := make(chan string, 100)
+	go listenLines(backup, backupReceived)
+
+	hook, err := NewRFC5424Hook("tcp", addr, syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	hook.SetReconnectBackoff(time.Millisecond, 10*time.Millisecond)
+	hook.SetFailover([]string{backup.Addr().String()}, 10*time.Millisecond)
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+
+	// logs until a message is received on received
+	logUntil := func(received chan string) {
+		deadline := time.After(5 * time.Second)
+		for {
+			log.Info("Congratulations!")
+
+			select {
+			case <-received:
+				return
+			case <-time.After(10 * time.Millisecond):
+			case <-deadline:
+				t.Fatal("No message received")
+			}
+		}
+	}
+
+	// the primary server goes down
+	conn, err := primary.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	primary.Close()
+	logUntil(backupReceived)
+
+	if stats := hook.Stats(); stats.Failovers != 1 {
+		t.Errorf("Expected 1 failover, got %d", stats.Failovers)
+	}
+
+	// and comes back
+	primary, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer primary.Close()
+	primaryReceived := make(chan string, 100)
+	go listenLines(primary, primaryReceived)
+	logUntil(primaryReceived)
+}
+
+func TestLocalSocketReprobe(t *testing.T) {
+	dir, err := ioutil.TempDir("", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := dir + "/log"
+	defer func(sockets []string) { localSockets = sockets }(localSockets)
+	localSockets = []string{dir + "/missing", path}
+
+	pc, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hook, err := NewRFC5424Hook("", "", syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	hook.SetReconnectBackoff(time.Millisecond, 10*time.Millisecond)
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+
+	// the daemon restarts with a stream socket
+	pc.Close()
+	os.Remove(path)
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string, 100)
+	go listenLines(ln, received)
+
+	deadline := time.After(5 * time.Second)
+	for {
+		log.Info("Congratulations!")
+
+		select {
+		case msg := <-received:
+			if !strings.HasPrefix(msg, "<134>1 ") {
+				t.Errorf("Unexpected message %q", msg)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("No message received")
+		}
+	}
+}
+
+func TestFormatter(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook, err := NewRFC5424Hook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	hook.SetFormatter(&logrus.JSONFormatter{})
+	log.Hooks.Add(hook)
+
+	log.WithField("user", "alice").Info("Congratulations!")
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := strings.SplitN(string(buf[:n]), " ", 8)
+	if len(header) != 8 {
+		t.Fatalf("Message %q has no RFC 5424 header", buf[:n])
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(header[7]), &fields); err != nil {
+		t.Fatalf("Message body %q isn't JSON: %v", header[7], err)
+	}
+	if fields["user"] != "alice" || fields["msg"] != "Congratulations!" {
+		t.Errorf("Unexpected message body %q", header[7])
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Level = logrus.DebugLevel
+
+	hook, err := NewRFC5424Hook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	hook.SetRateLimit(10, 3)
+	hook.SetLevelRateLimit(logrus.DebugLevel, 0.001, 1)
+	log.Hooks.Add(hook)
+
+	log.Debug("first")
+	log.Debug("second")
+	log.Info("third")
+	log.Info("fourth")
+	log.Info("fifth")
+	time.Sleep(150 * time.Millisecond)
+	log.Info("sixth")
+
+	buf := make([]byte, 2048)
+	for _, want := range []string{"first", "1 messages suppressed", "third", "fourth", "1 messages suppressed", "sixth"} {
+		pc.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg := string(buf[:n]); !strings.Contains(msg, want) {
+			t.Errorf("Expected message %q to contain %q", msg, want)
+		}
+	}
+
+	if stats := hook.Stats(); stats.Suppressed != 2 {
+		t.Errorf("Expected 2 suppressed messages, got %d", stats.Suppressed)
+	}
+}
+
+func TestSetLevels(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Level = logrus.DebugLevel
+
+	hook, err := NewRFC5424Hook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	hook.SetLevels(logrus.AllLevels[:logrus.WarnLevel+1])
+	log.Hooks.Add(hook)
+
+	if len(log.Hooks[logrus.InfoLevel]) != 0 || len(log.Hooks[logrus.WarnLevel]) != 1 {
+		t.Errorf("Expected the hook to be added for warnings and above only")
+	}
+
+	log.Debug("debug")
+	log.Info("info")
+	log.Warn("warning")
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg := string(buf[:n]); !strings.Contains(msg, "warning") {
+		t.Errorf("Expected the warning only, got %q", msg)
+	}
+
+	// levels dropped after adding the hook are ignored
+	hook.SetLevels([]logrus.Level{logrus.ErrorLevel})
+	log.Warn("warning")
+	log.Error("error")
+
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err = pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg := string(buf[:n]); !strings.Contains(msg, "error") {
+		t.Errorf("Expected the error only, got %q", msg)
+	}
+}
+
+func TestStructuredData(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook, err := NewRFC5424Hook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	hook.SetStructuredData("fields@32473")
+	log.Hooks.Add(hook)
+
+	log.WithFields(logrus.Fields{"user": 42, "req id": `a"b]c\`}).Info("Congratulations!")
+	log.Info("Congratulations!")
+
+	buf := make([]byte, 2048)
+	for _, want := range []string{
+		` - [fields@32473 req_id="a\"b\]c\\" user="42"] `,
+		` - - `,
+	} {
+		pc.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg := string(buf[:n]); !strings.Contains(msg, want) {
+			t.Errorf("Expected message %q to contain %q", msg, want)
+		}
+	}
+}
+
+// busyConn is a connection failing the first busy writes with ENOBUFS.
+type busyConn struct {
+	net.Conn
+	busy    int
+	written [][]byte
+}
+
+func (c *busyConn) Write(b []byte) (int, error) {
+	if c.busy > 0 {
+		c.busy--
+		return 0, &net.OpError{Op: "write", Net: "unixgram", Err: os.NewSyscallError("sendto", syscall.ENOBUFS)}
+	}
+
+	c.written = append(c.written, b)
+	return len(b), nil
+}
+
+func TestTransientWriteErrors(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	hook, err := NewRFC5424Hook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	conn := &busyConn{Conn: hook.conn, busy: 2}
+	hook.conn = conn
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+
+	log.Info("first")
+	conn.busy = 100
+	log.Info("second")
+	conn.busy = 0
+	log.Info("third")
+
+	if len(conn.written) != 2 || !strings.Contains(string(conn.written[0]), "first") || !strings.Contains(string(conn.written[1]), "third") {
+		t.Errorf("Expected the first and third messages to be written, got %q", conn.written)
+	}
+	if stats := hook.Stats(); stats.Sent != 2 || stats.Dropped != 1 || stats.Reconnects != 0 {
+		t.Errorf("Expected 1 dropped message and no reconnects, got %+v", stats)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	hook, err := NewRFC5424Hook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	hook.SetHostname("web-1")
+	hook.SetMetadata(logrus.Fields{"env": "prod", "region": "eu"})
+	hook.SetStructuredData("fields@32473")
+	log.Hooks.Add(hook)
+
+	entry := log.WithField("region", "us")
+	entry.Info("Congratulations!")
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+
+	if header := strings.SplitN(msg, " ", 4); len(header) != 4 || header[2] != "web-1" {
+		t.Errorf("Message %q doesn't have the hostname web-1", msg)
+	}
+	if !strings.Contains(msg, `[fields@32473 env="prod" region="us"]`) {
+		t.Errorf("Message %q doesn't have the metadata", msg)
+	}
+	if len(entry.Data) != 1 {
+		t.Errorf("Expected the entry to be untouched, got %v", entry.Data)
+	}
+}
+
+func TestWriteTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	hook, err := NewRFC5424Hook("tcp", ln.Addr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	if err := hook.SetTimeouts(time.Second, 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	hook.SetMaxMessageSize(-1)
+
+	// the collector accepts the connection, but never reads
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+
+	long := strings.Repeat("x", 1<<20)
+	deadline := time.Now().Add(5 * time.Second)
+	for hook.Stats().Failed == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected a write to time out")
+		}
+		log.Info(long)
+	}
+}
+
+func TestTimeoutsUnsupported(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	hook, err := NewHook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0|syslog.LOG_INFO, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	if err := hook.SetTimeouts(time.Second, time.Second); err == nil {
+		t.Error("Expected timeouts of a log/syslog hook to fail")
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	hook, err := NewRFC5424Hook("udp", pc.LocalAddr().String(), syslog.LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+	log.Info("Congratulations!")
+
+	rec := httptest.NewRecorder()
+	hook.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE syslog_hook_sent_total counter",
+		"syslog_hook_sent_total 1",
+		"syslog_hook_failed_total 0",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Expected the metrics to contain %q, got %q", line, body)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package syslog
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// SetTagTemplate derives the tag, the APP-NAME of RFC 5424 messages, from a
+// text/template executed with the entry, e.g. `{{.Data.service}}`, so a
+// process logging on behalf of several components tags them correctly.
+// Entries missing a field of the template, or rendering an empty tag, use the
+// tag passed to the constructor. An empty text disables the template.
+// Hooks created with NewHook keep a connection for each of the last 16
+// rendered tags and routed facilities.
+func (hook *SyslogHook) SetTagTemplate(text string) error {
+	var tmpl *template.Template
+	if text != "" {
+		var err error
+		tmpl, err = template.New("tag").Option("missingkey=error").Parse(text)
+		if err != nil {
+			return err
+		}
+	}
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	hook.tagTmpl = tmpl
+	return nil
+}
+
+// tagOf returns the tag of entry.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) tagOf(entry *logrus.Entry) string {
+	if hook.tagTmpl == nil {
+		return hook.tag
+	}
+
+	var b strings.Builder
+	if err := hook.tagTmpl.Execute(&b, entry); err != nil || b.Len() == 0 {
+		return hook.tag
+	}
+	return b.String()
+}
@@ -0,0 +1,45 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package syslog
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// errTimeouts is returned by SetTimeouts for hooks using log/syslog.
+var errTimeouts = errors.New("syslog: timeouts are supported by RFC 5424 and TLS hooks only")
+
+// SetTimeouts sets the timeouts of dials and writes of RFC 5424 and TLS
+// hooks, so a blackholed collector fails quickly instead of hanging Fire.
+// A write timing out drops the connection, which is dialed again as usual.
+// Dials time out after 10s unless set, writes don't time out unless set. A
+// zero timeout keeps the default, a negative one disables it. It fails for
+// hooks created with NewHook, as log/syslog doesn't support timeouts.
+func (hook *SyslogHook) SetTimeouts(dial, write time.Duration) error {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	if !hook.rfc5424 {
+		return errTimeouts
+	}
+	hook.dialTimeout = dial
+	hook.writeTimeout = write
+	return nil
+}
+
+// dial connects to the syslog server at raddr.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) dial(raddr string) (net.Conn, error) {
+	timeout := hook.dialTimeout
+	switch {
+	case timeout == 0:
+		timeout = defaultDialTimeout
+	case timeout < 0:
+		timeout = 0
+	}
+
+	return dial(hook.SyslogNetwork, raddr, hook.tlsConfig, timeout)
+}
@@ -0,0 +1,32 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package syslog
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+const (
+	transientRetries = 3
+	transientBackoff = time.Millisecond
+)
+
+// isTransient reports whether err is a temporary lack of socket buffer space,
+// which sendto reports for local datagram sockets under burst load.
+func isTransient(err error) bool {
+	return errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.ENOBUFS)
+}
+
+// retryTransient calls write until it succeeds or fails with an error that
+// isn't transient, retrying a few times with short sleeps.
+func retryTransient(write func() error) error {
+	err := write()
+	for i := 0; i < transientRetries && isTransient(err); i++ {
+		time.Sleep(transientBackoff << uint(i))
+		err = write()
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package syslog
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// defaultMaxDatagramSize is the message size RFC 5426 receivers must
+	// accept, larger UDP datagrams are dropped by many of them.
+	defaultMaxDatagramSize = 2048
+	defaultMaxMessageSize  = 8192
+
+	truncationMarker = "...[truncated]"
+)
+
+// SetMaxMessageSize sets the maximum size of messages in bytes, longer
+// messages are truncated and end with "...[truncated]". For RFC 5424 and TLS
+// hooks the size includes the header, for NewHook hooks it only covers the
+// entry as log/syslog adds the header. The default is 2048 bytes over UDP and
+// 8192 bytes otherwise, a negative size disables truncation.
+func (hook *SyslogHook) SetMaxMessageSize(size int) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	hook.maxMessageSize = size
+}
+
+// maxSize returns the maximum size of messages, or 0 if it is unlimited.
+// Must be called with hook.mu held.
+func (hook *SyslogHook) maxSize() int {
+	switch {
+	case hook.maxMessageSize < 0:
+		return 0
+	case hook.maxMessageSize > 0:
+		return hook.maxMessageSize
+	case strings.HasPrefix(hook.SyslogNetwork, "udp"):
+		return defaultMaxDatagramSize
+	default:
+		return defaultMaxMessageSize
+	}
+}
+
+// truncate cuts msg to at most max bytes including the truncation marker,
+// without splitting UTF-8 characters.
+func truncate(msg string, max int) string {
+	if max <= 0 || len(msg) <= max {
+		return msg
+	}
+	if max <= len(truncationMarker) {
+		return truncationMarker[:max]
+	}
+
+	n := max - len(truncationMarker)
+	for n > 0 && !utf8.RuneStart(msg[n]) {
+		n--
+	}
+	return msg[:n] + truncationMarker
+}
@@ -0,0 +1,61 @@
+package test
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// Matcher reports whether an entry matches, see Hook.Find and
+// Hook.WaitForEntry.
+type Matcher func(*logrus.Entry) bool
+
+// MatchLevel matches the entries logged at level.
+func MatchLevel(level logrus.Level) Matcher {
+	return func(entry *logrus.Entry) bool {
+		return entry.Level == level
+	}
+}
+
+// MatchMessage matches the entries with the message msg.
+func MatchMessage(msg string) Matcher {
+	return func(entry *logrus.Entry) bool {
+		return entry.Message == msg
+	}
+}
+
+// MatchMessageContains matches the entries with a message containing substr.
+func MatchMessageContains(substr string) Matcher {
+	return func(entry *logrus.Entry) bool {
+		return strings.Contains(entry.Message, substr)
+	}
+}
+
+// MatchField matches the entries with the field key equal to value.
+func MatchField(key string, value interface{}) Matcher {
+	return func(entry *logrus.Entry) bool {
+		v, ok := entry.Data[key]
+		return ok && reflect.DeepEqual(v, value)
+	}
+}
+
+// MatchHasField matches the entries with the field key.
+func MatchHasField(key string) Matcher {
+	return func(entry *logrus.Entry) bool {
+		_, ok := entry.Data[key]
+		return ok
+	}
+}
+
+// MatchAll matches the entries matching all the matchers.
+func MatchAll(matchers ...Matcher) Matcher {
+	return func(entry *logrus.Entry) bool {
+		for _, matcher := range matchers {
+			if !matcher(entry) {
+				return false
+			}
+		}
+		return true
+	}
+}
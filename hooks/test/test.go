@@ -4,6 +4,7 @@
 package test
 
 import (
+	"context"
 	"io/ioutil"
 	"sync"
 
@@ -17,6 +18,8 @@ type Hook struct {
 	// value directly.
 	Entries []*logrus.Entry
 	mu      sync.RWMutex
+	// closed when an entry is fired, see WaitForEntry
+	fired chan struct{}
 }
 
 // NewGlobal installs a test hook for the global logger.
@@ -53,6 +56,10 @@ func (t *Hook) Fire(e *logrus.Entry) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.Entries = append(t.Entries, e)
+	if t.fired != nil {
+		close(t.fired)
+		t.fired = nil
+	}
 	return nil
 }
 
@@ -97,3 +104,63 @@ func (t *Hook) Reset() {
 	defer t.mu.Unlock()
 	t.Entries = make([]*logrus.Entry, 0)
 }
+
+// Find returns the entries logged which match matcher.
+func (t *Hook) Find(matcher Matcher) []*logrus.Entry {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var entries []*logrus.Entry
+	for _, entry := range t.Entries {
+		if matcher(entry) {
+			e := *entry
+			entries = append(entries, &e)
+		}
+	}
+	return entries
+}
+
+// Count returns the number of entries logged at level.
+func (t *Hook) Count(level logrus.Level) int {
+	return len(t.Find(MatchLevel(level)))
+}
+
+// Counts returns the number of entries logged at each level.
+func (t *Hook) Counts() map[logrus.Level]int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	counts := make(map[logrus.Level]int)
+	for _, entry := range t.Entries {
+		counts[entry.Level]++
+	}
+	return counts
+}
+
+// WaitForEntry returns the first entry matching matcher, waiting until one is
+// logged, e.g. by a goroutine or an async hook, or until ctx is done:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+//	defer cancel()
+//	entry, err := hook.WaitForEntry(ctx, test.MatchMessage("connected"))
+func (t *Hook) WaitForEntry(ctx context.Context, matcher Matcher) (*logrus.Entry, error) {
+	for {
+		t.mu.Lock()
+		for _, entry := range t.Entries {
+			if matcher(entry) {
+				e := *entry
+				t.mu.Unlock()
+				return &e, nil
+			}
+		}
+		if t.fired == nil {
+			t.fired = make(chan struct{})
+		}
+		fired := t.fired
+		t.mu.Unlock()
+
+		select {
+		case <-fired:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
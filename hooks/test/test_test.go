@@ -1,8 +1,10 @@
 package test
 
 import (
+	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/dorofeevsa/logrus"
 	"github.com/stretchr/testify/assert"
@@ -59,3 +61,55 @@ func TestLoggingWithHooksRace(t *testing.T) {
 	entries := hook.AllEntries()
 	assert.Equal(100, len(entries))
 }
+
+func TestFindAndCount(t *testing.T) {
+	assert := assert.New(t)
+	logger, hook := NewNullLogger()
+
+	logger.WithField("user", "alice").Info("logged in")
+	logger.WithField("user", "bob").Info("logged in")
+	logger.WithField("user", "bob").Warn("failed to log in")
+
+	entries := hook.Find(MatchAll(MatchLevel(logrus.InfoLevel), MatchField("user", "bob")))
+	assert.Equal(1, len(entries))
+	assert.Equal("logged in", entries[0].Message)
+	assert.Equal(1, len(hook.Find(MatchMessageContains("failed"))))
+	assert.Equal(3, len(hook.Find(MatchHasField("user"))))
+	assert.Empty(hook.Find(MatchMessage("logged out")))
+
+	assert.Equal(2, hook.Count(logrus.InfoLevel))
+	assert.Equal(0, hook.Count(logrus.ErrorLevel))
+	assert.Equal(map[logrus.Level]int{logrus.InfoLevel: 2, logrus.WarnLevel: 1}, hook.Counts())
+}
+
+func TestWaitForEntry(t *testing.T) {
+	assert := assert.New(t)
+	logger, hook := NewNullLogger()
+	logger.SetAsyncHooks(10)
+	defer logger.SetAsyncHooks(0)
+
+	logger.Info("before")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	entry, err := hook.WaitForEntry(ctx, MatchMessage("before"))
+	assert.NoError(err)
+	assert.Equal("before", entry.Message)
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			logger.WithField("i", i).Info("loop")
+		}
+	}()
+	entry, err = hook.WaitForEntry(ctx, MatchField("i", 2))
+	assert.NoError(err)
+	assert.Equal("loop", entry.Message)
+}
+
+func TestWaitForEntryTimeout(t *testing.T) {
+	_, hook := NewNullLogger()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := hook.WaitForEntry(ctx, MatchMessage("never"))
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
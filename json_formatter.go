@@ -1,8 +1,11 @@
 package logrus
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 )
 
 type fieldKey string
@@ -25,11 +28,25 @@ func (f FieldMap) resolve(key fieldKey) string {
 	return string(key)
 }
 
+// DottedKeyConflict tells JSONFormatter how to nest a dotted key whose parent
+// key holds a value other than an object, e.g. "a.b" when "a" is 1.
+type DottedKeyConflict int
+
+const (
+	// DottedKeyKeepFlat logs the dotted key as is, next to the parent key.
+	DottedKeyKeepFlat DottedKeyConflict = iota
+	// DottedKeyReplace replaces the value of the parent key by an object.
+	DottedKeyReplace
+)
+
 // JSONFormatter formats logs into parsable json
 type JSONFormatter struct {
 	// TimestampFormat sets the format used for marshaling timestamps.
 	TimestampFormat string
 
+	// TimestampMode allows logging timestamps as epoch numbers instead.
+	TimestampMode TimestampMode
+
 	// DisableTimestamp allows disabling automatic timestamps in output
 	DisableTimestamp bool
 
@@ -43,6 +60,31 @@ type JSONFormatter struct {
 	//    },
 	// }
 	FieldMap FieldMap
+
+	// DataKey allows users to put all the log entry parameters into a nested
+	// dictionary at a given key.
+	DataKey string
+
+	// PrettyPrint will indent all json logs
+	PrettyPrint bool
+
+	// Indent sets the indentation of pretty printed logs, two spaces by
+	// default.
+	Indent string
+
+	// NestDottedKeys logs fields with dotted keys as nested objects, e.g.
+	// "http.method" as {"http":{"method":...}}.
+	NestDottedKeys bool
+
+	// DottedKeyConflict sets how conflicting dotted keys are nested.
+	DottedKeyConflict DottedKeyConflict
+
+	// SortingFunc replaces the alphabetical sorting of the keys.
+	SortingFunc func([]string)
+
+	// PriorityKeys are logged before the other keys, in their order, e.g.
+	// []string{"time", "level", "msg", "request_id"}.
+	PriorityKeys []string
 }
 
 // Format renders a single log entry
@@ -58,7 +100,16 @@ func (f *JSONFormatter) Format(entry *Entry) ([]byte, error) {
 			data[k] = v
 		}
 	}
-	prefixFieldClashes(data, f.FieldMap)
+
+	// the code is a dedicated field, see WithCode
+	code, hasCode := data[FieldKeyCode]
+	if hasCode {
+		delete(data, FieldKeyCode)
+	}
+	if f.DataKey != "" {
+		data = Fields{f.DataKey: data}
+	}
+	prefixFieldClashes(data, f.FieldMap, entry.HasCaller())
 
 	timestampFormat := f.TimestampFormat
 	if timestampFormat == "" {
@@ -66,14 +117,124 @@ func (f *JSONFormatter) Format(entry *Entry) ([]byte, error) {
 	}
 
 	if !f.DisableTimestamp {
-		data[f.FieldMap.resolve(FieldKeyTime)] = entry.Time.Format(timestampFormat)
+		data[f.FieldMap.resolve(FieldKeyTime)] = f.TimestampMode.timestamp(entry.Time, timestampFormat)
 	}
 	data[f.FieldMap.resolve(FieldKeyMsg)] = entry.Message
 	data[f.FieldMap.resolve(FieldKeyLevel)] = entry.Level.String()
+	if hasCode {
+		data[f.FieldMap.resolve(FieldKeyCode)] = code
+	}
+	if entry.HasCaller() {
+		function, file := entry.callerInfo()
+		data[f.FieldMap.resolve(FieldKeyFunc)] = function
+		data[f.FieldMap.resolve(FieldKeyFile)] = file
+	}
+
+	var m map[string]interface{} = data
+	if f.NestDottedKeys {
+		m = nestDottedKeys(data, f.DottedKeyConflict)
+	}
+
+	// encoding/json sorts the keys of maps, so keys are sorted by default
+	var v interface{} = m
+	if f.SortingFunc != nil || len(f.PriorityKeys) > 0 {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sortKeys(keys, f.PriorityKeys, f.SortingFunc)
+		v = orderedObject{keys: keys, values: m}
+	}
 
-	serialized, err := json.Marshal(data)
-	if err != nil {
+	b := entryBuffer(entry)
+	encoder := json.NewEncoder(b)
+	if f.PrettyPrint {
+		indent := f.Indent
+		if indent == "" {
+			indent = "  "
+		}
+		encoder.SetIndent("", indent)
+	}
+	if err := encoder.Encode(v); err != nil {
 		return nil, fmt.Errorf("Failed to marshal fields to JSON, %v", err)
 	}
-	return append(serialized, '\n'), nil
+	return b.Bytes(), nil
+}
+
+// orderedObject is a JSON object marshaled with its keys in order.
+type orderedObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(o.values[k])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// nestDottedKeys returns data with the fields of dotted keys moved into nested
+// objects. Keys are nested in sorted order, so conflicts are resolved the
+// same way for every entry.
+func nestDottedKeys(data Fields, conflict DottedKeyConflict) map[string]interface{} {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	nested := make(map[string]interface{}, len(data))
+	for _, k := range keys {
+		path := strings.Split(k, ".")
+		if !nestPath(nested, path, data[k], conflict) {
+			nested[k] = data[k]
+		}
+	}
+	return nested
+}
+
+// nestPath sets the value at path in m, creating objects on the way. It
+// reports false if a value on the way is kept by the conflict policy.
+func nestPath(m map[string]interface{}, path []string, value interface{}, conflict DottedKeyConflict) bool {
+	for _, p := range path {
+		if p == "" {
+			return false
+		}
+	}
+
+	for _, p := range path[:len(path)-1] {
+		child, ok := m[p].(map[string]interface{})
+		if !ok {
+			if _, exists := m[p]; exists && conflict == DottedKeyKeepFlat {
+				return false
+			}
+			child = make(map[string]interface{})
+			m[p] = child
+		}
+		m = child
+	}
+
+	leaf := path[len(path)-1]
+	if _, exists := m[leaf]; exists && conflict == DottedKeyKeepFlat {
+		return false
+	}
+	m[leaf] = value
+	return true
 }
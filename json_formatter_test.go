@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestErrorNotLost(t *testing.T) {
@@ -252,3 +253,136 @@ func TestJSONEnableTimestamp(t *testing.T) {
 		t.Error("Timestamp not present", s)
 	}
 }
+
+func TestJSONPrettyPrint(t *testing.T) {
+	formatter := &JSONFormatter{DisableTimestamp: true, PrettyPrint: true, Indent: "\t"}
+
+	entry := WithField("b", 1)
+	entry.Level = InfoLevel
+	entry.Message = "hi"
+
+	b, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+	expected := "{\n\t\"b\": 1,\n\t\"level\": \"info\",\n\t\"msg\": \"hi\"\n}\n"
+	if string(b) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(b))
+	}
+}
+
+func TestJSONPrettyPrintDefaultIndent(t *testing.T) {
+	formatter := &JSONFormatter{DisableTimestamp: true, PrettyPrint: true}
+
+	b, err := formatter.Format(WithField("b", 1))
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+	if !strings.Contains(string(b), "\n  \"b\": 1,\n") {
+		t.Error("Did not indent with two spaces", string(b))
+	}
+}
+
+func TestJSONNestDottedKeys(t *testing.T) {
+	formatter := &JSONFormatter{DisableTimestamp: true, NestDottedKeys: true}
+
+	b, err := formatter.Format(WithFields(Fields{
+		"http.request.method": "GET",
+		"http.request.path":   "/",
+		"level":               1,
+		"plain":               true,
+	}))
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	expected := `{"fields":{"level":1},"http":{"request":{"method":"GET","path":"/"}},"level":"panic","msg":"","plain":true}` + "\n"
+	if string(b) != expected {
+		t.Errorf("Expected %s, got %s", expected, string(b))
+	}
+}
+
+func TestJSONDottedKeyConflict(t *testing.T) {
+	entry := WithFields(Fields{"a": 1, "a.b": 2, "c..d": 3})
+
+	for conflict, expected := range map[DottedKeyConflict]string{
+		DottedKeyKeepFlat: `{"a":1,"a.b":2,"c..d":3}`,
+		DottedKeyReplace:  `{"a":{"b":2},"c..d":3}`,
+	} {
+		formatter := &JSONFormatter{
+			DisableTimestamp:  true,
+			NestDottedKeys:    true,
+			DottedKeyConflict: conflict,
+			FieldMap:          FieldMap{FieldKeyMsg: "m.x", FieldKeyLevel: "m.y"},
+		}
+
+		b, err := formatter.Format(entry)
+		if err != nil {
+			t.Fatal("Unable to format entry: ", err)
+		}
+		expected = strings.TrimSuffix(expected, "}") + `,"m":{"x":"","y":"panic"}}` + "\n"
+		if string(b) != expected {
+			t.Errorf("Expected %s, got %s", expected, string(b))
+		}
+	}
+}
+
+func TestJSONTimestampMode(t *testing.T) {
+	entry := WithField("test", "test")
+	entry.Time = time.Unix(1514862245, 123456789).UTC()
+
+	for _, test := range []struct {
+		formatter *JSONFormatter
+		expected  string
+	}{
+		{&JSONFormatter{TimestampMode: TimestampEpochMillis}, `"time":1514862245123`},
+		{&JSONFormatter{TimestampFormat: RFC3339Milli}, `"time":"2018-01-02T03:04:05.123Z"`},
+	} {
+		b, err := test.formatter.Format(entry)
+		if err != nil {
+			t.Fatal("Unable to format entry: ", err)
+		}
+		if !strings.Contains(string(b), test.expected) {
+			t.Errorf("Expected %s to contain %s", string(b), test.expected)
+		}
+	}
+}
+
+func TestJSONDataKey(t *testing.T) {
+	formatter := &JSONFormatter{DisableTimestamp: true, DataKey: "fields"}
+
+	b, err := formatter.Format(WithFields(Fields{"msg": "user", "level": 1}))
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	expected := `{"fields":{"level":1,"msg":"user"},"level":"panic","msg":""}` + "\n"
+	if string(b) != expected {
+		t.Errorf("Expected %s, got %s", expected, string(b))
+	}
+}
+
+func TestJSONPriorityKeys(t *testing.T) {
+	formatter := &JSONFormatter{DisableTimestamp: true, PriorityKeys: []string{"msg", "request_id"}}
+
+	b, err := formatter.Format(WithFields(Fields{"a": 1, "request_id": "x"}))
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	expected := `{"msg":"","request_id":"x","a":1,"level":"panic"}` + "\n"
+	if string(b) != expected {
+		t.Errorf("Expected %s, got %s", expected, string(b))
+	}
+
+	formatter.PrettyPrint = true
+	b, err = formatter.Format(WithFields(Fields{"a": 1}))
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	expected = "{\n  \"msg\": \"\",\n  \"a\": 1,\n  \"level\": \"panic\"\n}\n"
+	if string(b) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(b))
+	}
+}
@@ -0,0 +1,132 @@
+package logrus
+
+// Lazy is a field value computed only when an entry with it is logged, e.g.
+//
+//	log.WithField("state", logrus.Lazy(func() interface{} {
+//		return dump(state)
+//	})).Debug("state changed")
+//
+// doesn't dump the state unless debug entries are logged.
+type Lazy func() interface{}
+
+// resolveLazy returns data with the values of Lazy fields computed.
+func resolveLazy(data Fields) Fields {
+	var resolved Fields
+	for k, v := range data {
+		lazy, ok := v.(Lazy)
+		if !ok {
+			continue
+		}
+		if resolved == nil {
+			resolved = make(Fields, len(data))
+			for k, v := range data {
+				resolved[k] = v
+			}
+		}
+		resolved[k] = lazy()
+	}
+
+	if resolved == nil {
+		return data
+	}
+	return resolved
+}
+
+// Entry Fn family functions, calling fn for the message only if the level is
+// enabled
+
+func (entry *Entry) DebugFn(fn func() string) {
+	if entry.enabled(DebugLevel) {
+		entry.Debug(fn())
+	}
+}
+
+func (entry *Entry) InfoFn(fn func() string) {
+	if entry.enabled(InfoLevel) {
+		entry.Info(fn())
+	}
+}
+
+func (entry *Entry) PrintFn(fn func() string) {
+	entry.InfoFn(fn)
+}
+
+func (entry *Entry) WarnFn(fn func() string) {
+	if entry.enabled(WarnLevel) {
+		entry.Warn(fn())
+	}
+}
+
+func (entry *Entry) WarningFn(fn func() string) {
+	entry.WarnFn(fn)
+}
+
+func (entry *Entry) ErrorFn(fn func() string) {
+	if entry.enabled(ErrorLevel) {
+		entry.Error(fn())
+	}
+}
+
+func (entry *Entry) FatalFn(fn func() string) {
+	entry.fatal(fn)
+}
+
+func (entry *Entry) PanicFn(fn func() string) {
+	entry.Panic(fn())
+}
+
+func (logger *Logger) DebugFn(fn func() string) {
+	if logger.IsLevelEnabled(DebugLevel) {
+		entry := logger.newEntry()
+		entry.DebugFn(fn)
+		logger.releaseEntry(entry)
+	}
+}
+
+func (logger *Logger) InfoFn(fn func() string) {
+	if logger.IsLevelEnabled(InfoLevel) {
+		entry := logger.newEntry()
+		entry.InfoFn(fn)
+		logger.releaseEntry(entry)
+	}
+}
+
+func (logger *Logger) PrintFn(fn func() string) {
+	logger.InfoFn(fn)
+}
+
+func (logger *Logger) WarnFn(fn func() string) {
+	if logger.IsLevelEnabled(WarnLevel) {
+		entry := logger.newEntry()
+		entry.WarnFn(fn)
+		logger.releaseEntry(entry)
+	}
+}
+
+func (logger *Logger) WarningFn(fn func() string) {
+	if logger.IsLevelEnabled(WarnLevel) {
+		entry := logger.newEntry()
+		entry.WarnFn(fn)
+		logger.releaseEntry(entry)
+	}
+}
+
+func (logger *Logger) ErrorFn(fn func() string) {
+	if logger.IsLevelEnabled(ErrorLevel) {
+		entry := logger.newEntry()
+		entry.ErrorFn(fn)
+		logger.releaseEntry(entry)
+	}
+}
+
+func (logger *Logger) FatalFn(fn func() string) {
+	entry := logger.newEntry()
+	entry.FatalFn(fn)
+	logger.releaseEntry(entry)
+}
+
+func (logger *Logger) PanicFn(fn func() string) {
+	entry := logger.newEntry()
+	entry.PanicFn(fn)
+	logger.releaseEntry(entry)
+}
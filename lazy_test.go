@@ -0,0 +1,49 @@
+package logrus
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazyField(t *testing.T) {
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.WithField("state", Lazy(func() interface{} { return "dumped" })).Info("test")
+	}, func(fields Fields) {
+		assert.Equal(t, "dumped", fields["state"])
+	})
+}
+
+func TestLazyFieldNotEvaluatedWhenDisabled(t *testing.T) {
+	log := New()
+	log.Out = &bytes.Buffer{}
+
+	log.WithField("state", Lazy(func() interface{} {
+		t.Error("lazy field evaluated for a disabled level")
+		return nil
+	})).Debug("test")
+}
+
+func TestFn(t *testing.T) {
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.WithField("a", 1).WarnFn(func() string { return "computed" })
+	}, func(fields Fields) {
+		assert.Equal(t, "computed", fields["msg"])
+		assert.Equal(t, "warning", fields["level"])
+	})
+}
+
+func TestFnNotCalledWhenDisabled(t *testing.T) {
+	log := New()
+	log.Out = &bytes.Buffer{}
+
+	log.DebugFn(func() string {
+		t.Error("message computed for a disabled level")
+		return ""
+	})
+	log.WithField("a", 1).DebugFn(func() string {
+		t.Error("message computed for a disabled level")
+		return ""
+	})
+}
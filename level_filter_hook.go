@@ -0,0 +1,40 @@
+package logrus
+
+import "sync/atomic"
+
+// LevelFilterHook fires a hook only for entries at least as severe as its
+// level, so e.g. one hook receives warnings and above only while others
+// receive everything. The level can be changed at runtime, see SetLevel and
+// LevelHandler.
+type LevelFilterHook struct {
+	Hook
+	level uint32
+}
+
+// NewLevelFilterHook returns a hook firing hook for entries of its levels
+// at least as severe as level:
+//
+//	log.AddHook(logrus.NewLevelFilterHook(hook, logrus.WarnLevel))
+func NewLevelFilterHook(hook Hook, level Level) *LevelFilterHook {
+	return &LevelFilterHook{Hook: hook, level: uint32(level)}
+}
+
+// Fire fires the hook if the entry is at least as severe as the level.
+func (hook *LevelFilterHook) Fire(entry *Entry) error {
+	if entry.Level.rank() > hook.Level().rank() {
+		return nil
+	}
+	return hook.Hook.Fire(entry)
+}
+
+// Level returns the least severe level the hook is fired for.
+func (hook *LevelFilterHook) Level() Level {
+	return Level(atomic.LoadUint32(&hook.level))
+}
+
+// SetLevel sets the least severe level the hook is fired for. As the hook is
+// added to loggers for the levels of the wrapped hook, it can't be fired for
+// other levels.
+func (hook *LevelFilterHook) SetLevel(level Level) {
+	atomic.StoreUint32(&hook.level, uint32(level))
+}
@@ -0,0 +1,29 @@
+package logrus
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelFilterHook(t *testing.T) {
+	log := New()
+	log.Out = &bytes.Buffer{}
+	log.SetLevel(DebugLevel)
+
+	inner := new(TestHook)
+	hook := NewLevelFilterHook(inner, WarnLevel)
+	log.AddHook(hook)
+
+	log.Info("test")
+	assert.False(t, inner.Fired)
+
+	log.Warn("test")
+	assert.True(t, inner.Fired)
+
+	inner.Fired = false
+	hook.SetLevel(DebugLevel)
+	log.Debug("test")
+	assert.True(t, inner.Fired)
+}
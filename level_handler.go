@@ -0,0 +1,120 @@
+package logrus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// levelPayload is the JSON body of the level handler.
+type levelPayload struct {
+	Level string            `json:"level,omitempty"`
+	Hooks map[string]string `json:"hooks,omitempty"`
+}
+
+// LevelHandler is an http.Handler for changing the level of a logger, and
+// optionally of LevelFilterHooks, at runtime.
+type LevelHandler struct {
+	logger *Logger
+
+	mu    sync.Mutex
+	hooks map[string]*LevelFilterHook
+}
+
+// NewLevelHandler returns an http.Handler for changing the level of logger at
+// runtime, e.g. to debug a single instance without a restart. GET requests
+// return the level as JSON, `{"level":"info"}`, PUT requests with such a body
+// set it and return the new level:
+//
+//	http.Handle("/log/level", logrus.NewLevelHandler(logrus.StandardLogger()))
+//
+// Protect it like other administrative endpoints.
+func NewLevelHandler(logger *Logger) *LevelHandler {
+	return &LevelHandler{logger: logger, hooks: map[string]*LevelFilterHook{}}
+}
+
+// AddHook makes the level of hook available by name, in the "hooks" object of
+// the JSON body, e.g. `{"level":"info","hooks":{"syslog":"warning"}}`.
+func (h *LevelHandler) AddHook(name string, hook *LevelFilterHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.hooks[name] = hook
+}
+
+func (h *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		if err := h.setLevels(r); err != nil {
+			writeLevelError(w, http.StatusBadRequest, err)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeLevelError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	payload := levelPayload{Level: h.logger.level().String()}
+	if len(h.hooks) > 0 {
+		payload.Hooks = make(map[string]string, len(h.hooks))
+		for name, hook := range h.hooks {
+			payload.Hooks[name] = hook.Level().String()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payload)
+}
+
+// setLevels sets the levels of the body of r, or none if any is invalid.
+// Must be called with h.mu held.
+func (h *LevelHandler) setLevels(r *http.Request) error {
+	var payload levelPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		return fmt.Errorf("invalid request body, %v", err)
+	}
+	if payload.Level == "" && len(payload.Hooks) == 0 {
+		return fmt.Errorf("no level given")
+	}
+
+	var level Level
+	if payload.Level != "" {
+		var err error
+		if level, err = ParseLevel(payload.Level); err != nil {
+			return err
+		}
+	}
+
+	hookLevels := make(map[*LevelFilterHook]Level, len(payload.Hooks))
+	for name, lvl := range payload.Hooks {
+		hook, ok := h.hooks[name]
+		if !ok {
+			return fmt.Errorf("unknown hook %q", name)
+		}
+		l, err := ParseLevel(lvl)
+		if err != nil {
+			return err
+		}
+		hookLevels[hook] = l
+	}
+
+	if payload.Level != "" {
+		h.logger.SetLevel(level)
+	}
+	for hook, l := range hookLevels {
+		hook.SetLevel(l)
+	}
+	return nil
+}
+
+func writeLevelError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
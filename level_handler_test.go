@@ -0,0 +1,65 @@
+package logrus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelHandler(t *testing.T) {
+	log := New()
+	handler := NewLevelHandler(log)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"level":"info"}`+"\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("PUT", "/", strings.NewReader(`{"level":"debug"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"level":"debug"}`+"\n", w.Body.String())
+	assert.Equal(t, DebugLevel, log.level())
+}
+
+func TestLevelHandlerErrors(t *testing.T) {
+	log := New()
+	handler := NewLevelHandler(log)
+
+	for _, test := range []struct {
+		method string
+		body   string
+		code   int
+	}{
+		{"PUT", `{"level":"loud"}`, http.StatusBadRequest},
+		{"PUT", `level=debug`, http.StatusBadRequest},
+		{"POST", `{"level":"debug"}`, http.StatusMethodNotAllowed},
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(test.method, "/", strings.NewReader(test.body)))
+		assert.Equal(t, test.code, w.Code, test.body)
+		assert.Contains(t, w.Body.String(), `"error":`)
+	}
+	assert.Equal(t, InfoLevel, log.level())
+}
+
+func TestLevelHandlerHooks(t *testing.T) {
+	log := New()
+	hook := NewLevelFilterHook(new(TestHook), WarnLevel)
+	handler := NewLevelHandler(log)
+	handler.AddHook("test", hook)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("PUT", "/", strings.NewReader(`{"hooks":{"test":"debug"}}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"level":"info","hooks":{"test":"debug"}}`+"\n", w.Body.String())
+	assert.Equal(t, DebugLevel, hook.Level())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("PUT", "/", strings.NewReader(`{"level":"error","hooks":{"other":"debug"}}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, InfoLevel, log.level())
+}
@@ -0,0 +1,188 @@
+package logrus
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// customLevelBase is the first value of custom levels, leaving room for
+// levels added to logrus itself.
+const customLevelBase Level = 100
+
+var (
+	levelsMu sync.RWMutex
+	// custom levels by value and by name
+	customLevels     = map[Level]customLevel{}
+	customLevelNames = map[string]Level{}
+	// []float64 ranks of custom levels from customLevelBase, copied on
+	// registration so level checks don't take levelsMu
+	customRanks atomic.Value
+)
+
+type customLevel struct {
+	name string
+	// rank orders levels by verbosity, the rank of built-in levels is their
+	// value
+	rank float64
+}
+
+// RegisterLevel registers a custom level named name, e.g. "notice", which is
+// less severe than after and more severe than the levels less severe than
+// after so far:
+//
+//	var NoticeLevel = logrus.MustRegisterLevel("notice", logrus.WarnLevel)
+//	var TraceLevel = logrus.MustRegisterLevel("trace", logrus.DebugLevel)
+//
+// Entries of custom levels are logged with Log, Logf and Logln, when the
+// level of the logger, which may be a custom level too, is as verbose as
+// theirs. ParseLevel parses their names and all formatters log them.
+// Register levels on initialization, before adding hooks to loggers, so
+// AllLevels includes them.
+func RegisterLevel(name string, after Level) (Level, error) {
+	name = strings.ToLower(name)
+	if _, err := ParseLevel(name); err == nil {
+		return 0, fmt.Errorf("logrus level %q already exists", name)
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	if _, ok := customLevelNames[name]; ok {
+		return 0, fmt.Errorf("logrus level %q already exists", name)
+	}
+	if _, ok := customLevels[after]; !ok && after > DebugLevel {
+		return 0, fmt.Errorf("not a valid logrus Level: %d", after)
+	}
+
+	// rank it between after and the next less severe level
+	lower := rankLocked(after)
+	upper := lower + 1
+	for _, l := range AllLevels {
+		if r := rankLocked(l); r > lower && r < upper {
+			upper = r
+		}
+	}
+
+	level := customLevelBase + Level(len(customLevels))
+	customLevels[level] = customLevel{name: name, rank: (lower + upper) / 2}
+	customLevelNames[name] = level
+	AllLevels = append(AllLevels, level)
+
+	ranks, _ := customRanks.Load().([]float64)
+	customRanks.Store(append(ranks[:len(ranks):len(ranks)], customLevels[level].rank))
+	return level, nil
+}
+
+// MustRegisterLevel is like RegisterLevel but panics if the level can't be
+// registered.
+func MustRegisterLevel(name string, after Level) Level {
+	level, err := RegisterLevel(name, after)
+	if err != nil {
+		panic(err)
+	}
+	return level
+}
+
+// rank returns the verbosity rank of level.
+func (level Level) rank() float64 {
+	if level <= DebugLevel {
+		return float64(level)
+	}
+
+	ranks, _ := customRanks.Load().([]float64)
+	if i := int(level - customLevelBase); level >= customLevelBase && i < len(ranks) {
+		return ranks[i]
+	}
+	return float64(level)
+}
+
+// Must be called with levelsMu held.
+func rankLocked(level Level) float64 {
+	if l, ok := customLevels[level]; ok {
+		return l.rank
+	}
+	return float64(level)
+}
+
+// stepLevel returns the next more verbose level after level if more is true,
+// or else the next less verbose one, or level if there is none.
+func stepLevel(level Level, more bool) Level {
+	current := level.rank()
+	next := level
+	for _, l := range AllLevels {
+		r := l.rank()
+		if more && r > current && (next == level || r < next.rank()) ||
+			!more && r < current && (next == level || r > next.rank()) {
+			next = l
+		}
+	}
+	return next
+}
+
+// Builtin returns the built-in level of level, e.g. WarnLevel for a custom
+// level less severe than WarnLevel and more severe than InfoLevel.
+func (level Level) Builtin() Level {
+	builtin := Level(level.rank())
+	if builtin > DebugLevel {
+		return DebugLevel
+	}
+	return builtin
+}
+
+// IsLevelEnabled reports whether entries of level are logged by the logger.
+func (logger *Logger) IsLevelEnabled(level Level) bool {
+	if logger.nop != nil {
+		return false
+	}
+	current := logger.level()
+	if current <= DebugLevel && level <= DebugLevel {
+		return current >= level
+	}
+	return current.rank() >= level.rank()
+}
+
+// Log logs a message at level, e.g. a custom level, see RegisterLevel.
+// Unlike Fatal, it doesn't exit for FatalLevel.
+func (entry *Entry) Log(level Level, args ...interface{}) {
+	if entry.enabled(level) {
+		entry.log(level, fmt.Sprint(args...))
+	}
+}
+
+func (entry *Entry) Logf(level Level, format string, args ...interface{}) {
+	if entry.enabled(level) {
+		entry.Log(level, fmt.Sprintf(format, args...))
+	}
+}
+
+func (entry *Entry) Logln(level Level, args ...interface{}) {
+	if entry.enabled(level) {
+		entry.Log(level, entry.sprintlnn(args...))
+	}
+}
+
+func (logger *Logger) Log(level Level, args ...interface{}) {
+	if logger.IsLevelEnabled(level) {
+		entry := logger.newEntry()
+		entry.Log(level, args...)
+		logger.releaseEntry(entry)
+	}
+}
+
+func (logger *Logger) Logf(level Level, format string, args ...interface{}) {
+	if logger.IsLevelEnabled(level) {
+		entry := logger.newEntry()
+		entry.Logf(level, format, args...)
+		logger.releaseEntry(entry)
+	}
+}
+
+func (logger *Logger) Logln(level Level, args ...interface{}) {
+	if logger.IsLevelEnabled(level) {
+		entry := logger.newEntry()
+		entry.Logln(level, args...)
+		logger.releaseEntry(entry)
+	}
+}
@@ -0,0 +1,103 @@
+package logrus
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testNoticeLevel = MustRegisterLevel("notice", WarnLevel)
+	testAuditLevel  = MustRegisterLevel("aud", FatalLevel)
+	testTraceLevel  = MustRegisterLevel("trace", DebugLevel)
+)
+
+func TestCustomLevelNames(t *testing.T) {
+	assert.Equal(t, "notice", testNoticeLevel.String())
+
+	level, err := ParseLevel("NOTICE")
+	assert.NoError(t, err)
+	assert.Equal(t, testNoticeLevel, level)
+
+	assert.Contains(t, AllLevels, testTraceLevel)
+}
+
+func TestRegisterLevelErrors(t *testing.T) {
+	_, err := RegisterLevel("Notice", InfoLevel)
+	assert.Error(t, err)
+
+	_, err = RegisterLevel("warn", InfoLevel)
+	assert.Error(t, err)
+
+	_, err = RegisterLevel("verbose", Level(99))
+	assert.Error(t, err)
+}
+
+func TestCustomLevelOrdering(t *testing.T) {
+	log := New()
+
+	log.SetLevel(WarnLevel)
+	assert.False(t, log.IsLevelEnabled(testNoticeLevel))
+	assert.True(t, log.IsLevelEnabled(testAuditLevel))
+
+	log.SetLevel(InfoLevel)
+	assert.True(t, log.IsLevelEnabled(testNoticeLevel))
+	assert.False(t, log.IsLevelEnabled(testTraceLevel))
+
+	log.SetLevel(testNoticeLevel)
+	assert.True(t, log.IsLevelEnabled(WarnLevel))
+	assert.True(t, log.IsLevelEnabled(testNoticeLevel))
+	assert.False(t, log.IsLevelEnabled(InfoLevel))
+	assert.False(t, log.IsLevelEnabled(DebugLevel))
+
+	log.SetLevel(testTraceLevel)
+	assert.True(t, log.IsLevelEnabled(DebugLevel))
+}
+
+func TestLogCustomLevel(t *testing.T) {
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.WithField("a", 1).Logf(testNoticeLevel, "hello %s", "world")
+	}, func(fields Fields) {
+		assert.Equal(t, "notice", fields["level"])
+		assert.Equal(t, "hello world", fields["msg"])
+	})
+}
+
+func TestLogCustomLevelDisabled(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+
+	log.Log(testTraceLevel, "test")
+	assert.Empty(t, buffer.String())
+}
+
+func TestCustomLevelColors(t *testing.T) {
+	tf := &TextFormatter{ForceColors: true, DisableTimestamp: true}
+
+	entry := WithField("a", 1)
+	entry.Level = testAuditLevel
+	b, _ := tf.Format(entry)
+	assert.True(t, strings.HasPrefix(string(b), "\x1b[31mAUD\x1b[0m"), string(b))
+}
+
+func TestDisabledLevelDoesNotAllocate(t *testing.T) {
+	logger := New()
+	logger.Out = ioutil.Discard
+	logger.SetLevel(WarnLevel)
+	entry := logger.WithField("a", 1)
+	str, n := "str", 1234567
+
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Debugf("aaa %s %d", str, n)
+		logger.Info("aaa", str, n)
+		logger.Printf("aaa %s %d", str, n)
+		logger.Log(testTraceLevel, "aaa", str, n)
+		entry.Infof("aaa %s %d", str, n)
+		entry.Logf(testNoticeLevel, "aaa %s %d", str, n)
+	})
+	assert.Equal(t, float64(0), allocs)
+}
@@ -3,14 +3,17 @@ package logrus
 import (
 	"io"
 	"os"
+	"runtime"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type Logger struct {
 	// The logs are `io.Copy`'d to this in a mutex. It's common to set this to a
 	// file, or leave it default which is `os.Stderr`. You can also set this to
-	// something more adventorous, such as logging to Kafka.
+	// something more adventorous, such as logging to Kafka. It may be nil to
+	// log to the writers added with AddOutput only.
 	Out io.Writer
 	// Hooks for the logger instance. These allow firing events based on logging
 	// levels and log entries. For example, to send errors to an error tracking
@@ -29,8 +32,70 @@ type Logger struct {
 	Level Level
 	// Used to sync writing to the log. Locking is enabled by Default
 	mu MutexWrap
+	// Held for reading while entries are written, see Reconfigure
+	writes sync.RWMutex
 	// Reusable empty entry
 	entryPool sync.Pool
+	// Extract fields from the context of entries
+	contextExtractors []ContextExtractor
+	// Generates trace IDs for contexts without one, see SetTraceIDGeneration
+	traceIDGeneration bool
+	// Samples entries, see SetSampling
+	sampler *sampler
+	// Limits the rate of entries, see SetRateLimit
+	limiter *rateLimiter
+	// Collapses duplicate entries, see SetDuplicateSuppression
+	deduper *deduper
+	// Transform entries, see AddMiddleware
+	middlewares []Middleware
+	// Redacts the values of fields, see SetRedaction
+	redactor *redactor
+	// Scrubs messages and fields, see SetScrubber
+	scrubber *Scrubber
+	// Counts the entries and bytes written, see SetStatsEnabled
+	stats *Stats
+	// The entry of a logger logging nothing, see NewNop
+	nop *Entry
+	// Writers the entries are written to besides Out, see AddOutput
+	outputs []*output
+	// Fires hooks in the background, see SetAsyncHooks
+	asyncHooks *asyncHooks
+	// Handle the failures of hooks, see SetHookPolicy
+	hookPolicies hookPolicies
+	// Handles the errors of hooks, see SetHookErrorHandler
+	hookErrorHandler atomic.Value
+	// Flag for whether to log caller info (off by default)
+	ReportCaller bool
+	// Number of stack frames to skip above logrus when reporting the caller,
+	// e.g. 1 to report the callers of a function wrapping logrus.
+	CallerSkip int
+	// Prefixes trimmed from the files of callers, e.g. the module root, so
+	// logs show `pkg/handler.go:42` instead of absolute paths.
+	CallerTrimPrefixes []string
+	// Formats the function and file of callers for all formatters, instead
+	// of the full function name and the trimmed `file:line`.
+	CallerPrettyfier func(*runtime.Frame) (function string, file string)
+	// Flag for whether to add the stack to Error, Fatal and Panic entries in
+	// the FieldKeyStack field (off by default)
+	ReportStack bool
+	// Maximum number of frames of the stack, 32 by default
+	StackDepth int
+	// Function called with the exit code by Exit, e.g. on Fatal entries,
+	// after the exit handlers, instead of closing the logger and calling
+	// os.Exit. Fatal returns if it returns.
+	ExitFunc func(int)
+	// Flag for whether Fatal entries are logged at Error level, without
+	// exiting, see SetDemoteFatal (off by default)
+	DemoteFatal bool
+	// Maximum time Panic waits for the hooks to fire, unlimited by default,
+	// see SetPanicFlushTimeout
+	PanicFlushTimeout time.Duration
+	// Flag for whether RecoverAndLog and the other recovering functions
+	// panic again after logging a panic (off by default)
+	RepanicRecovered bool
+	// Flag for whether WithError adds the message, type, stack and causes of
+	// the chain of wrapped errors as separate fields (off by default)
+	UnwrapErrors bool
 }
 
 type MutexWrap struct {
@@ -76,6 +141,9 @@ func New() *Logger {
 }
 
 func (logger *Logger) newEntry() *Entry {
+	if logger.nop != nil {
+		return logger.nop
+	}
 	entry, ok := logger.entryPool.Get().(*Entry)
 	if ok {
 		return entry
@@ -85,6 +153,9 @@ func (logger *Logger) newEntry() *Entry {
 }
 
 func (logger *Logger) releaseEntry(entry *Entry) {
+	if entry == logger.nop {
+		return
+	}
 	logger.entryPool.Put(entry)
 }
 
@@ -113,8 +184,16 @@ func (logger *Logger) WithError(err error) *Entry {
 	return entry.WithError(err)
 }
 
+// Sets the time of the log entry, logged instead of the time of logging,
+// see Entry.WithTime.
+func (logger *Logger) WithTime(t time.Time) *Entry {
+	entry := logger.newEntry()
+	defer logger.releaseEntry(entry)
+	return entry.WithTime(t)
+}
+
 func (logger *Logger) Debugf(format string, args ...interface{}) {
-	if logger.level() >= DebugLevel {
+	if logger.IsLevelEnabled(DebugLevel) {
 		entry := logger.newEntry()
 		entry.Debugf(format, args...)
 		logger.releaseEntry(entry)
@@ -122,7 +201,7 @@ func (logger *Logger) Debugf(format string, args ...interface{}) {
 }
 
 func (logger *Logger) Infof(format string, args ...interface{}) {
-	if logger.level() >= InfoLevel {
+	if logger.IsLevelEnabled(InfoLevel) {
 		entry := logger.newEntry()
 		entry.Infof(format, args...)
 		logger.releaseEntry(entry)
@@ -130,13 +209,15 @@ func (logger *Logger) Infof(format string, args ...interface{}) {
 }
 
 func (logger *Logger) Printf(format string, args ...interface{}) {
-	entry := logger.newEntry()
-	entry.Printf(format, args...)
-	logger.releaseEntry(entry)
+	if logger.IsLevelEnabled(InfoLevel) {
+		entry := logger.newEntry()
+		entry.Printf(format, args...)
+		logger.releaseEntry(entry)
+	}
 }
 
 func (logger *Logger) Warnf(format string, args ...interface{}) {
-	if logger.level() >= WarnLevel {
+	if logger.IsLevelEnabled(WarnLevel) {
 		entry := logger.newEntry()
 		entry.Warnf(format, args...)
 		logger.releaseEntry(entry)
@@ -144,7 +225,7 @@ func (logger *Logger) Warnf(format string, args ...interface{}) {
 }
 
 func (logger *Logger) Warningf(format string, args ...interface{}) {
-	if logger.level() >= WarnLevel {
+	if logger.IsLevelEnabled(WarnLevel) {
 		entry := logger.newEntry()
 		entry.Warnf(format, args...)
 		logger.releaseEntry(entry)
@@ -152,7 +233,7 @@ func (logger *Logger) Warningf(format string, args ...interface{}) {
 }
 
 func (logger *Logger) Errorf(format string, args ...interface{}) {
-	if logger.level() >= ErrorLevel {
+	if logger.IsLevelEnabled(ErrorLevel) {
 		entry := logger.newEntry()
 		entry.Errorf(format, args...)
 		logger.releaseEntry(entry)
@@ -160,24 +241,19 @@ func (logger *Logger) Errorf(format string, args ...interface{}) {
 }
 
 func (logger *Logger) Fatalf(format string, args ...interface{}) {
-	if logger.level() >= FatalLevel {
-		entry := logger.newEntry()
-		entry.Fatalf(format, args...)
-		logger.releaseEntry(entry)
-	}
-	logger.Exit(1)
+	entry := logger.newEntry()
+	entry.Fatalf(format, args...)
+	logger.releaseEntry(entry)
 }
 
 func (logger *Logger) Panicf(format string, args ...interface{}) {
-	if logger.level() >= PanicLevel {
-		entry := logger.newEntry()
-		entry.Panicf(format, args...)
-		logger.releaseEntry(entry)
-	}
+	entry := logger.newEntry()
+	entry.Panicf(format, args...)
+	logger.releaseEntry(entry)
 }
 
 func (logger *Logger) Debug(args ...interface{}) {
-	if logger.level() >= DebugLevel {
+	if logger.IsLevelEnabled(DebugLevel) {
 		entry := logger.newEntry()
 		entry.Debug(args...)
 		logger.releaseEntry(entry)
@@ -185,7 +261,7 @@ func (logger *Logger) Debug(args ...interface{}) {
 }
 
 func (logger *Logger) Info(args ...interface{}) {
-	if logger.level() >= InfoLevel {
+	if logger.IsLevelEnabled(InfoLevel) {
 		entry := logger.newEntry()
 		entry.Info(args...)
 		logger.releaseEntry(entry)
@@ -193,13 +269,15 @@ func (logger *Logger) Info(args ...interface{}) {
 }
 
 func (logger *Logger) Print(args ...interface{}) {
-	entry := logger.newEntry()
-	entry.Info(args...)
-	logger.releaseEntry(entry)
+	if logger.IsLevelEnabled(InfoLevel) {
+		entry := logger.newEntry()
+		entry.Info(args...)
+		logger.releaseEntry(entry)
+	}
 }
 
 func (logger *Logger) Warn(args ...interface{}) {
-	if logger.level() >= WarnLevel {
+	if logger.IsLevelEnabled(WarnLevel) {
 		entry := logger.newEntry()
 		entry.Warn(args...)
 		logger.releaseEntry(entry)
@@ -207,7 +285,7 @@ func (logger *Logger) Warn(args ...interface{}) {
 }
 
 func (logger *Logger) Warning(args ...interface{}) {
-	if logger.level() >= WarnLevel {
+	if logger.IsLevelEnabled(WarnLevel) {
 		entry := logger.newEntry()
 		entry.Warn(args...)
 		logger.releaseEntry(entry)
@@ -215,7 +293,7 @@ func (logger *Logger) Warning(args ...interface{}) {
 }
 
 func (logger *Logger) Error(args ...interface{}) {
-	if logger.level() >= ErrorLevel {
+	if logger.IsLevelEnabled(ErrorLevel) {
 		entry := logger.newEntry()
 		entry.Error(args...)
 		logger.releaseEntry(entry)
@@ -223,24 +301,20 @@ func (logger *Logger) Error(args ...interface{}) {
 }
 
 func (logger *Logger) Fatal(args ...interface{}) {
-	if logger.level() >= FatalLevel {
-		entry := logger.newEntry()
-		entry.Fatal(args...)
-		logger.releaseEntry(entry)
-	}
-	logger.Exit(1)
+	// the entry exits, once, even if the level is disabled
+	entry := logger.newEntry()
+	entry.Fatal(args...)
+	logger.releaseEntry(entry)
 }
 
 func (logger *Logger) Panic(args ...interface{}) {
-	if logger.level() >= PanicLevel {
-		entry := logger.newEntry()
-		entry.Panic(args...)
-		logger.releaseEntry(entry)
-	}
+	entry := logger.newEntry()
+	entry.Panic(args...)
+	logger.releaseEntry(entry)
 }
 
 func (logger *Logger) Debugln(args ...interface{}) {
-	if logger.level() >= DebugLevel {
+	if logger.IsLevelEnabled(DebugLevel) {
 		entry := logger.newEntry()
 		entry.Debugln(args...)
 		logger.releaseEntry(entry)
@@ -248,7 +322,7 @@ func (logger *Logger) Debugln(args ...interface{}) {
 }
 
 func (logger *Logger) Infoln(args ...interface{}) {
-	if logger.level() >= InfoLevel {
+	if logger.IsLevelEnabled(InfoLevel) {
 		entry := logger.newEntry()
 		entry.Infoln(args...)
 		logger.releaseEntry(entry)
@@ -256,13 +330,15 @@ func (logger *Logger) Infoln(args ...interface{}) {
 }
 
 func (logger *Logger) Println(args ...interface{}) {
-	entry := logger.newEntry()
-	entry.Println(args...)
-	logger.releaseEntry(entry)
+	if logger.IsLevelEnabled(InfoLevel) {
+		entry := logger.newEntry()
+		entry.Println(args...)
+		logger.releaseEntry(entry)
+	}
 }
 
 func (logger *Logger) Warnln(args ...interface{}) {
-	if logger.level() >= WarnLevel {
+	if logger.IsLevelEnabled(WarnLevel) {
 		entry := logger.newEntry()
 		entry.Warnln(args...)
 		logger.releaseEntry(entry)
@@ -270,7 +346,7 @@ func (logger *Logger) Warnln(args ...interface{}) {
 }
 
 func (logger *Logger) Warningln(args ...interface{}) {
-	if logger.level() >= WarnLevel {
+	if logger.IsLevelEnabled(WarnLevel) {
 		entry := logger.newEntry()
 		entry.Warnln(args...)
 		logger.releaseEntry(entry)
@@ -278,7 +354,7 @@ func (logger *Logger) Warningln(args ...interface{}) {
 }
 
 func (logger *Logger) Errorln(args ...interface{}) {
-	if logger.level() >= ErrorLevel {
+	if logger.IsLevelEnabled(ErrorLevel) {
 		entry := logger.newEntry()
 		entry.Errorln(args...)
 		logger.releaseEntry(entry)
@@ -286,20 +362,15 @@ func (logger *Logger) Errorln(args ...interface{}) {
 }
 
 func (logger *Logger) Fatalln(args ...interface{}) {
-	if logger.level() >= FatalLevel {
-		entry := logger.newEntry()
-		entry.Fatalln(args...)
-		logger.releaseEntry(entry)
-	}
-	logger.Exit(1)
+	entry := logger.newEntry()
+	entry.Fatalln(args...)
+	logger.releaseEntry(entry)
 }
 
 func (logger *Logger) Panicln(args ...interface{}) {
-	if logger.level() >= PanicLevel {
-		entry := logger.newEntry()
-		entry.Panicln(args...)
-		logger.releaseEntry(entry)
-	}
+	entry := logger.newEntry()
+	entry.Panicln(args...)
+	logger.releaseEntry(entry)
 }
 
 //When file is opened with appending mode, it's safe to
@@ -349,20 +420,58 @@ func (logger *Logger) AddHook(hook Hook) {
 	logger.Hooks.Add(hook)
 }
 
-func (logger *Logger) Close() {
+// Close fires the entries queued for async hooks, then closes the hooks, Out
+// and the writers of the outputs implementing io.Closer, once each, except
+// os.Stdout and os.Stderr. It returns the errors of closing them. The logger
+// writes nowhere after.
+func (logger *Logger) Close() error {
+	logger.writes.Lock()
+	defer logger.writes.Unlock()
 	logger.mu.Lock()
 	defer logger.mu.Unlock()
 
-	if out, ok := logger.Out.(io.WriteCloser); ok {
-		out.Close()
-		logger.Out = nil
+	if logger.asyncHooks != nil {
+		logger.asyncHooks.stop()
+		logger.asyncHooks = nil
 	}
 
-	logger.Hooks.Close()
-	logger.Hooks = nil
+	var c closer
+	logger.Hooks.close(&c)
+	logger.Hooks = make(LevelHooks)
+	for _, w := range logger.writers() {
+		if wc, ok := w.(io.Closer); ok {
+			c.close(wc)
+		}
+	}
+	logger.Out = nil
+	logger.outputs = nil
+
+	return c.err()
 }
 
+// Exit runs the Logrus exit handlers, which may still log, and calls the
+// logger's ExitFunc, or else closes the logger and terminates the program
+// using os.Exit(code).
 func (logger *Logger) Exit(code int) {
+	logger.mu.Lock()
+	exitFunc := logger.ExitFunc
+	logger.mu.Unlock()
+
+	runHandlers()
+	if exitFunc != nil {
+		exitFunc(code)
+		return
+	}
+
 	logger.Close()
-	Exit(code)
+	os.Exit(code)
+}
+
+// SetExitFunc sets the function called by Exit, e.g. on Fatal entries, see
+// ExitFunc.
+func (logger *Logger) SetExitFunc(exitFunc func(int)) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	logger.ExitFunc = exitFunc
 }
@@ -1,6 +1,7 @@
 package logrus
 
 import (
+	"io/ioutil"
 	"os"
 	"testing"
 )
@@ -59,3 +60,27 @@ func doLoggerBenchmarkNoLock(b *testing.B, out *os.File, formatter Formatter, fi
 		}
 	})
 }
+
+func BenchmarkDisabledLevel(b *testing.B) {
+	logger := New()
+	logger.Out = ioutil.Discard
+	entry := logger.WithFields(smallFields)
+	str, n := "str", 1234567
+
+	for name, log := range map[string]func(){
+		"Logger.Debug":   func() { logger.Debug("aaa") },
+		"Logger.Debugf":  func() { logger.Debugf("aaa %s %d", str, n) },
+		"Logger.Debugln": func() { logger.Debugln("aaa", str, n) },
+		"Logger.Logf":    func() { logger.Logf(DebugLevel, "aaa %s %d", str, n) },
+		"Entry.Debug":    func() { entry.Debug("aaa", str, n) },
+		"Entry.Debugf":   func() { entry.Debugf("aaa %s %d", str, n) },
+		"Entry.Logf":     func() { entry.Logf(DebugLevel, "aaa %s %d", str, n) },
+	} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				log()
+			}
+		})
+	}
+}
@@ -29,6 +29,11 @@ func (level Level) String() string {
 		return "panic"
 	}
 
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	if l, ok := customLevels[level]; ok {
+		return l.name
+	}
 	return "unknown"
 }
 
@@ -49,6 +54,13 @@ func ParseLevel(lvl string) (Level, error) {
 		return DebugLevel, nil
 	}
 
+	levelsMu.RLock()
+	level, ok := customLevelNames[strings.ToLower(lvl)]
+	levelsMu.RUnlock()
+	if ok {
+		return level, nil
+	}
+
 	var l Level
 	return l, fmt.Errorf("not a valid logrus Level: %q", lvl)
 }
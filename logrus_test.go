@@ -384,3 +384,54 @@ func TestEntryWriter(t *testing.T) {
 	assert.Equal(t, fields["foo"], "bar")
 	assert.Equal(t, fields["level"], "warning")
 }
+
+func TestEntryWriterParseLevel(t *testing.T) {
+	cw := channelWriter(make(chan []byte, 1))
+	log := New()
+	log.Out = cw
+	log.Formatter = new(JSONFormatter)
+	log.SetLevel(DebugLevel)
+	w := log.WithField("foo", "bar").WriterParseLevel(InfoLevel)
+	defer w.Close()
+
+	for _, test := range []struct {
+		line, level, msg string
+	}{
+		{"ERROR: disk full", "error", "disk full"},
+		{"[debug] cache miss", "debug", "cache miss"},
+		{"WARN retrying", "warning", "retrying"},
+		{"Info about the disk", "info", "Info about the disk"},
+	} {
+		w.Write([]byte(test.line + "\n"))
+
+		var fields Fields
+		err := json.Unmarshal(<-cw, &fields)
+		assert.Nil(t, err)
+		assert.Equal(t, "bar", fields["foo"])
+		assert.Equal(t, test.level, fields["level"])
+		assert.Equal(t, test.msg, fields["msg"])
+	}
+}
+
+func TestParseLevelPrefix(t *testing.T) {
+	for line, expected := range map[string]struct {
+		level Level
+		msg   string
+	}{
+		"error: a":     {ErrorLevel, "a"},
+		"  [WARNING]a": {WarnLevel, "a"},
+		"ERR a":        {ErrorLevel, "a"},
+		"DBG: a":       {DebugLevel, "a"},
+		"PANIC: a":     {FatalLevel, "a"},
+		"NOTICE a":     {testNoticeLevel, "a"},
+		"Warn a":       {InfoLevel, "Warn a"},
+		"[a] b":        {InfoLevel, "[a] b"},
+		"a: b":         {InfoLevel, "a: b"},
+		"[debug":       {InfoLevel, "[debug"},
+		"debug":        {InfoLevel, "debug"},
+	} {
+		level, msg := parseLevelPrefix(line, InfoLevel)
+		assert.Equal(t, expected.level, level, line)
+		assert.Equal(t, expected.msg, msg, line)
+	}
+}
@@ -0,0 +1,215 @@
+// Package logrusconfig builds loggers from YAML or JSON documents, so services
+// can change their level, formatters, outputs and hooks without being
+// recompiled.
+package logrusconfig
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/dorofeevsa/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration of a logger, e.g.
+//
+//	level: debug
+//	report_caller: true
+//	formatter:
+//	  type: text
+//	  full_timestamp: true
+//	outputs:
+//	  - path: stdout
+//	    level: info
+//	  - path: /var/log/app.json
+//	    formatter:
+//	      type: json
+//	hooks:
+//	  - type: syslog
+//	    level: error
+//	    options:
+//	      network: udp
+//	      address: localhost:514
+//
+// JSON documents use the same keys.
+type Config struct {
+	// Level of the logger, info by default
+	Level string `yaml:"level"`
+	// Whether the logger reports callers
+	ReportCaller bool `yaml:"report_caller"`
+	// Formatter of the logger, and of the outputs without one
+	Formatter *FormatterConfig `yaml:"formatter"`
+	// Writers the logger writes to, stderr if none
+	Outputs []OutputConfig `yaml:"outputs"`
+	// Hooks of the logger, of the types registered with RegisterHook
+	Hooks []HookConfig `yaml:"hooks"`
+}
+
+// FormatterConfig is the configuration of a formatter. Options not used by
+// its type are ignored.
+type FormatterConfig struct {
+	// "text" (the default), "logfmt" for text without colors, "json" or
+	// "ecs"
+	Type             string `yaml:"type"`
+	TimestampFormat  string `yaml:"timestamp_format"`
+	DisableTimestamp bool   `yaml:"disable_timestamp"`
+	// Options of the text formatter
+	FullTimestamp bool `yaml:"full_timestamp"`
+	ForceColors   bool `yaml:"force_colors"`
+	DisableColors bool `yaml:"disable_colors"`
+	// Options of the JSON formatter
+	PrettyPrint bool   `yaml:"pretty_print"`
+	DataKey     string `yaml:"data_key"`
+}
+
+// OutputConfig is the configuration of a writer of the logger, see
+// logrus.Logger.AddOutput.
+type OutputConfig struct {
+	// "stdout", "stderr" or the path of a file, which is appended to
+	Path string `yaml:"path"`
+	// Minimum level of the entries written, all levels of the logger by
+	// default
+	Level string `yaml:"level"`
+	// Formatter of the entries, the logger's by default
+	Formatter *FormatterConfig `yaml:"formatter"`
+}
+
+// HookConfig is the configuration of a hook.
+type HookConfig struct {
+	// Type the hook is registered with, see RegisterHook
+	Type string `yaml:"type"`
+	// Minimum level of the entries fired, all levels of the hook by default
+	Level string `yaml:"level"`
+	// Options of the hook, decoded by its factory
+	Options yaml.Node `yaml:"options"`
+}
+
+// Load reads the configuration in the YAML or JSON file at path.
+func Load(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses a YAML or JSON configuration. Unknown keys are errors, so
+// typos don't go unnoticed.
+func Parse(data []byte) (*Config, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var c Config
+	if err := decoder.Decode(&c); err != nil {
+		return nil, fmt.Errorf("logrusconfig: %v", err)
+	}
+	return &c, nil
+}
+
+// Build returns a new logger configured by c. The files of its outputs and
+// its hooks are closed by the logger's Close.
+func (c *Config) Build() (*logrus.Logger, error) {
+	logger := logrus.New()
+	if err := c.build(logger); err != nil {
+		logger.Close()
+		return nil, err
+	}
+	return logger, nil
+}
+
+func (c *Config) build(logger *logrus.Logger) error {
+	level := logrus.InfoLevel
+	if c.Level != "" {
+		var err error
+		if level, err = logrus.ParseLevel(c.Level); err != nil {
+			return fmt.Errorf("logrusconfig: %v", err)
+		}
+	}
+	logger.SetLevel(level)
+	logger.SetReportCaller(c.ReportCaller)
+
+	if c.Formatter != nil {
+		formatter, err := c.Formatter.Build()
+		if err != nil {
+			return err
+		}
+		logger.SetFormatter(formatter)
+	}
+
+	if len(c.Outputs) > 0 {
+		logger.SetOut(nil)
+	}
+	for i, o := range c.Outputs {
+		if err := o.add(logger, level); err != nil {
+			return fmt.Errorf("logrusconfig: output %d: %v", i, err)
+		}
+	}
+
+	for i, h := range c.Hooks {
+		hook, err := h.Build()
+		if err != nil {
+			return fmt.Errorf("logrusconfig: hook %d: %v", i, err)
+		}
+		logger.AddHook(hook)
+	}
+	return nil
+}
+
+// Build returns the formatter configured by c.
+func (c *FormatterConfig) Build() (logrus.Formatter, error) {
+	switch c.Type {
+	case "", "text", "logfmt":
+		return &logrus.TextFormatter{
+			TimestampFormat:  c.TimestampFormat,
+			DisableTimestamp: c.DisableTimestamp,
+			FullTimestamp:    c.FullTimestamp,
+			ForceColors:      c.ForceColors && c.Type != "logfmt",
+			DisableColors:    c.DisableColors || c.Type == "logfmt",
+		}, nil
+	case "json":
+		return &logrus.JSONFormatter{
+			TimestampFormat:  c.TimestampFormat,
+			DisableTimestamp: c.DisableTimestamp,
+			PrettyPrint:      c.PrettyPrint,
+			DataKey:          c.DataKey,
+		}, nil
+	case "ecs":
+		return &logrus.ECSFormatter{DisableTimestamp: c.DisableTimestamp}, nil
+	}
+	return nil, fmt.Errorf("logrusconfig: unknown formatter type %q", c.Type)
+}
+
+func (c *OutputConfig) add(logger *logrus.Logger, level logrus.Level) error {
+	if c.Level != "" {
+		var err error
+		if level, err = logrus.ParseLevel(c.Level); err != nil {
+			return err
+		}
+	}
+
+	var formatter logrus.Formatter
+	if c.Formatter != nil {
+		var err error
+		if formatter, err = c.Formatter.Build(); err != nil {
+			return err
+		}
+	}
+
+	switch c.Path {
+	case "":
+		return fmt.Errorf("missing path")
+	case "stdout":
+		logger.AddOutput(os.Stdout, level, formatter)
+	case "stderr":
+		logger.AddOutput(os.Stderr, level, formatter)
+	default:
+		f, err := os.OpenFile(c.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		if err != nil {
+			return err
+		}
+		logger.AddOutput(f, level, formatter)
+	}
+	return nil
+}
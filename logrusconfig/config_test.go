@@ -0,0 +1,274 @@
+package logrusconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+func TestBuild(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logrusconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.json")
+
+	c, err := Parse([]byte(`
+level: debug
+report_caller: true
+formatter:
+  type: logfmt
+  disable_timestamp: true
+outputs:
+  - path: ` + path + `
+    level: warn
+    formatter:
+      type: json
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if logger.Level != logrus.DebugLevel {
+		t.Errorf("expected the debug level, got %v", logger.Level)
+	}
+	if !logger.ReportCaller {
+		t.Error("expected the logger to report callers")
+	}
+	if f, ok := logger.Formatter.(*logrus.TextFormatter); !ok || !f.DisableColors || !f.DisableTimestamp {
+		t.Errorf("expected a logfmt formatter, got %#v", logger.Formatter)
+	}
+
+	logger.Info("skipped")
+	logger.WithField("key", "value").Warn("written")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields logrus.Fields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("expected a JSON line, got %q: %v", data, err)
+	}
+	if fields["msg"] != "written" || fields["key"] != "value" {
+		t.Errorf("unexpected entry %v", fields)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	c, err := Parse([]byte(`{"level": "warn", "formatter": {"type": "json", "pretty_print": true}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logger.Level != logrus.WarnLevel {
+		t.Errorf("expected the warn level, got %v", logger.Level)
+	}
+	if f, ok := logger.Formatter.(*logrus.JSONFormatter); !ok || !f.PrettyPrint {
+		t.Errorf("expected a pretty JSON formatter, got %#v", logger.Formatter)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, doc := range []string{
+		"levle: debug",
+		"level: [debug]",
+	} {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("expected an error for %q", doc)
+		}
+	}
+
+	for _, doc := range []string{
+		"level: loud",
+		"formatter: {type: xml}",
+		"outputs: [{level: info}]",
+		"hooks: [{type: unknown}]",
+		"hooks: [{type: lfshook, options: {paht: app.log}}]",
+	} {
+		c, err := Parse([]byte(doc))
+		if err != nil {
+			t.Fatalf("%q: %v", doc, err)
+		}
+		if _, err := c.Build(); err == nil {
+			t.Errorf("expected an error for %q", doc)
+		}
+	}
+}
+
+type testHook struct {
+	prefix string
+	fired  []string
+}
+
+func (hook *testHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (hook *testHook) Fire(entry *logrus.Entry) error {
+	hook.fired = append(hook.fired, hook.prefix+entry.Message)
+	return nil
+}
+
+func (hook *testHook) Close() error {
+	return nil
+}
+
+func TestRegisterHook(t *testing.T) {
+	var hook *testHook
+	RegisterHook("test", func(decode func(interface{}) error) (logrus.Hook, error) {
+		var options struct {
+			Prefix string `yaml:"prefix"`
+		}
+		if err := decode(&options); err != nil {
+			return nil, err
+		}
+		if options.Prefix == "" {
+			return nil, errors.New("missing prefix")
+		}
+		hook = &testHook{prefix: options.Prefix}
+		return hook, nil
+	})
+
+	c, err := Parse([]byte(`
+outputs: [{path: stderr, level: panic}]
+hooks:
+  - type: test
+    level: warn
+    options:
+      prefix: "test: "
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("info")
+	logger.Warn("warn")
+	if strings.Join(hook.fired, ",") != "test: warn" {
+		t.Errorf("expected the warning to be fired, got %v", hook.fired)
+	}
+
+	c.Hooks[0].Options.Content = nil
+	if _, err := c.Build(); err == nil || !strings.Contains(err.Error(), "missing prefix") {
+		t.Errorf("expected the error of the factory, got %v", err)
+	}
+}
+
+func TestLfsHook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logrusconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "errors.log")
+
+	c, err := Parse([]byte(`
+outputs: [{path: stderr, level: panic}]
+hooks:
+  - type: lfshook
+    options:
+      paths: {error: ` + path + `}
+      formatter: {type: json}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("skipped")
+	logger.Error("failed")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"msg":"failed"`) || strings.Contains(string(data), "skipped") {
+		t.Errorf("unexpected file content %q", data)
+	}
+}
+
+func TestRotatelogHook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logrusconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := Parse([]byte(`
+outputs: [{path: stderr, level: panic}]
+hooks:
+  - type: rotatelog
+    level: error
+    options:
+      pattern: ` + filepath.Join(dir, "app.%Y%m%d.log") + `
+      rotation_time: 1h
+      rotation_count: 3
+      formatter: {type: json}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("skipped")
+	logger.Error("failed")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "app.*.log"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected a log file, got %v, %v", files, err)
+	}
+	data, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"msg":"failed"`) || strings.Contains(string(data), "skipped") {
+		t.Errorf("unexpected file content %q", data)
+	}
+}
+
+func TestRotatelogHookErrors(t *testing.T) {
+	for _, options := range []string{
+		`{}`,
+		`{pattern: app.log, max_age: 1h, rotation_count: 3}`,
+		`{pattern: app.log, rotation: 1h}`,
+	} {
+		c, err := Parse([]byte(`hooks: [{type: rotatelog, options: ` + options + `}]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Build(); err == nil {
+			t.Errorf("expected an error for %s", options)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package logrusconfig
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/dorofeevsa/logrus"
+	"github.com/dorofeevsa/logrus/hooks/lfslog"
+	"gopkg.in/yaml.v3"
+)
+
+// HookFactory builds a hook from the options of its configuration, decoding
+// them into a struct with decode, e.g.
+//
+//	logrusconfig.RegisterHook("airbrake", func(decode func(interface{}) error) (logrus.Hook, error) {
+//		var options struct {
+//			ProjectID int64  `yaml:"project_id"`
+//			Key       string `yaml:"key"`
+//			Env       string `yaml:"env"`
+//		}
+//		if err := decode(&options); err != nil {
+//			return nil, err
+//		}
+//		return airbrake.NewHook(options.ProjectID, options.Key, options.Env), nil
+//	})
+type HookFactory func(decode func(options interface{}) error) (logrus.Hook, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]HookFactory{}
+)
+
+// RegisterHook registers the factory of the hooks of type typ, replacing the
+// one registered already, if any. The "lfshook", "rotatelog" and, where
+// supported, "syslog" types are registered by default.
+func RegisterHook(typ string, factory HookFactory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	factories[typ] = factory
+}
+
+// Build returns the hook configured by c, firing only entries at least as
+// severe as its level, if any.
+func (c *HookConfig) Build() (logrus.Hook, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[c.Type]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown hook type %q", c.Type)
+	}
+
+	var level logrus.Level
+	if c.Level != "" {
+		var err error
+		if level, err = logrus.ParseLevel(c.Level); err != nil {
+			return nil, err
+		}
+	}
+
+	hook, err := factory(c.decode)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", c.Type, err)
+	}
+	if c.Level != "" {
+		hook = logrus.NewLevelFilterHook(hook, level)
+	}
+	return hook, nil
+}
+
+// decode decodes the options of the hook into v, rejecting unknown keys.
+func (c *HookConfig) decode(v interface{}) error {
+	if c.Options.Kind == 0 {
+		return nil
+	}
+	data, err := yaml.Marshal(&c.Options)
+	if err != nil {
+		return err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	return decoder.Decode(v)
+}
+
+func init() {
+	RegisterHook("lfshook", newLfsHook)
+}
+
+// newLfsHook builds an lfslog hook writing to a single file, or a file per
+// level.
+func newLfsHook(decode func(interface{}) error) (logrus.Hook, error) {
+	var options struct {
+		Path      string            `yaml:"path"`
+		Paths     map[string]string `yaml:"paths"`
+		Formatter *FormatterConfig  `yaml:"formatter"`
+	}
+	if err := decode(&options); err != nil {
+		return nil, err
+	}
+
+	var formatter logrus.Formatter
+	if options.Formatter != nil {
+		var err error
+		if formatter, err = options.Formatter.Build(); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Path != "" {
+		return lfslog.NewSinglePathHook(options.Path, formatter), nil
+	}
+	if len(options.Paths) == 0 {
+		return nil, fmt.Errorf("missing path or paths")
+	}
+	paths := make(lfslog.PathMap, len(options.Paths))
+	for name, path := range options.Paths {
+		level, err := logrus.ParseLevel(name)
+		if err != nil {
+			return nil, err
+		}
+		paths[level] = path
+	}
+	return lfslog.NewPathHook(paths, formatter), nil
+}
@@ -0,0 +1,62 @@
+package logrusconfig
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dorofeevsa/logrus"
+	"github.com/dorofeevsa/logrus/hooks/rotatelog"
+)
+
+func init() {
+	RegisterHook("rotatelog", newRotatelogHook)
+}
+
+// newRotatelogHook builds a rotatelog hook writing to files named by a
+// strftime pattern.
+func newRotatelogHook(decode func(interface{}) error) (logrus.Hook, error) {
+	var options struct {
+		Pattern       string           `yaml:"pattern"`
+		LinkName      string           `yaml:"link_name"`
+		MaxAge        time.Duration    `yaml:"max_age"`
+		RotationTime  time.Duration    `yaml:"rotation_time"`
+		RotationCount uint             `yaml:"rotation_count"`
+		Formatter     *FormatterConfig `yaml:"formatter"`
+	}
+	if err := decode(&options); err != nil {
+		return nil, err
+	}
+	if options.Pattern == "" {
+		return nil, fmt.Errorf("missing pattern")
+	}
+
+	var formatter logrus.Formatter
+	if options.Formatter != nil {
+		var err error
+		if formatter, err = options.Formatter.Build(); err != nil {
+			return nil, err
+		}
+	}
+
+	var rotateOptions []rotatelog.Option
+	if options.LinkName != "" {
+		rotateOptions = append(rotateOptions, rotatelog.WithLinkName(options.LinkName))
+	}
+	if options.MaxAge != 0 {
+		rotateOptions = append(rotateOptions, rotatelog.WithMaxAge(options.MaxAge))
+	}
+	if options.RotationTime != 0 {
+		rotateOptions = append(rotateOptions, rotatelog.WithRotationTime(options.RotationTime))
+	}
+	if options.RotationCount != 0 {
+		rotateOptions = append(rotateOptions, rotatelog.WithRotationCount(options.RotationCount))
+	}
+	hook, err := rotatelog.NewHook(options.Pattern, rotateOptions...)
+	if err != nil {
+		return nil, err
+	}
+	if formatter != nil {
+		hook.SetFormatter(formatter)
+	}
+	return hook, nil
+}
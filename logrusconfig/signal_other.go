@@ -0,0 +1,11 @@
+//go:build windows || nacl || plan9
+// +build windows nacl plan9
+
+package logrusconfig
+
+import "os"
+
+// SIGHUP isn't supported, the configuration is reloaded when it changes only.
+func notifyReload(c chan<- os.Signal) {}
+
+func stopReload(c chan<- os.Signal) {}
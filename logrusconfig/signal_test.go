@@ -0,0 +1,39 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package logrusconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+func TestWatchSIGHUP(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logrusconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "logging.yaml")
+	writeConfig(t, path, "level: debug", time.Now())
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	logger.Out = ioutil.Discard
+	stop, err := Watch(logger, path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return logger.IsLevelEnabled(logrus.DebugLevel) })
+}
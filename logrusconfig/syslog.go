@@ -0,0 +1,29 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package logrusconfig
+
+import (
+	"log/syslog"
+
+	"github.com/dorofeevsa/logrus"
+	logrus_syslog "github.com/dorofeevsa/logrus/hooks/syslog"
+)
+
+func init() {
+	RegisterHook("syslog", newSyslogHook)
+}
+
+// newSyslogHook builds a syslog hook, to the local syslog daemon unless an
+// address is given.
+func newSyslogHook(decode func(interface{}) error) (logrus.Hook, error) {
+	var options struct {
+		Network string `yaml:"network"`
+		Address string `yaml:"address"`
+		Tag     string `yaml:"tag"`
+	}
+	if err := decode(&options); err != nil {
+		return nil, err
+	}
+	return logrus_syslog.NewHook(options.Network, options.Address, syslog.LOG_INFO, options.Tag)
+}
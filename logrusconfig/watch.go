@@ -0,0 +1,73 @@
+package logrusconfig
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// Apply reconfigures logger atomically, with the level, formatter, outputs
+// and hooks configured by c, see logrus.Logger.Reconfigure. If c is invalid,
+// the logger is left as is.
+func (c *Config) Apply(logger *logrus.Logger) error {
+	next, err := c.Build()
+	if err != nil {
+		return err
+	}
+	return logger.Reconfigure(next)
+}
+
+// Watch applies the configuration file at path to logger again whenever it
+// changes, checked every interval, and on SIGHUP where supported. Errors
+// reloading it, e.g. of invalid configurations, which leave the logger as is,
+// are logged by logger. The returned function stops watching.
+func Watch(logger *logrus.Logger, path string, interval time.Duration) (stop func(), err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(interval)
+	reload := make(chan os.Signal, 1)
+	notifyReload(reload)
+	done := make(chan struct{})
+
+	go func() {
+		defer ticker.Stop()
+		defer stopReload(reload)
+
+		for {
+			select {
+			case <-ticker.C:
+				current, err := os.Stat(path)
+				if err != nil || (current.ModTime().Equal(info.ModTime()) && current.Size() == info.Size()) {
+					continue
+				}
+				info = current
+			case <-reload:
+			case <-done:
+				return
+			}
+			apply(logger, path)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}, nil
+}
+
+func apply(logger *logrus.Logger, path string) {
+	c, err := Load(path)
+	if err == nil {
+		err = c.Apply(logger)
+	}
+	if err != nil {
+		logger.WithError(err).WithField("path", path).Error("Failed to reload the logging configuration")
+		return
+	}
+	logger.WithField("path", path).Info("Reloaded the logging configuration")
+}
@@ -0,0 +1,139 @@
+package logrusconfig
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// syncBuffer is a buffer written by the watching goroutine.
+type syncBuffer struct {
+	mu sync.Mutex
+	bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Buffer.String()
+}
+
+// writeConfig writes the configuration at path, with a modification time
+// after the previous one.
+func writeConfig(t *testing.T, path, doc string, mtime time.Time) {
+	if err := ioutil.WriteFile(path, []byte(doc), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitFor waits for cond to be true, failing after a second.
+func waitFor(t *testing.T, cond func() bool) {
+	for deadline := time.Now().Add(time.Second); !cond(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &buffer
+
+	c, err := Parse([]byte("level: warn\nformatter: {type: json}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Apply(logger); err != nil {
+		t.Fatal(err)
+	}
+	if logger.IsLevelEnabled(logrus.InfoLevel) {
+		t.Error("expected the warn level")
+	}
+	if _, ok := logger.Formatter.(*logrus.JSONFormatter); !ok {
+		t.Errorf("expected a JSON formatter, got %#v", logger.Formatter)
+	}
+
+	c, err = Parse([]byte("level: debug\nformatter: {type: xml}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Apply(logger); err == nil {
+		t.Error("expected an error")
+	}
+	if logger.IsLevelEnabled(logrus.InfoLevel) {
+		t.Error("expected an invalid configuration to leave the logger as is")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logrusconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "logging.yaml")
+	mtime := time.Now().Add(-time.Hour)
+	writeConfig(t, path, "level: warn", mtime)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	logger.Out = ioutil.Discard
+	stop, err := Watch(logger, path, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	writeConfig(t, path, "level: debug", mtime.Add(time.Second))
+	waitFor(t, func() bool { return logger.IsLevelEnabled(logrus.DebugLevel) })
+}
+
+func TestWatchInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logrusconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "logging.yaml")
+	mtime := time.Now().Add(-time.Hour)
+	writeConfig(t, path, "level: warn", mtime)
+
+	buffer := &syncBuffer{}
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	logger.Out = buffer
+	stop, err := Watch(logger, path, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	writeConfig(t, path, "level: loud", mtime.Add(time.Second))
+	waitFor(t, func() bool { return strings.Contains(buffer.String(), "Failed to reload") })
+	if !logger.IsLevelEnabled(logrus.WarnLevel) || logger.IsLevelEnabled(logrus.InfoLevel) {
+		t.Error("expected an invalid configuration to leave the logger as is")
+	}
+}
+
+func TestWatchMissingFile(t *testing.T) {
+	if _, err := Watch(logrus.New(), "missing.yaml", time.Second); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,43 @@
+package logrus
+
+// Middleware transforms entries before hooks and formatters see them, e.g. to
+// add, rename or drop fields. It may modify the entry it is passed, whose
+// fields are a copy, and returns the entry to log, or nil to drop it.
+type Middleware func(*Entry) *Entry
+
+// AddMiddleware adds middleware to the logger, run after the middlewares
+// added before it:
+//
+//	log.AddMiddleware(func(entry *logrus.Entry) *logrus.Entry {
+//		if entry.Message == "health check" {
+//			return nil
+//		}
+//		entry.Data["service"] = "api"
+//		return entry
+//	})
+//
+// Middlewares run after sampling and rate limiting, and before redaction and
+// scrubbing. Panic entries dropped by a middleware still panic.
+func (logger *Logger) AddMiddleware(middleware Middleware) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	logger.middlewares = append(logger.middlewares, middleware)
+}
+
+// runMiddlewares returns the entry transformed by middlewares, or nil if one
+// dropped it.
+func (entry *Entry) runMiddlewares(middlewares []Middleware) *Entry {
+	data := make(Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	entry.Data = data
+
+	for _, middleware := range middlewares {
+		if entry = middleware(entry); entry == nil {
+			return nil
+		}
+	}
+	return entry
+}
@@ -0,0 +1,66 @@
+package logrus
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = new(JSONFormatter)
+	log.AddMiddleware(func(entry *Entry) *Entry {
+		if entry.Message == "health check" {
+			return nil
+		}
+		entry.Data["service"] = "api"
+		return entry
+	})
+	log.AddMiddleware(func(entry *Entry) *Entry {
+		if user, ok := entry.Data["usr"]; ok {
+			delete(entry.Data, "usr")
+			entry.Data["user"] = user
+		}
+		entry.Message = entry.Message + "!"
+		return entry
+	})
+
+	entry := log.WithField("usr", "bob")
+	entry.Info("health check")
+	entry.Info("login")
+
+	lines := logLines(t, &buffer)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, "login!", lines[0]["msg"])
+	assert.Equal(t, "api", lines[0]["service"])
+	assert.Equal(t, "bob", lines[0]["user"])
+	assert.NotContains(t, lines[0], "usr")
+	assert.Equal(t, Fields{"usr": "bob"}, entry.Data)
+}
+
+func TestMiddlewareDropPanic(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.AddMiddleware(func(entry *Entry) *Entry {
+		return nil
+	})
+
+	assert.Panics(t, func() { log.Panic("test") })
+	assert.Empty(t, buffer.String())
+}
+
+func TestScrubberMiddleware(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = new(JSONFormatter)
+	scrubber, _ := NewScrubber(ScrubEmails)
+	log.AddMiddleware(scrubber.Scrub)
+
+	log.Info("mail from bob@example.com")
+	assert.Equal(t, "mail from [EMAIL]", logLines(t, &buffer)[0]["msg"])
+}
@@ -0,0 +1,296 @@
+package logrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// MsgpackFormatter formats logs into MessagePack maps of the time, level,
+// message and fields, which is cheaper to encode and ship than JSON. With a
+// Tag, entries are Fluentd forward protocol messages `[tag, time, record]`
+// for Fluentd compatible consumers.
+type MsgpackFormatter struct {
+	// TimestampFormat sets the format used for marshaling timestamps.
+	TimestampFormat string
+
+	// DisableTimestamp allows disabling automatic timestamps in output
+	DisableTimestamp bool
+
+	// FieldMap allows users to customize the names of keys for default fields.
+	FieldMap FieldMap
+
+	// DataKey allows users to put all the log entry parameters into a nested
+	// dictionary at a given key.
+	DataKey string
+
+	// SortingFunc replaces the alphabetical sorting of the keys.
+	SortingFunc func([]string)
+
+	// PriorityKeys are encoded before the other keys, in their order.
+	PriorityKeys []string
+
+	// Tag makes entries Fluentd forward protocol messages with the tag.
+	Tag string
+}
+
+// Format renders a single log entry
+func (f *MsgpackFormatter) Format(entry *Entry) ([]byte, error) {
+	data := make(Fields, len(entry.Data)+3)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+
+	if f.DataKey != "" {
+		data = Fields{f.DataKey: data}
+	}
+	prefixFieldClashes(data, f.FieldMap, entry.HasCaller())
+
+	timestampFormat := f.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = defaultTimestampFormat
+	}
+
+	if !f.DisableTimestamp {
+		data[f.FieldMap.resolve(FieldKeyTime)] = entry.Time.Format(timestampFormat)
+	}
+	data[f.FieldMap.resolve(FieldKeyMsg)] = entry.Message
+	data[f.FieldMap.resolve(FieldKeyLevel)] = entry.Level.String()
+	if entry.HasCaller() {
+		function, file := entry.callerInfo()
+		data[f.FieldMap.resolve(FieldKeyFunc)] = function
+		data[f.FieldMap.resolve(FieldKeyFile)] = file
+	}
+
+	b := entryBuffer(entry)
+	e := msgpackEncoder{buf: b.Bytes()}
+	if f.Tag != "" {
+		e.writeArrayHeader(3)
+		e.writeString(f.Tag)
+		e.writeInt(entry.Time.Unix())
+	}
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sortKeys(keys, f.PriorityKeys, f.SortingFunc)
+	if err := e.writeMapKeys(data, keys); err != nil {
+		return nil, fmt.Errorf("Failed to marshal fields to MessagePack, %v", err)
+	}
+	// keep the buffer grown by the encoder in the pool
+	*b = *bytes.NewBuffer(e.buf)
+	return e.buf, nil
+}
+
+// msgpackEncoder appends MessagePack encoded values to buf.
+type msgpackEncoder struct {
+	buf []byte
+}
+
+func (e *msgpackEncoder) writeValue(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		e.buf = append(e.buf, 0xc0)
+	case bool:
+		if v {
+			e.buf = append(e.buf, 0xc3)
+		} else {
+			e.buf = append(e.buf, 0xc2)
+		}
+	case int:
+		e.writeInt(int64(v))
+	case int8:
+		e.writeInt(int64(v))
+	case int16:
+		e.writeInt(int64(v))
+	case int32:
+		e.writeInt(int64(v))
+	case int64:
+		e.writeInt(v)
+	case uint:
+		e.writeUint(uint64(v))
+	case uint8:
+		e.writeUint(uint64(v))
+	case uint16:
+		e.writeUint(uint64(v))
+	case uint32:
+		e.writeUint(uint64(v))
+	case uint64:
+		e.writeUint(v)
+	case float32:
+		e.buf = append(e.buf, 0xca)
+		e.buf = appendUint32(e.buf, math.Float32bits(v))
+	case float64:
+		e.buf = append(e.buf, 0xcb)
+		e.buf = appendUint64(e.buf, math.Float64bits(v))
+	case string:
+		e.writeString(v)
+	case []byte:
+		e.writeBytes(v)
+	case error:
+		e.writeString(v.Error())
+	case time.Time:
+		e.writeString(v.Format(time.RFC3339Nano))
+	case Fields:
+		return e.writeMap(v)
+	case map[string]interface{}:
+		return e.writeMap(v)
+	case []interface{}:
+		e.writeArrayHeader(len(v))
+		for _, item := range v {
+			if err := e.writeValue(item); err != nil {
+				return err
+			}
+		}
+	default:
+		// encode other types like encoding/json would
+		generic, err := toGeneric(v)
+		if err != nil {
+			return err
+		}
+		return e.writeValue(generic)
+	}
+	return nil
+}
+
+// writeMap writes m with its keys sorted, for a deterministic output.
+func (e *msgpackEncoder) writeMap(m map[string]interface{}) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return e.writeMapKeys(m, keys)
+}
+
+// writeMapKeys writes m with its keys in the order of keys.
+func (e *msgpackEncoder) writeMapKeys(m map[string]interface{}, keys []string) error {
+	switch n := len(m); {
+	case n < 16:
+		e.buf = append(e.buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xde)
+		e.buf = appendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdf)
+		e.buf = appendUint32(e.buf, uint32(n))
+	}
+
+	for _, k := range keys {
+		e.writeString(k)
+		if err := e.writeValue(m[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) writeArrayHeader(n int) {
+	switch {
+	case n < 16:
+		e.buf = append(e.buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xdc)
+		e.buf = appendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdd)
+		e.buf = appendUint32(e.buf, uint32(n))
+	}
+}
+
+func (e *msgpackEncoder) writeString(s string) {
+	switch n := len(s); {
+	case n < 32:
+		e.buf = append(e.buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xda)
+		e.buf = appendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdb)
+		e.buf = appendUint32(e.buf, uint32(n))
+	}
+	e.buf = append(e.buf, s...)
+}
+
+func (e *msgpackEncoder) writeBytes(b []byte) {
+	switch n := len(b); {
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xc5)
+		e.buf = appendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xc6)
+		e.buf = appendUint32(e.buf, uint32(n))
+	}
+	e.buf = append(e.buf, b...)
+}
+
+func (e *msgpackEncoder) writeInt(i int64) {
+	switch {
+	case i >= 0:
+		e.writeUint(uint64(i))
+	case i >= -32:
+		e.buf = append(e.buf, byte(i))
+	case i >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		e.buf = append(e.buf, 0xd1)
+		e.buf = appendUint16(e.buf, uint16(i))
+	case i >= math.MinInt32:
+		e.buf = append(e.buf, 0xd2)
+		e.buf = appendUint32(e.buf, uint32(i))
+	default:
+		e.buf = append(e.buf, 0xd3)
+		e.buf = appendUint64(e.buf, uint64(i))
+	}
+}
+
+func (e *msgpackEncoder) writeUint(u uint64) {
+	switch {
+	case u < 128:
+		e.buf = append(e.buf, byte(u))
+	case u <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		e.buf = append(e.buf, 0xcd)
+		e.buf = appendUint16(e.buf, uint16(u))
+	case u <= math.MaxUint32:
+		e.buf = append(e.buf, 0xce)
+		e.buf = appendUint32(e.buf, uint32(u))
+	default:
+		e.buf = append(e.buf, 0xcf)
+		e.buf = appendUint64(e.buf, u)
+	}
+}
+
+// toGeneric converts v to the maps, slices and scalars encoding/json decodes
+// its JSON encoding to, so binary formatters encode any value like the
+// JSONFormatter does.
+func toGeneric(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	err = json.Unmarshal(b, &generic)
+	return generic, err
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return appendUint32(appendUint32(b, uint32(v>>32)), uint32(v))
+}
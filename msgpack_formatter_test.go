@@ -0,0 +1,81 @@
+package logrus
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMsgpackFormatter(t *testing.T) {
+	formatter := &MsgpackFormatter{DisableTimestamp: true}
+
+	entry := WithFields(Fields{"n": -5, "user": "al"})
+	entry.Level = InfoLevel
+	entry.Message = "hi"
+
+	b, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	expected := []byte{0x84,
+		0xa5, 'l', 'e', 'v', 'e', 'l', 0xa4, 'i', 'n', 'f', 'o',
+		0xa3, 'm', 's', 'g', 0xa2, 'h', 'i',
+		0xa1, 'n', 0xfb,
+		0xa4, 'u', 's', 'e', 'r', 0xa2, 'a', 'l',
+	}
+	if !bytes.Equal(b, expected) {
+		t.Errorf("Expected % x, got % x", expected, b)
+	}
+}
+
+func TestMsgpackFormatterTag(t *testing.T) {
+	formatter := &MsgpackFormatter{DisableTimestamp: true, Tag: "app"}
+
+	entry := NewEntry(New())
+	entry.Time = time.Unix(1514862245, 0)
+	entry.Level = InfoLevel
+	entry.Message = "hi"
+
+	b, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	expected := []byte{0x93, 0xa3, 'a', 'p', 'p', 0xce, 0x5a, 0x4a, 0xf6, 0xa5, 0x82}
+	if !bytes.HasPrefix(b, expected) {
+		t.Errorf("Expected % x to start with % x", b, expected)
+	}
+}
+
+func TestMsgpackValues(t *testing.T) {
+	for _, test := range []struct {
+		value    interface{}
+		expected []byte
+	}{
+		{nil, []byte{0xc0}},
+		{true, []byte{0xc3}},
+		{127, []byte{0x7f}},
+		{200, []byte{0xcc, 0xc8}},
+		{-100, []byte{0xd0, 0x9c}},
+		{int64(math.MinInt32), []byte{0xd2, 0x80, 0, 0, 0}},
+		{uint64(math.MaxUint32) + 1, []byte{0xcf, 0, 0, 0, 1, 0, 0, 0, 0}},
+		{1.5, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{[]byte{1, 2}, []byte{0xc4, 2, 1, 2}},
+		{errors.New("e"), []byte{0xa1, 'e'}},
+		{[]interface{}{1, "a"}, []byte{0x92, 0x01, 0xa1, 'a'}},
+		{struct {
+			A int `json:"a"`
+		}{1}, []byte{0x81, 0xa1, 'a', 0xcb, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0}},
+	} {
+		var e msgpackEncoder
+		if err := e.writeValue(test.value); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(e.buf, test.expected) {
+			t.Errorf("Expected %v to encode to % x, got % x", test.value, test.expected, e.buf)
+		}
+	}
+}
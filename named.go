@@ -0,0 +1,76 @@
+package logrus
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	namedMu sync.Mutex
+	// named loggers by name, the standard logger is the root named ""
+	namedLoggers = map[string]*Logger{}
+)
+
+// GetLogger returns the logger named name, creating it if needed. Names are
+// dot separated paths like "db.pool", whose parent is "db"; the parent of
+// top-level names is the standard logger. New loggers inherit the output,
+// formatter, hooks and level of their parent, so subsystems can be
+// configured together:
+//
+//	log := logrus.GetLogger("db.pool")
+//	logrus.SetLoggerLevel("db", logrus.DebugLevel)
+//
+// Hooks added to a logger later aren't added to its existing children.
+func GetLogger(name string) *Logger {
+	namedMu.Lock()
+	defer namedMu.Unlock()
+
+	return getLogger(name)
+}
+
+// Must be called with namedMu held.
+func getLogger(name string) *Logger {
+	if name == "" {
+		return std
+	}
+	if logger, ok := namedLoggers[name]; ok {
+		return logger
+	}
+
+	parent := std
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		parent = getLogger(name[:i])
+	}
+
+	parent.mu.Lock()
+	hooks := make(LevelHooks, len(parent.Hooks))
+	for level, levelHooks := range parent.Hooks {
+		hooks[level] = append([]Hook(nil), levelHooks...)
+	}
+	logger := &Logger{
+		Out:       parent.Out,
+		Formatter: parent.Formatter,
+		Hooks:     hooks,
+		Level:     parent.level(),
+	}
+	parent.mu.Unlock()
+
+	namedLoggers[name] = logger
+	return logger
+}
+
+// SetLoggerLevel sets the level of the logger named prefix and all its
+// descendants, e.g. "db" sets the level of "db" and "db.pool" but not
+// "dbx". The empty prefix sets the level of all named loggers and the
+// standard logger.
+func SetLoggerLevel(prefix string, level Level) {
+	namedMu.Lock()
+	defer namedMu.Unlock()
+
+	getLogger(prefix).SetLevel(level)
+	for name, logger := range namedLoggers {
+		if prefix == "" || strings.HasPrefix(name, prefix+".") {
+			logger.SetLevel(level)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package logrus
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLogger(t *testing.T) {
+	assert.True(t, GetLogger("") == std)
+
+	pool := GetLogger("test.named.pool")
+	assert.True(t, GetLogger("test.named.pool") == pool)
+	assert.True(t, GetLogger("test.named") != pool)
+}
+
+func TestGetLoggerInherits(t *testing.T) {
+	var buffer bytes.Buffer
+	parent := GetLogger("test.inherit")
+	parent.Out = &buffer
+	parent.Formatter = new(JSONFormatter)
+	parent.SetLevel(WarnLevel)
+	hook := new(TestHook)
+	parent.AddHook(hook)
+
+	child := GetLogger("test.inherit.child")
+	assert.Equal(t, WarnLevel, child.level())
+
+	child.Warn("test")
+	assert.Contains(t, buffer.String(), `"msg":"test"`)
+	assert.True(t, hook.Fired)
+
+	child.AddHook(new(TestHook))
+	assert.Len(t, parent.Hooks[WarnLevel], 1)
+}
+
+func TestSetLoggerLevel(t *testing.T) {
+	db := GetLogger("test.db")
+	pool := GetLogger("test.db.pool")
+	other := GetLogger("test.dbx")
+
+	SetLoggerLevel("test.db", DebugLevel)
+	assert.Equal(t, DebugLevel, db.level())
+	assert.Equal(t, DebugLevel, pool.level())
+	assert.Equal(t, InfoLevel, other.level())
+
+	// later children inherit the level
+	assert.Equal(t, DebugLevel, GetLogger("test.db.conn").level())
+}
@@ -0,0 +1,18 @@
+package logrus
+
+import "io/ioutil"
+
+// NewNop returns a logger which logs nothing, whatever its level, hooks or
+// output, cheaply: its logging methods and entries don't allocate, besides
+// the arguments the compiler allocates for them. It suits libraries taking a
+// logger, silent by default. Fatal still exits and Panic still panics.
+func NewNop() *Logger {
+	logger := &Logger{
+		Out:       ioutil.Discard,
+		Formatter: new(TextFormatter),
+		Hooks:     make(LevelHooks),
+		Level:     PanicLevel,
+	}
+	logger.nop = &Entry{Logger: logger, disabled: true}
+	return logger
+}
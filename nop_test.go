@@ -0,0 +1,56 @@
+package logrus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNop(t *testing.T) {
+	logger := NewNop()
+	hook := &TestHook{}
+	logger.AddHook(hook)
+	logger.SetLevel(DebugLevel)
+
+	logger.WithField("key", "value").Error("error")
+	logger.Debug("debug")
+	assert.False(t, hook.Fired)
+	assert.False(t, logger.IsLevelEnabled(PanicLevel))
+	assert.Panics(t, func() { logger.Panic("panic") })
+
+	exits := logger.RecordExits()
+	logger.Fatal("fatal")
+	_, exited := exits.Exited()
+	assert.True(t, exited)
+	assert.False(t, hook.Fired)
+}
+
+func TestNopDoesNotAllocate(t *testing.T) {
+	logger := NewNop()
+	fields := Fields{"a": 1}
+	err := errors.New("failed")
+	ctx := context.Background()
+	str, n := "str", 1234567
+
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Errorf("aaa %s %d", str, n)
+		logger.Info("aaa", str, n)
+		logger.WithField("a", str).Warn("aaa")
+		logger.WithFields(fields).WithError(err).Error("aaa")
+		logger.WithContext(ctx).Infof("aaa %s %d", str, n)
+		logger.IfDebug().WithField("a", n).Debug("aaa")
+		logger.Log(testNoticeLevel, "aaa", str, n)
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func BenchmarkNop(b *testing.B) {
+	logger := NewNop()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.WithField("i", i).Info("message")
+	}
+}
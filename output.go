@@ -0,0 +1,40 @@
+package logrus
+
+import "io"
+
+// output is a writer added to a logger with AddOutput.
+type output struct {
+	writer    io.Writer
+	level     Level
+	formatter Formatter
+}
+
+// AddOutput makes the logger also write the entries at least as severe as
+// minLevel to w, formatted by formatter, or by the logger's formatter if nil,
+// e.g. colored text to stdout and JSON with debug entries to a file:
+//
+//	log.Out = nil
+//	log.SetLevel(logrus.DebugLevel)
+//	log.AddOutput(os.Stdout, logrus.InfoLevel, &logrus.TextFormatter{})
+//	log.AddOutput(file, logrus.DebugLevel, &logrus.JSONFormatter{})
+//
+// The logger's level applies first, so entries less severe than it aren't
+// written to any output. Out may be nil to write to the outputs only.
+func (logger *Logger) AddOutput(w io.Writer, minLevel Level, formatter Formatter) {
+	if f, ok := formatter.(*TextFormatter); ok {
+		// colors depend on the output, not on Out
+		f.Do(func() { f.isTerminal = checkIfTerminal(w) })
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	// copied on write, so entries can write to the outputs without the lock
+	outputs := make([]*output, len(logger.outputs), len(logger.outputs)+1)
+	copy(outputs, logger.outputs)
+	logger.outputs = append(outputs, &output{writer: w, level: minLevel, formatter: formatter})
+}
+
+func (o *output) enabled(level Level) bool {
+	return level.rank() <= o.level.rank()
+}
@@ -0,0 +1,70 @@
+package logrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddOutput(t *testing.T) {
+	var out, text, jsonOut bytes.Buffer
+	log := New()
+	log.Out = &out
+	log.Formatter = &TextFormatter{DisableColors: true, DisableTimestamp: true}
+	log.SetLevel(DebugLevel)
+	log.AddOutput(&text, WarnLevel, nil)
+	log.AddOutput(&jsonOut, DebugLevel, &JSONFormatter{})
+
+	log.Debug("debug")
+	log.WithField("key", "value").Warn("warn")
+
+	assert.Equal(t, "level=debug msg=debug\nlevel=warning msg=warn key=value\n", out.String())
+	assert.Equal(t, "level=warning msg=warn key=value\n", text.String())
+
+	lines := strings.Split(strings.TrimSpace(jsonOut.String()), "\n")
+	assert.Len(t, lines, 2)
+	var fields Fields
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &fields))
+	assert.Equal(t, "warn", fields["msg"])
+	assert.Equal(t, "value", fields["key"])
+}
+
+func TestAddOutputWithoutOut(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = nil
+	log.AddOutput(&buffer, InfoLevel, &JSONFormatter{})
+
+	log.Info("only output")
+	fields := logLines(t, &buffer)
+	assert.Len(t, fields, 1)
+	assert.Equal(t, "only output", fields[0]["msg"])
+}
+
+func TestAddOutputLoggerLevelAppliesFirst(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = nil
+	log.SetLevel(InfoLevel)
+	log.AddOutput(&buffer, DebugLevel, &JSONFormatter{})
+
+	log.Debug("filtered")
+	assert.Empty(t, buffer.String())
+}
+
+func TestAddOutputCustomLevel(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = nil
+	log.SetLevel(DebugLevel)
+	log.AddOutput(&buffer, WarnLevel, &JSONFormatter{})
+
+	log.Log(testNoticeLevel, "notice")
+	log.Log(WarnLevel, "warn")
+	fields := logLines(t, &buffer)
+	assert.Len(t, fields, 1)
+	assert.Equal(t, "warn", fields[0]["msg"])
+}
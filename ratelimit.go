@@ -0,0 +1,67 @@
+package logrus
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket limiting the entries of a logger, see
+// Logger.SetRateLimit.
+type rateLimiter struct {
+	mu         sync.Mutex
+	rate       float64
+	burst      float64
+	tokens     float64
+	last       time.Time
+	suppressed uint64
+}
+
+// SetRateLimit limits the entries logged to eventsPerSecond, allowing bursts
+// of up to burst entries, so a noisy component can't starve others sharing
+// the output or hooks. Entries over the limit are dropped before hooks and
+// the formatter run; the next entry logged is preceded by a warning with the
+// number of suppressed entries in the `suppressed` field. Fatal and Panic
+// entries are never dropped. A non-positive eventsPerSecond removes the
+// limit.
+func (logger *Logger) SetRateLimit(eventsPerSecond float64, burst int) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	if eventsPerSecond <= 0 {
+		logger.limiter = nil
+		return
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	logger.limiter = &rateLimiter{
+		rate:   eventsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// allow reports whether an entry logged at now is within the limit, and
+// returns the number of entries suppressed since the last one allowed.
+func (l *rateLimiter) allow(now time.Time) (uint64, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		l.suppressed++
+		return 0, false
+	}
+	l.tokens--
+
+	suppressed := l.suppressed
+	l.suppressed = 0
+	return suppressed, true
+}
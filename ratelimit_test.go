@@ -0,0 +1,65 @@
+package logrus
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = new(JSONFormatter)
+	log.SetRateLimit(0.001, 2)
+
+	for i := 0; i < 5; i++ {
+		log.Info("noisy")
+	}
+
+	lines := logLines(t, &buffer)
+	assert.Len(t, lines, 2)
+}
+
+func TestRateLimitSummary(t *testing.T) {
+	l := &rateLimiter{rate: 10, burst: 1, tokens: 1}
+	now := time.Now()
+
+	_, ok := l.allow(now)
+	assert.True(t, ok)
+	_, ok = l.allow(now)
+	assert.False(t, ok)
+	_, ok = l.allow(now.Add(50 * time.Millisecond))
+	assert.False(t, ok)
+
+	suppressed, ok := l.allow(now.Add(100 * time.Millisecond))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), suppressed)
+}
+
+func TestRateLimitSummaryEntry(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = new(JSONFormatter)
+	log.SetRateLimit(1, 1)
+	log.limiter.suppressed = 3
+
+	log.Info("allowed")
+
+	lines := logLines(t, &buffer)
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, float64(3), lines[0]["suppressed"])
+		assert.Equal(t, "warning", lines[0]["level"])
+		assert.Equal(t, "allowed", lines[1]["msg"])
+	}
+}
+
+func TestRateLimitRemoved(t *testing.T) {
+	log := New()
+	log.SetRateLimit(1, 1)
+	log.SetRateLimit(0, 0)
+	assert.Nil(t, log.limiter)
+}
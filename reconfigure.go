@@ -0,0 +1,54 @@
+package logrus
+
+import "io"
+
+// Reconfigure atomically replaces the level, caller reporting, formatter, Out,
+// outputs and hooks of the logger by those of next, a logger only built to
+// hold them, e.g. from a configuration file reloaded at runtime. Entries are
+// either logged with the old configuration or with the new one. The entries
+// queued for async hooks are fired first. The replaced hooks and writers
+// which next doesn't use are closed once the entries being written to them
+// are, and the errors of closing them returned. next mustn't be used after.
+func (logger *Logger) Reconfigure(next *Logger) error {
+	next.mu.Lock()
+	level, reportCaller, formatter := next.level(), next.ReportCaller, next.Formatter
+	out, outputs, hooks := next.Out, next.outputs, next.Hooks
+	next.mu.Unlock()
+
+	logger.mu.Lock()
+	if logger.asyncHooks != nil {
+		// the workers are per hook, so they're started again for the new ones
+		logger.asyncHooks.stop()
+		logger.asyncHooks.workers = map[Hook]*hookWorker{}
+	}
+	oldHooks, oldWriters := logger.Hooks, logger.writers()
+	logger.SetLevel(level)
+	logger.ReportCaller = reportCaller
+	logger.Formatter = formatter
+	logger.Out, logger.outputs, logger.Hooks = out, outputs, hooks
+	kept := logger.writers()
+	logger.mu.Unlock()
+
+	// entries being written may still use the old writers
+	logger.writes.Lock()
+	logger.writes.Unlock()
+
+	// closing what next uses too would break the logger
+	var c closer
+	for _, levelHooks := range hooks {
+		for _, hook := range levelHooks {
+			c.keep(hook)
+		}
+	}
+	for _, w := range kept {
+		c.keep(w)
+	}
+
+	oldHooks.close(&c)
+	for _, w := range oldWriters {
+		if wc, ok := w.(io.Closer); ok {
+			c.close(wc)
+		}
+	}
+	return c.err()
+}
@@ -0,0 +1,144 @@
+package logrus
+
+import (
+	"bytes"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconfigure(t *testing.T) {
+	oldHook, keptHook, newHook := &closingHook{}, &closingHook{}, &closingHook{}
+	oldOut, newOut := &closingWriter{}, &closingWriter{}
+	log := New()
+	log.Out = oldOut
+	log.AddHook(oldHook)
+	log.AddHook(keptHook)
+
+	next := New()
+	next.Out = nil
+	next.SetLevel(DebugLevel)
+	next.ReportCaller = true
+	next.Formatter = &JSONFormatter{}
+	next.AddOutput(newOut, InfoLevel, nil)
+	next.AddHook(keptHook)
+	next.AddHook(newHook)
+
+	assert.NoError(t, log.Reconfigure(next))
+	assert.Equal(t, 1, oldHook.closes)
+	assert.Equal(t, 1, oldOut.closes)
+	assert.Equal(t, 0, keptHook.closes)
+	assert.Equal(t, 0, newHook.closes)
+	assert.Equal(t, 0, newOut.closes)
+
+	log.Debug("filtered by the output")
+	log.Info("reconfigured")
+	assert.Empty(t, oldOut.String())
+	fields := logLines(t, &newOut.Buffer)
+	if assert.Len(t, fields, 1) {
+		assert.Equal(t, "reconfigured", fields[0]["msg"])
+		assert.Contains(t, fields[0], FieldKeyFunc)
+	}
+	assert.Equal(t, DebugLevel, log.level())
+	assert.True(t, newHook.Fired)
+}
+
+func TestReconfigureAsyncHooks(t *testing.T) {
+	oldHook := &blockingHook{release: make(chan struct{})}
+	close(oldHook.release)
+	newHook := &blockingHook{release: make(chan struct{})}
+	close(newHook.release)
+	log := New()
+	log.Out = &bytes.Buffer{}
+	log.AddHook(oldHook)
+	log.SetAsyncHooks(10)
+	defer log.SetAsyncHooks(0)
+
+	log.Info("queued")
+	next := New()
+	next.Out = &bytes.Buffer{}
+	next.AddHook(newHook)
+	assert.NoError(t, log.Reconfigure(next))
+	assert.Equal(t, []string{"queued"}, oldHook.Messages())
+
+	log.Info("new")
+	log.SetAsyncHooks(0)
+	assert.Equal(t, []string{"queued"}, oldHook.Messages())
+	assert.Equal(t, []string{"new"}, newHook.Messages())
+}
+
+// countingWriter counts the entries written to it, and the ones written after
+// it's closed.
+type countingWriter struct {
+	mu      sync.Mutex
+	entries int
+	closed  bool
+	lost    int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		w.lost++
+		return 0, os.ErrClosed
+	}
+	w.entries++
+	return len(p), nil
+}
+
+func (w *countingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return nil
+}
+
+func TestReconfigureConcurrently(t *testing.T) {
+	newLogger := func(w *countingWriter) *Logger {
+		logger := New()
+		logger.Out = nil
+		logger.AddOutput(w, InfoLevel, nil)
+		return logger
+	}
+	writers := []*countingWriter{{}}
+	log := newLogger(writers[0])
+
+	const goroutines, entries = 4, 20000
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < entries; j++ {
+				log.Info("test")
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+reloading:
+	for {
+		select {
+		case <-done:
+			break reloading
+		default:
+		}
+		w := &countingWriter{}
+		writers = append(writers, w)
+		assert.NoError(t, log.Reconfigure(newLogger(w)))
+	}
+
+	written := 0
+	for _, w := range writers {
+		assert.Zero(t, w.lost)
+		written += w.entries
+	}
+	assert.Equal(t, goroutines*entries, written)
+}
@@ -0,0 +1,81 @@
+package logrus
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// FieldKeyPanic is the key of the value of recovered panics.
+const FieldKeyPanic = "panic"
+
+// RecoverAndLog calls fn, logging a panic in it at PanicLevel, with its value
+// and stack, instead of crashing. It panics again after logging if the
+// logger's RepanicRecovered is set.
+func RecoverAndLog(logger *Logger, fn func()) {
+	NewEntry(logger).RecoverAndLog(fn)
+}
+
+// RecoverAndLog calls fn, logging a panic in it with the entry's fields, see
+// the RecoverAndLog function.
+func (entry *Entry) RecoverAndLog(fn func()) {
+	defer entry.recoverAndLog()
+	fn()
+}
+
+// Go runs fn in a new goroutine, logging its panics, see RecoverAndLog.
+func Go(logger *Logger, fn func()) {
+	NewEntry(logger).Go(fn)
+}
+
+// Go runs fn in a new goroutine, logging its panics with the entry's fields,
+// see RecoverAndLog.
+func (entry *Entry) Go(fn func()) {
+	go entry.RecoverAndLog(fn)
+}
+
+// RecoverHandler returns a handler calling next, logging its panics with the
+// method, URL and remote address of the request, in its context, and
+// responding with a 500 Internal Server Error, see RecoverAndLog. The
+// http.ErrAbortHandler panic, which aborts a response, isn't logged.
+func RecoverHandler(logger *Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if p := recover(); p != nil {
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+				logger.WithContext(r.Context()).WithFields(Fields{
+					"method":      r.Method,
+					"url":         r.URL.String(),
+					"remote_addr": r.RemoteAddr,
+				}).logPanic(p)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// recoverAndLog logs a panic of the calling goroutine. It must be deferred.
+func (entry *Entry) recoverAndLog() {
+	if p := recover(); p != nil {
+		entry.logPanic(p)
+	}
+}
+
+// logPanic logs the recovered value p at PanicLevel, panicking again if the
+// logger's RepanicRecovered is set.
+func (entry *Entry) logPanic(p interface{}) {
+	entry.Logger.mu.Lock()
+	depth, repanic := entry.Logger.StackDepth, entry.Logger.RepanicRecovered
+	entry.Logger.mu.Unlock()
+
+	entry.WithFields(Fields{
+		FieldKeyPanic: p,
+		FieldKeyStack: panicStack(depth),
+	}).logNoPanic(PanicLevel, fmt.Sprintf("Recovered from panic: %v", p))
+
+	if repanic {
+		panic(p)
+	}
+}
@@ -0,0 +1,80 @@
+package logrus
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func panicky() {
+	var m map[string]int
+	m["a"] = 1
+}
+
+func TestRecoverAndLog(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = new(JSONFormatter)
+
+	assert.NotPanics(t, func() {
+		log.WithField("job", "sync").RecoverAndLog(panicky)
+	})
+
+	fields := logLines(t, &buffer)[0]
+	assert.Equal(t, "panic", fields["level"])
+	assert.Equal(t, "sync", fields["job"])
+	assert.Equal(t, "assignment to entry in nil map", fields["panic"])
+	assert.Contains(t, fields["msg"], "Recovered from panic: assignment to entry in nil map")
+	stack := fields["stack"].([]interface{})
+	assert.True(t, strings.HasPrefix(stack[0].(string), "github.com/dorofeevsa/logrus.panicky "), "%v", stack[0])
+}
+
+func TestRecoverAndLogRepanic(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.RepanicRecovered = true
+
+	assert.PanicsWithValue(t, "boom", func() {
+		RecoverAndLog(log, func() { panic("boom") })
+	})
+	assert.Contains(t, buffer.String(), "Recovered from panic: boom")
+}
+
+func TestGo(t *testing.T) {
+	cw := channelWriter(make(chan []byte, 1))
+	log := New()
+	log.Out = cw
+
+	Go(log, func() { panic("boom") })
+	assert.Contains(t, string(<-cw), "Recovered from panic: boom")
+}
+
+func TestRecoverHandler(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = new(JSONFormatter)
+
+	handler := RecoverHandler(log, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/orders?id=1", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	fields := logLines(t, &buffer)[0]
+	assert.Equal(t, "GET", fields["method"])
+	assert.Equal(t, "/orders?id=1", fields["url"])
+	assert.Equal(t, "boom", fields["panic"])
+
+	abort := RecoverHandler(log, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	assert.Panics(t, func() { abort.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil)) })
+}
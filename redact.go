@@ -0,0 +1,125 @@
+package logrus
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// DefaultRedactionReplacement replaces the values of redacted fields.
+const DefaultRedactionReplacement = "[REDACTED]"
+
+// Redaction selects the fields whose values are redacted, see
+// Logger.SetRedaction.
+type Redaction struct {
+	// Keys redacted, compared case-insensitively
+	Keys []string
+	// Patterns of keys redacted, as in path.Match, e.g. `*_token`
+	Globs []string
+	// Regular expressions matching keys redacted, e.g. `password|token|secret`
+	Patterns []string
+	// Value of redacted fields, DefaultRedactionReplacement by default
+	Replacement string
+}
+
+// redactor replaces the values of fields matching a Redaction.
+type redactor struct {
+	keys        []string
+	globs       []string
+	patterns    []*regexp.Regexp
+	replacement string
+}
+
+// SetRedaction makes the logger replace the values of fields whose keys match
+// redaction, also in nested Fields, before hooks and formatters see them, so
+// secrets don't leak to files or other services:
+//
+//	log.SetRedaction(logrus.Redaction{
+//		Keys:     []string{"authorization"},
+//		Patterns: []string{`(?i)password|token|secret`},
+//	})
+//
+// It returns an error, keeping the previous redaction, if a glob or pattern
+// is invalid. An empty Redaction disables redaction.
+func (logger *Logger) SetRedaction(redaction Redaction) error {
+	r := &redactor{
+		keys:        redaction.Keys,
+		globs:       redaction.Globs,
+		replacement: redaction.Replacement,
+	}
+	if r.replacement == "" {
+		r.replacement = DefaultRedactionReplacement
+	}
+	for _, glob := range redaction.Globs {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid redaction glob %q, %v", glob, err)
+		}
+	}
+	for _, pattern := range redaction.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid redaction pattern %q, %v", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	if len(r.keys) == 0 && len(r.globs) == 0 && len(r.patterns) == 0 {
+		logger.redactor = nil
+		return nil
+	}
+	logger.redactor = r
+	return nil
+}
+
+// matches reports whether the value of key is redacted.
+func (r *redactor) matches(key string) bool {
+	for _, k := range r.keys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	for _, glob := range r.globs {
+		if ok, _ := path.Match(glob, key); ok {
+			return true
+		}
+	}
+	for _, re := range r.patterns {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact returns data with the values of matching fields replaced, and
+// whether any matched.
+func (r *redactor) redact(data Fields) (Fields, bool) {
+	var redacted Fields
+	for k, v := range data {
+		var value interface{}
+		if r.matches(k) {
+			value = r.replacement
+		} else if nested, ok := v.(Fields); !ok {
+			continue
+		} else if value, ok = r.redact(nested); !ok {
+			continue
+		}
+
+		if redacted == nil {
+			redacted = make(Fields, len(data))
+			for k, v := range data {
+				redacted[k] = v
+			}
+		}
+		redacted[k] = value
+	}
+
+	if redacted == nil {
+		return data, false
+	}
+	return redacted, true
+}
@@ -0,0 +1,86 @@
+package logrus
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// dataHook records the fields of the last entry.
+type dataHook struct {
+	data Fields
+}
+
+func (hook *dataHook) Fire(entry *Entry) error {
+	hook.data = entry.Data
+	return nil
+}
+
+func (hook *dataHook) Levels() []Level {
+	return AllLevels
+}
+
+func (hook *dataHook) Close() error {
+	return nil
+}
+
+func TestRedaction(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = new(JSONFormatter)
+	hook := new(dataHook)
+	log.AddHook(hook)
+
+	err := log.SetRedaction(Redaction{
+		Keys:     []string{"Authorization"},
+		Globs:    []string{"*_key"},
+		Patterns: []string{`(?i)password|token`},
+	})
+	assert.NoError(t, err)
+
+	entry := log.WithFields(Fields{
+		"authorization": "Bearer abc",
+		"api_key":       "123",
+		"db_Password":   "hunter2",
+		"user":          "bob",
+		"req":           Fields{"token": "xyz", "path": "/"},
+	})
+	entry.Info("login")
+
+	fields := logLines(t, &buffer)[0]
+	assert.Equal(t, "[REDACTED]", fields["authorization"])
+	assert.Equal(t, "[REDACTED]", fields["api_key"])
+	assert.Equal(t, "[REDACTED]", fields["db_Password"])
+	assert.Equal(t, "bob", fields["user"])
+	assert.Equal(t, map[string]interface{}{"token": "[REDACTED]", "path": "/"}, fields["req"])
+	assert.Equal(t, "[REDACTED]", hook.data["api_key"])
+
+	// the entry itself is untouched
+	assert.Equal(t, "123", entry.Data["api_key"])
+	assert.Equal(t, "xyz", entry.Data["req"].(Fields)["token"])
+}
+
+func TestRedactionReplacement(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = new(JSONFormatter)
+	assert.NoError(t, log.SetRedaction(Redaction{Keys: []string{"ssn"}, Replacement: "***"}))
+
+	log.WithField("ssn", "078-05-1120").Info("test")
+	assert.Equal(t, "***", logLines(t, &buffer)[0]["ssn"])
+
+	assert.NoError(t, log.SetRedaction(Redaction{}))
+	buffer.Reset()
+	log.WithField("ssn", "078-05-1120").Info("test")
+	assert.Equal(t, "078-05-1120", logLines(t, &buffer)[0]["ssn"])
+}
+
+func TestRedactionInvalid(t *testing.T) {
+	log := New()
+	assert.Error(t, log.SetRedaction(Redaction{Patterns: []string{"("}}))
+	assert.Error(t, log.SetRedaction(Redaction{Globs: []string{"["}}))
+	assert.Nil(t, log.redactor)
+}
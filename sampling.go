@@ -0,0 +1,100 @@
+package logrus
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// sampler samples entries per key, see Logger.SetSampling.
+type sampler struct {
+	initial    int
+	thereafter int
+	keyField   string
+
+	mu     sync.Mutex
+	window time.Time
+	counts map[sampleKey]*sampleCount
+}
+
+type sampleKey struct {
+	level Level
+	key   string
+}
+
+type sampleCount struct {
+	logged  int
+	dropped int
+}
+
+// SetSampling limits the entries logged per second with the same level and
+// message, or value of keyField if not empty, to the first initial entries
+// and every thereafter-th entry after that, so a hot loop logging the same
+// warning can't flood the logs. The next entry logged after dropped ones has
+// `sampled=true` and `dropped=N` fields. Fatal and Panic entries are never
+// dropped. Non-positive initial and thereafter disable sampling.
+func (logger *Logger) SetSampling(initial, thereafter int, keyField string) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	if initial <= 0 && thereafter <= 0 {
+		logger.sampler = nil
+		return
+	}
+	logger.sampler = &sampler{
+		initial:    initial,
+		thereafter: thereafter,
+		keyField:   keyField,
+		counts:     make(map[sampleKey]*sampleCount),
+	}
+}
+
+// sample reports whether entry, logged at now, is logged, and returns its
+// fields with the number of entries dropped before it added.
+func (s *sampler) sample(entry *Entry, now time.Time) (Fields, bool) {
+	key := sampleKey{level: entry.Level, key: entry.Message}
+	if s.keyField != "" {
+		key.key = fmt.Sprint(entry.Data[s.keyField])
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.window) >= time.Second {
+		s.window = now
+		for k, c := range s.counts {
+			// keep the counts of dropped entries to report them
+			if c.dropped == 0 {
+				delete(s.counts, k)
+			} else {
+				c.logged = 0
+			}
+		}
+	}
+
+	c, ok := s.counts[key]
+	if !ok {
+		c = &sampleCount{}
+		s.counts[key] = c
+	}
+
+	c.logged++
+	n := c.logged - s.initial
+	if n > 0 && (s.thereafter <= 0 || n%s.thereafter != 0) {
+		c.dropped++
+		return nil, false
+	}
+
+	if c.dropped == 0 {
+		return entry.Data, true
+	}
+
+	data := make(Fields, len(entry.Data)+2)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	data["sampled"] = true
+	data["dropped"] = c.dropped
+	c.dropped = 0
+	return data, true
+}
@@ -0,0 +1,89 @@
+package logrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func logLines(t *testing.T, buffer *bytes.Buffer) []Fields {
+	var lines []Fields
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := Fields{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &fields))
+		lines = append(lines, fields)
+	}
+	return lines
+}
+
+func TestSampling(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = new(JSONFormatter)
+	log.SetSampling(2, 3, "")
+
+	for i := 0; i < 10; i++ {
+		log.Warn("hot")
+	}
+	log.Warn("other")
+
+	lines := logLines(t, &buffer)
+	// entries 1, 2, 5 and 8 of "hot"
+	assert.Len(t, lines, 5)
+	assert.Nil(t, lines[1]["dropped"])
+	assert.Equal(t, true, lines[2]["sampled"])
+	assert.Equal(t, float64(2), lines[2]["dropped"])
+	assert.Equal(t, float64(2), lines[3]["dropped"])
+	assert.Equal(t, "other", lines[4]["msg"])
+}
+
+func TestSamplingByField(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = new(JSONFormatter)
+	log.SetSampling(1, 0, "tenant")
+
+	for i := 0; i < 3; i++ {
+		log.WithField("tenant", "a").Infof("request %d", i)
+		log.WithField("tenant", "b").Infof("request %d", i)
+	}
+
+	lines := logLines(t, &buffer)
+	assert.Len(t, lines, 2)
+}
+
+func TestSamplingWindow(t *testing.T) {
+	s := &sampler{initial: 1, counts: make(map[sampleKey]*sampleCount)}
+	entry := &Entry{Data: Fields{}, Level: InfoLevel, Message: "hot"}
+	now := time.Now()
+
+	_, ok := s.sample(entry, now)
+	assert.True(t, ok)
+	_, ok = s.sample(entry, now)
+	assert.False(t, ok)
+
+	data, ok := s.sample(entry, now.Add(time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, 1, data["dropped"])
+}
+
+func TestSamplingKeepsFatalAndPanic(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.SetSampling(1, 0, "")
+
+	for i := 0; i < 2; i++ {
+		assert.Panics(t, func() { log.Panic("boom") })
+	}
+	assert.Equal(t, 2, strings.Count(buffer.String(), "boom"))
+}
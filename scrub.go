@@ -0,0 +1,139 @@
+package logrus
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ScrubRule replaces the matches of a regular expression in messages and
+// string fields, see NewScrubber.
+type ScrubRule struct {
+	// Regular expression, e.g. `\d{3}-\d{2}-\d{4}`
+	Pattern string
+	// Replacement of matches, which may refer to submatches as in
+	// regexp.Regexp.ReplaceAllString
+	Replacement string
+}
+
+// Common rules for compliance scrubbing
+var (
+	// ScrubCreditCards replaces numbers of 13 to 19 digits, optionally
+	// separated by spaces or dashes, like payment card numbers.
+	ScrubCreditCards = ScrubRule{Pattern: `\b(?:\d[ -]?){12,18}\d\b`, Replacement: "[CARD]"}
+	// ScrubBearerTokens replaces the tokens of bearer authorizations.
+	ScrubBearerTokens = ScrubRule{Pattern: `(?i)\b(bearer)\s+[A-Za-z0-9\-._~+/]+=*`, Replacement: "$1 [TOKEN]"}
+	// ScrubEmails replaces email addresses.
+	ScrubEmails = ScrubRule{Pattern: `[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`, Replacement: "[EMAIL]"}
+)
+
+// Scrubber replaces the matches of rules in the messages and string fields of
+// entries. Errors and fmt.Stringer values are scrubbed as their text: an
+// error whose message matches is replaced by an error with the scrubbed
+// message, which doesn't wrap the original, and a Stringer by the scrubbed
+// string. Other values, e.g. structs, aren't scrubbed. Its expressions are
+// compiled once and, as regexp.Regexp, safe for concurrent use.
+type Scrubber struct {
+	rules []scrubRule
+}
+
+type scrubRule struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// NewScrubber returns a Scrubber applying rules in order, or an error if a
+// pattern is invalid:
+//
+//	scrubber, err := logrus.NewScrubber(logrus.ScrubCreditCards, logrus.ScrubEmails)
+//	log.SetScrubber(scrubber)
+func NewScrubber(rules ...ScrubRule) (*Scrubber, error) {
+	s := &Scrubber{}
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scrubbing pattern %q, %v", rule.Pattern, err)
+		}
+		s.rules = append(s.rules, scrubRule{re: re, replacement: rule.Replacement})
+	}
+	return s, nil
+}
+
+// SetScrubber makes the logger scrub entries with scrubber before hooks and
+// formatters see them. A nil scrubber disables scrubbing.
+func (logger *Logger) SetScrubber(scrubber *Scrubber) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	logger.scrubber = scrubber
+}
+
+// ScrubString returns str with the matches of the rules replaced.
+func (s *Scrubber) ScrubString(str string) string {
+	for _, rule := range s.rules {
+		str = rule.re.ReplaceAllString(str, rule.replacement)
+	}
+	return str
+}
+
+// Scrub returns a copy of entry with its message and string, error and
+// Stringer fields, also in nested Fields, scrubbed.
+func (s *Scrubber) Scrub(entry *Entry) *Entry {
+	scrubbed := *entry
+	scrubbed.Message = s.ScrubString(entry.Message)
+	scrubbed.Data, _ = s.scrubFields(entry.Data)
+	return &scrubbed
+}
+
+// scrubFields returns data with its string, error and Stringer values
+// scrubbed, and whether any changed.
+func (s *Scrubber) scrubFields(data Fields) (Fields, bool) {
+	var scrubbed Fields
+	for k, v := range data {
+		var value interface{}
+		switch v := v.(type) {
+		case string:
+			if value = s.ScrubString(v); value == v {
+				continue
+			}
+		case Fields:
+			var changed bool
+			if value, changed = s.scrubFields(v); !changed {
+				continue
+			}
+		case error:
+			msg := v.Error()
+			scrubbedMsg := s.ScrubString(msg)
+			if scrubbedMsg == msg {
+				continue
+			}
+			value = scrubbedError(scrubbedMsg)
+		case fmt.Stringer:
+			str := v.String()
+			if value = s.ScrubString(str); value == str {
+				continue
+			}
+		default:
+			continue
+		}
+
+		if scrubbed == nil {
+			scrubbed = make(Fields, len(data))
+			for k, v := range data {
+				scrubbed[k] = v
+			}
+		}
+		scrubbed[k] = value
+	}
+
+	if scrubbed == nil {
+		return data, false
+	}
+	return scrubbed, true
+}
+
+// scrubbedError replaces an error whose message was scrubbed.
+type scrubbedError string
+
+func (e scrubbedError) Error() string {
+	return string(e)
+}
@@ -0,0 +1,78 @@
+package logrus
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrubber(t *testing.T) {
+	scrubber, err := NewScrubber(ScrubCreditCards, ScrubBearerTokens, ScrubEmails)
+	assert.NoError(t, err)
+
+	for input, expected := range map[string]string{
+		"paid with 4111 1111 1111 1111":        "paid with [CARD]",
+		"paid with 4111-1111-1111-1111 today":  "paid with [CARD] today",
+		"Authorization: Bearer eyJhbGciOi.x_y": "Authorization: Bearer [TOKEN]",
+		"sent to bob@example.com":              "sent to [EMAIL]",
+		"order 12345 shipped":                  "order 12345 shipped",
+	} {
+		assert.Equal(t, expected, scrubber.ScrubString(input))
+	}
+
+	_, err = NewScrubber(ScrubRule{Pattern: "("})
+	assert.Error(t, err)
+}
+
+func TestLoggerScrubber(t *testing.T) {
+	var buffer bytes.Buffer
+	log := New()
+	log.Out = &buffer
+	log.Formatter = new(JSONFormatter)
+	scrubber, _ := NewScrubber(ScrubEmails, ScrubRule{Pattern: `\d{3}-\d{2}-(\d{4})`, Replacement: "***-**-$1"})
+	log.SetScrubber(scrubber)
+
+	entry := log.WithFields(Fields{
+		"user":  "bob@example.com",
+		"ssn":   "078-05-1120",
+		"count": 3,
+		"req":   Fields{"from": "alice@example.com"},
+	})
+	entry.Info("mail from bob@example.com")
+
+	fields := logLines(t, &buffer)[0]
+	assert.Equal(t, "mail from [EMAIL]", fields["msg"])
+	assert.Equal(t, "[EMAIL]", fields["user"])
+	assert.Equal(t, "***-**-1120", fields["ssn"])
+	assert.Equal(t, float64(3), fields["count"])
+	assert.Equal(t, map[string]interface{}{"from": "[EMAIL]"}, fields["req"])
+	assert.Equal(t, "bob@example.com", entry.Data["user"])
+}
+
+type testStringer string
+
+func (s testStringer) String() string {
+	return string(s)
+}
+
+func TestScrubErrorsAndStringers(t *testing.T) {
+	scrubber, _ := NewScrubber(ScrubBearerTokens)
+	err := errors.New("request with Bearer abc.def failed")
+	ok := errors.New("timeout")
+
+	scrubbed := scrubber.Scrub(NewEntry(New()).WithFields(Fields{
+		ErrorKey:  err,
+		"cause":   ok,
+		"request": testStringer("Authorization: Bearer abc.def"),
+	}))
+
+	scrubbedErr, isErr := scrubbed.Data[ErrorKey].(error)
+	if assert.True(t, isErr) {
+		assert.Equal(t, "request with Bearer [TOKEN] failed", scrubbedErr.Error())
+		assert.False(t, errors.Is(scrubbedErr, err))
+	}
+	assert.Equal(t, ok, scrubbed.Data["cause"])
+	assert.Equal(t, "Authorization: Bearer [TOKEN]", scrubbed.Data["request"])
+}
@@ -0,0 +1,63 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package logrus
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// HandleLevelSignals makes the logger more verbose by one level on SIGUSR1
+// and less verbose on SIGUSR2, e.g. to debug a daemon without a restart. If
+// restoreAfter is positive, the level in effect before the first signal is
+// restored restoreAfter after the last one, so debug logging left enabled
+// doesn't fill the disk. The returned function stops handling the signals.
+func (logger *Logger) HandleLevelSignals(restoreAfter time.Duration) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	done := make(chan struct{})
+
+	go func() {
+		var timer *time.Timer
+		var restore <-chan time.Time
+		var original Level
+		changed := false
+
+		for {
+			select {
+			case sig := <-signals:
+				level := logger.level()
+				if !changed {
+					original, changed = level, true
+				}
+				logger.SetLevel(stepLevel(level, sig == syscall.SIGUSR1))
+
+				if restoreAfter > 0 {
+					if timer != nil {
+						timer.Stop()
+					}
+					timer = time.NewTimer(restoreAfter)
+					restore = timer.C
+				}
+			case <-restore:
+				logger.SetLevel(original)
+				changed, restore = false, nil
+			case <-done:
+				signal.Stop(signals)
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
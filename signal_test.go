@@ -0,0 +1,53 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package logrus
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// waitForLevel waits for the level of log to become level.
+func waitForLevel(t *testing.T, log *Logger, level Level) {
+	deadline := time.Now().Add(2 * time.Second)
+	for log.level() != level && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, level, log.level())
+}
+
+func TestHandleLevelSignals(t *testing.T) {
+	log := New()
+	log.SetLevel(WarnLevel)
+	stop := log.HandleLevelSignals(0)
+	defer stop()
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	waitForLevel(t, log, testNoticeLevel)
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	waitForLevel(t, log, WarnLevel)
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	waitForLevel(t, log, ErrorLevel)
+}
+
+func TestHandleLevelSignalsRestore(t *testing.T) {
+	log := New()
+	stop := log.HandleLevelSignals(50 * time.Millisecond)
+	defer stop()
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	waitForLevel(t, log, DebugLevel)
+	waitForLevel(t, log, InfoLevel)
+}
+
+func TestStepLevel(t *testing.T) {
+	assert.Equal(t, testAuditLevel, stepLevel(FatalLevel, true))
+	assert.Equal(t, ErrorLevel, stepLevel(testAuditLevel, true))
+	assert.Equal(t, PanicLevel, stepLevel(PanicLevel, false))
+	assert.Equal(t, testTraceLevel, stepLevel(DebugLevel, true))
+	assert.Equal(t, testTraceLevel, stepLevel(testTraceLevel, true))
+}
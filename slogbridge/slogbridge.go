@@ -0,0 +1,172 @@
+//go:build go1.21
+// +build go1.21
+
+// Package slogbridge implements a log/slog Handler logging to a logrus
+// Logger, so code migrating to slog, and libraries taking a *slog.Logger,
+// keep the hooks and formatters of logrus.
+package slogbridge
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"github.com/dorofeevsa/logrus"
+)
+
+// Handler is a slog.Handler logging records to a logrus Logger. Attributes
+// become fields and groups nested logrus.Fields:
+//
+//	log := slog.New(slogbridge.NewHandler(logrus.StandardLogger()))
+//	log.WithGroup("req").Info("handled", "method", "GET")
+//
+// logs `msg="handled" req=map[method:GET]`.
+type Handler struct {
+	logger *logrus.Logger
+	// fields added by WithAttrs
+	fields logrus.Fields
+	// groups opened by WithGroup
+	groups []string
+}
+
+// NewHandler returns a Handler logging to logger.
+func NewHandler(logger *logrus.Logger) *Handler {
+	return &Handler{logger: logger, fields: logrus.Fields{}}
+}
+
+// NewLogger returns a slog.Logger logging to logger, e.g. for libraries
+// taking a *slog.Logger.
+func NewLogger(logger *logrus.Logger) *slog.Logger {
+	return slog.New(NewHandler(logger))
+}
+
+// NewEntryLogger returns a slog.Logger logging to the logger of entry, with
+// the fields of entry.
+func NewEntryLogger(entry *logrus.Entry) *slog.Logger {
+	return slog.New(&Handler{logger: entry.Logger, fields: copyFields(entry.Data)})
+}
+
+// SetDefault makes the default slog.Logger, and so the log package's default
+// logger, log to logger.
+func SetDefault(logger *logrus.Logger) {
+	slog.SetDefault(NewLogger(logger))
+}
+
+// LogrusLevel returns the logrus level of level, e.g. logrus.WarnLevel for
+// levels from slog.LevelWarn up to slog.LevelError.
+func LogrusLevel(level slog.Level) logrus.Level {
+	switch {
+	case level >= slog.LevelError:
+		return logrus.ErrorLevel
+	case level >= slog.LevelWarn:
+		return logrus.WarnLevel
+	case level >= slog.LevelInfo:
+		return logrus.InfoLevel
+	default:
+		return logrus.DebugLevel
+	}
+}
+
+// Enabled reports whether the logger logs records of level.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.IsLevelEnabled(LogrusLevel(level))
+}
+
+// Handle logs the record to the logger, with ctx as the context of the entry
+// and the time of the record.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	fields := copyFields(h.fields)
+	if r.NumAttrs() > 0 {
+		target := fields
+		for _, group := range h.groups {
+			target = subFields(target, group)
+		}
+		r.Attrs(func(a slog.Attr) bool {
+			addAttr(target, a)
+			return true
+		})
+	}
+
+	entry := h.logger.WithFields(fields).WithContext(ctx).WithTime(r.Time)
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		entry.Caller = &frame
+	}
+	entry.Log(LogrusLevel(r.Level), r.Message)
+	return nil
+}
+
+// WithAttrs returns a Handler adding attrs to records, in the open groups.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	fields := copyFields(h.fields)
+	target := fields
+	for _, group := range h.groups {
+		target = subFields(target, group)
+	}
+	for _, a := range attrs {
+		addAttr(target, a)
+	}
+	return &Handler{logger: h.logger, fields: fields, groups: h.groups}
+}
+
+// WithGroup returns a Handler adding the attributes of records, and the ones
+// added later, to the group name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	groups := make([]string, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+	return &Handler{logger: h.logger, fields: h.fields, groups: append(groups, name)}
+}
+
+// addAttr adds a to fields, groups as nested fields.
+func addAttr(fields logrus.Fields, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() != slog.KindGroup {
+		fields[a.Key] = a.Value.Any()
+		return
+	}
+
+	attrs := a.Value.Group()
+	if len(attrs) == 0 {
+		return
+	}
+	if a.Key != "" {
+		fields = subFields(fields, a.Key)
+	}
+	for _, ga := range attrs {
+		addAttr(fields, ga)
+	}
+}
+
+// subFields returns the nested fields of key in fields, adding them if needed.
+func subFields(fields logrus.Fields, key string) logrus.Fields {
+	sub, ok := fields[key].(logrus.Fields)
+	if !ok {
+		sub = logrus.Fields{}
+		fields[key] = sub
+	}
+	return sub
+}
+
+// copyFields returns a copy of fields and of their nested fields.
+func copyFields(fields logrus.Fields) logrus.Fields {
+	copied := make(logrus.Fields, len(fields))
+	for k, v := range fields {
+		if sub, ok := v.(logrus.Fields); ok {
+			v = copyFields(sub)
+		}
+		copied[k] = v
+	}
+	return copied
+}
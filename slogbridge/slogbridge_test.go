@@ -0,0 +1,137 @@
+//go:build go1.22
+// +build go1.22
+
+package slogbridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	"github.com/dorofeevsa/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLogger(buffer *bytes.Buffer) *logrus.Logger {
+	logger := logrus.New()
+	logger.Out = buffer
+	logger.Formatter = new(logrus.JSONFormatter)
+	logger.SetLevel(logrus.DebugLevel)
+	return logger
+}
+
+func entries(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, line := range bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestSlogtest(t *testing.T) {
+	var buffer bytes.Buffer
+	slogtest.Run(t, func(t *testing.T) slog.Handler {
+		if strings.HasSuffix(t.Name(), "/zero-time") {
+			t.Skip("logrus sets the time of all entries")
+		}
+		buffer.Reset()
+		return NewHandler(newLogger(&buffer))
+	}, func(t *testing.T) map[string]interface{} {
+		return entries(t, &buffer)[0]
+	})
+}
+
+func TestHandler(t *testing.T) {
+	var buffer bytes.Buffer
+	log := slog.New(NewHandler(newLogger(&buffer)))
+
+	log.With("a", 1).WithGroup("req").With("method", "GET").Warn("handled", slog.Group("user", "id", 7), "status", 200)
+
+	entry := entries(t, &buffer)[0]
+	assert.Equal(t, "warning", entry["level"])
+	assert.Equal(t, "handled", entry["msg"])
+	assert.Equal(t, float64(1), entry["a"])
+	assert.Equal(t, map[string]interface{}{
+		"method": "GET",
+		"status": float64(200),
+		"user":   map[string]interface{}{"id": float64(7)},
+	}, entry["req"])
+}
+
+func TestHandlerLevels(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := newLogger(&buffer)
+	logger.SetLevel(logrus.WarnLevel)
+	log := slog.New(NewHandler(logger))
+
+	log.Info("info")
+	log.Log(context.Background(), slog.LevelWarn+1, "warn")
+	log.Log(context.Background(), slog.LevelError+4, "error")
+
+	e := entries(t, &buffer)
+	assert.Len(t, e, 2)
+	assert.Equal(t, "warning", e[0]["level"])
+	assert.Equal(t, "error", e[1]["level"])
+	assert.False(t, log.Enabled(context.Background(), slog.LevelInfo))
+}
+
+func TestHandlerCaller(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := newLogger(&buffer)
+	logger.SetReportCaller(true)
+
+	slog.New(NewHandler(logger)).Info("test")
+
+	entry := entries(t, &buffer)[0]
+	assert.Equal(t, "github.com/dorofeevsa/logrus/slogbridge.TestHandlerCaller", entry["func"])
+	assert.Contains(t, entry["file"], "slogbridge_test.go:")
+}
+
+func TestHandlerTime(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := newLogger(&buffer)
+	logger.Formatter = &logrus.JSONFormatter{TimestampFormat: time.RFC3339}
+
+	r := slog.NewRecord(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), slog.LevelInfo, "replayed", 0)
+	assert.NoError(t, NewHandler(logger).Handle(context.Background(), r))
+
+	assert.Equal(t, "2020-01-02T03:04:05Z", entries(t, &buffer)[0]["time"])
+}
+
+func TestNewEntryLogger(t *testing.T) {
+	var buffer bytes.Buffer
+	entry := newLogger(&buffer).WithField("component", "db")
+
+	NewEntryLogger(entry).Info("connected", "host", "localhost")
+	NewLogger(entry.Logger).Info("plain")
+
+	e := entries(t, &buffer)
+	assert.Equal(t, "db", e[0]["component"])
+	assert.Equal(t, "localhost", e[0]["host"])
+	assert.Equal(t, "connected", e[0]["msg"])
+	assert.NotContains(t, e[1], "component")
+}
+
+func TestSetDefault(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	var buffer bytes.Buffer
+	SetDefault(newLogger(&buffer))
+	slog.Debug("default", "a", 1)
+
+	e := entries(t, &buffer)
+	assert.Equal(t, "default", e[0]["msg"])
+	assert.Equal(t, "debug", e[0]["level"])
+}
@@ -0,0 +1,95 @@
+package logrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// FieldKeyStack is the key of the stack captured for errors, see
+// Logger.ReportStack.
+const FieldKeyStack = "stack"
+
+const defaultStackDepth = 32
+
+// Stack is the stack of the goroutine logging an entry, innermost frame
+// first. It prints like debug.Stack and marshals to JSON as an array of
+// "function file:line" strings.
+type Stack []runtime.Frame
+
+func (s Stack) String() string {
+	var b bytes.Buffer
+	for _, f := range s {
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+	}
+	return b.String()
+}
+
+func (s Stack) MarshalJSON() ([]byte, error) {
+	frames := make([]string, len(s))
+	for i, f := range s {
+		frames[i] = fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line)
+	}
+	return json.Marshal(frames)
+}
+
+// captureStack returns up to depth frames of the stack of the calling
+// goroutine, starting at the first frame outside of logrus.
+func captureStack(depth int) Stack {
+	if depth <= 0 {
+		depth = defaultStackDepth
+	}
+
+	// leave room for the frames of logrus
+	pcs := make([]uintptr, depth+maximumCallerDepth)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+
+	stack := make(Stack, 0, depth)
+	for f, again := frames.Next(); again && len(stack) < depth; f, again = frames.Next() {
+		if len(stack) == 0 && isLogrusFrame(f) {
+			continue
+		}
+		stack = append(stack, f)
+	}
+	return stack
+}
+
+// panicStack returns up to depth frames of the stack of a panic, when called
+// by a deferred function recovering from it, starting at the frame which
+// panicked.
+func panicStack(depth int) Stack {
+	if depth <= 0 {
+		depth = defaultStackDepth
+	}
+	stack := captureStack(depth + maximumCallerDepth)
+	for i, f := range stack {
+		if f.Function != "runtime.gopanic" {
+			continue
+		}
+		// skip the runtime frames raising the panic, e.g. for nil pointers
+		for i++; i < len(stack) && strings.HasPrefix(stack[i].Function, "runtime."); i++ {
+		}
+		stack = stack[i:]
+		break
+	}
+	if len(stack) > depth {
+		stack = stack[:depth]
+	}
+	return stack
+}
+
+// withStack returns the entry's fields with the stack added, unless they have
+// one, e.g. of a recovered panic.
+func (entry *Entry) withStack(depth int) Fields {
+	if _, ok := entry.Data[FieldKeyStack].(Stack); ok {
+		return entry.Data
+	}
+	data := make(Fields, len(entry.Data)+1)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	data[FieldKeyStack] = captureStack(depth)
+	return data
+}
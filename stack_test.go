@@ -0,0 +1,63 @@
+package logrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportStack(t *testing.T) {
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.ReportStack = true
+		log.StackDepth = 2
+		log.Error("test")
+	}, func(fields Fields) {
+		stack, ok := fields[FieldKeyStack].([]interface{})
+		assert.True(t, ok, "stack is not an array")
+		assert.Len(t, stack, 2)
+		assert.True(t, strings.HasPrefix(stack[0].(string), "github.com/dorofeevsa/logrus.TestReportStack.func1 "), stack[0])
+	})
+}
+
+func TestReportStackBelowError(t *testing.T) {
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.ReportStack = true
+		log.Warn("test")
+	}, func(fields Fields) {
+		_, ok := fields[FieldKeyStack]
+		assert.False(t, ok)
+	})
+}
+
+func TestReportStackDoesNotModifyEntry(t *testing.T) {
+	log := New()
+	log.Out = &bytes.Buffer{}
+	log.ReportStack = true
+
+	entry := log.WithField("a", 1)
+	entry.Error("test")
+
+	_, ok := entry.Data[FieldKeyStack]
+	assert.False(t, ok)
+}
+
+func TestStackFormatting(t *testing.T) {
+	stack := Stack{
+		{Function: "main.handler", File: "/app/handler.go", Line: 42},
+		{Function: "main.main", File: "/app/main.go", Line: 7},
+	}
+
+	assert.Equal(t, "main.handler\n\t/app/handler.go:42\nmain.main\n\t/app/main.go:7\n", stack.String())
+
+	b, err := json.Marshal(stack)
+	assert.NoError(t, err)
+	assert.Equal(t, `["main.handler /app/handler.go:42","main.main /app/main.go:7"]`, string(b))
+
+	tf := &TextFormatter{DisableColors: true, DisableTimestamp: true}
+	entry := WithFields(Fields{"a": 1, FieldKeyStack: stack})
+	out, _ := tf.Format(entry)
+	assert.Equal(t, "level=panic a=1\n"+stack.String(), string(out))
+}
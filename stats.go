@@ -0,0 +1,57 @@
+package logrus
+
+import "time"
+
+// Stats holds the counters of a logger, see Logger.SetStatsEnabled.
+type Stats struct {
+	// Entries is the number of entries written.
+	Entries uint64
+	// Bytes is the number of formatted bytes written, to Out and the outputs.
+	Bytes uint64
+	// Since is the time the counting started.
+	Since time.Time
+}
+
+// EntriesPerSecond returns the rate of entries written since counting
+// started.
+func (s Stats) EntriesPerSecond() float64 {
+	return s.rate(s.Entries)
+}
+
+// BytesPerSecond returns the rate of bytes written since counting started.
+func (s Stats) BytesPerSecond() float64 {
+	return s.rate(s.Bytes)
+}
+
+func (s Stats) rate(n uint64) float64 {
+	elapsed := time.Since(s.Since).Seconds()
+	if s.Since.IsZero() || elapsed <= 0 {
+		return 0
+	}
+	return float64(n) / elapsed
+}
+
+// SetStatsEnabled starts counting the entries and bytes written by the
+// logger from zero, or stops counting, see Stats. Counting is off by
+// default.
+func (logger *Logger) SetStatsEnabled(enabled bool) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	if enabled {
+		logger.stats = &Stats{Since: time.Now()}
+	} else {
+		logger.stats = nil
+	}
+}
+
+// Stats returns the counters of the logger, zero unless counting is enabled.
+func (logger *Logger) Stats() Stats {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	if logger.stats == nil {
+		return Stats{}
+	}
+	return *logger.stats
+}
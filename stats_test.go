@@ -0,0 +1,34 @@
+package logrus
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	var out, output bytes.Buffer
+	log := New()
+	log.Out = &out
+	log.AddOutput(&output, WarnLevel, nil)
+
+	log.Info("not counted")
+	assert.Equal(t, Stats{}, log.Stats())
+
+	log.SetStatsEnabled(true)
+	out.Reset()
+	log.Info("info")
+	log.Warn("warn")
+	stats := log.Stats()
+	assert.Equal(t, uint64(2), stats.Entries)
+	assert.Equal(t, uint64(out.Len()+output.Len()), stats.Bytes)
+	assert.True(t, time.Since(stats.Since) < time.Minute)
+	assert.True(t, stats.EntriesPerSecond() > 0)
+	assert.True(t, stats.BytesPerSecond() > stats.EntriesPerSecond())
+
+	log.SetStatsEnabled(false)
+	assert.Equal(t, Stats{}, log.Stats())
+	assert.Equal(t, float64(0), Stats{}.EntriesPerSecond())
+}
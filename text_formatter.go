@@ -3,7 +3,8 @@ package logrus
 import (
 	"bytes"
 	"fmt"
-	"sort"
+	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -35,6 +36,19 @@ type TextFormatter struct {
 	// Force disabling colors.
 	DisableColors bool
 
+	// Override coloring based on the NO_COLOR, CLICOLOR and CLICOLOR_FORCE
+	// environment variables.
+	EnvironmentOverrideColors bool
+
+	// LevelColors overrides the colors of levels. Colors are the parameters
+	// of ANSI escape codes, e.g. "31" for red, Color256(208) or
+	// RGBColor(255, 128, 0).
+	LevelColors map[Level]string
+
+	// KeyColor sets the color of field keys, the color of the level by
+	// default.
+	KeyColor string
+
 	// Disable timestamp logging. useful when output is redirected to logging
 	// system that already adds timestamps.
 	DisableTimestamp bool
@@ -46,11 +60,21 @@ type TextFormatter struct {
 	// TimestampFormat to use for display when a full timestamp is printed
 	TimestampFormat string
 
+	// TimestampMode allows logging timestamps as epoch numbers instead.
+	TimestampMode TimestampMode
+
 	// The fields are sorted by default for a consistent output. For applications
 	// that log extremely frequently and don't use the JSON formatter this may not
 	// be desired.
 	DisableSorting bool
 
+	// SortingFunc replaces the alphabetical sorting of the fields.
+	SortingFunc func([]string)
+
+	// PriorityKeys are logged before the other fields, in their order, e.g.
+	// []string{"request_id", "user_id"}.
+	PriorityKeys []string
+
 	// Disables the truncation of the level text to 4 characters.
 	DisableLevelTruncation bool
 
@@ -71,26 +95,35 @@ func (f *TextFormatter) init(entry *Entry) {
 
 // Format renders a single log entry
 func (f *TextFormatter) Format(entry *Entry) ([]byte, error) {
-	var b *bytes.Buffer
+	var stacks []Stack
 	keys := make([]string, 0, len(entry.Data))
-	for k := range entry.Data {
+	for k, v := range entry.Data {
+		// stacks are printed below the line to be readable
+		if stack, ok := v.(Stack); ok {
+			stacks = append(stacks, stack)
+			continue
+		}
 		keys = append(keys, k)
 	}
 
 	if !f.DisableSorting {
-		sort.Strings(keys)
+		sortKeys(keys, f.PriorityKeys, f.SortingFunc)
 	}
-	if entry.Buffer != nil {
-		b = entry.Buffer
-	} else {
-		b = &bytes.Buffer{}
+	// the code comes first, see WithCode
+	for i, k := range keys {
+		if k == FieldKeyCode {
+			copy(keys[1:i+1], keys[:i])
+			keys[0] = FieldKeyCode
+			break
+		}
 	}
+	b := entryBuffer(entry)
 
-	prefixFieldClashes(entry.Data, emptyFieldMap)
+	prefixFieldClashes(entry.Data, emptyFieldMap, entry.HasCaller())
 
 	f.Do(func() { f.init(entry) })
 
-	isColored := (f.ForceColors || f.isTerminal) && !f.DisableColors
+	isColored := f.isColored()
 
 	timestampFormat := f.TimestampFormat
 	if timestampFormat == "" {
@@ -100,49 +133,104 @@ func (f *TextFormatter) Format(entry *Entry) ([]byte, error) {
 		f.printColored(b, entry, keys, timestampFormat)
 	} else {
 		if !f.DisableTimestamp {
-			f.appendKeyValue(b, "time", entry.Time.Format(timestampFormat))
+			f.appendKeyValue(b, "time", f.TimestampMode.timestamp(entry.Time, timestampFormat))
 		}
 		f.appendKeyValue(b, "level", entry.Level.String())
 		if entry.Message != "" {
 			f.appendKeyValue(b, "msg", entry.Message)
 		}
+		if entry.HasCaller() {
+			function, file := entry.callerInfo()
+			f.appendKeyValue(b, FieldKeyFunc, function)
+			f.appendKeyValue(b, FieldKeyFile, file)
+		}
 		for _, key := range keys {
 			f.appendKeyValue(b, key, entry.Data[key])
 		}
 	}
 
 	b.WriteByte('\n')
+	for _, stack := range stacks {
+		b.WriteString(stack.String())
+	}
 	return b.Bytes(), nil
 }
 
-func (f *TextFormatter) printColored(b *bytes.Buffer, entry *Entry, keys []string, timestampFormat string) {
-	var levelColor int
-	switch entry.Level {
+// isColored reports whether entries are logged in colors.
+func (f *TextFormatter) isColored() bool {
+	isColored := f.ForceColors || f.isTerminal
+
+	if f.EnvironmentOverrideColors {
+		force, ok := os.LookupEnv("CLICOLOR_FORCE")
+		switch {
+		case os.Getenv("NO_COLOR") != "":
+			isColored = false
+		case ok && force != "0":
+			isColored = true
+		case ok && force == "0", os.Getenv("CLICOLOR") == "0":
+			isColored = false
+		}
+	}
+
+	return isColored && !f.DisableColors
+}
+
+// Color256 returns the color n of the 256-color palette, see LevelColors.
+func Color256(n uint8) string {
+	return "38;5;" + strconv.Itoa(int(n))
+}
+
+// RGBColor returns a 24-bit truecolor, see LevelColors.
+func RGBColor(r, g, b uint8) string {
+	return fmt.Sprintf("38;2;%d;%d;%d", r, g, b)
+}
+
+func (f *TextFormatter) levelColor(level Level) string {
+	if color, ok := f.LevelColors[level]; ok {
+		return color
+	}
+
+	switch level.Builtin() {
 	case DebugLevel:
-		levelColor = gray
+		return strconv.Itoa(gray)
 	case WarnLevel:
-		levelColor = yellow
+		return strconv.Itoa(yellow)
 	case ErrorLevel, FatalLevel, PanicLevel:
-		levelColor = red
+		return strconv.Itoa(red)
 	default:
-		levelColor = blue
+		return strconv.Itoa(blue)
+	}
+}
+
+func (f *TextFormatter) printColored(b *bytes.Buffer, entry *Entry, keys []string, timestampFormat string) {
+	levelColor := f.levelColor(entry.Level)
+	keyColor := f.KeyColor
+	if keyColor == "" {
+		keyColor = levelColor
 	}
 
 	levelText := strings.ToUpper(entry.Level.String())
-	if !f.DisableLevelTruncation {
+	if !f.DisableLevelTruncation && len(levelText) > 4 {
 		levelText = levelText[0:4]
 	}
 
 	if f.DisableTimestamp {
-		fmt.Fprintf(b, "\x1b[%dm%s\x1b[0m %-44s ", levelColor, levelText, entry.Message)
+		fmt.Fprintf(b, "\x1b[%sm%s\x1b[0m %-44s ", levelColor, levelText, entry.Message)
 	} else if !f.FullTimestamp {
-		fmt.Fprintf(b, "\x1b[%dm%s\x1b[0m[%04d] %-44s ", levelColor, levelText, int(entry.Time.Sub(baseTimestamp)/time.Second), entry.Message)
+		fmt.Fprintf(b, "\x1b[%sm%s\x1b[0m[%04d] %-44s ", levelColor, levelText, int(entry.Time.Sub(baseTimestamp)/time.Second), entry.Message)
 	} else {
-		fmt.Fprintf(b, "\x1b[%dm%s\x1b[0m[%s] %-44s ", levelColor, levelText, entry.Time.Format(timestampFormat), entry.Message)
+		fmt.Fprintf(b, "\x1b[%sm%s\x1b[0m[%v] %-44s ", levelColor, levelText, f.TimestampMode.timestamp(entry.Time, timestampFormat), entry.Message)
+	}
+	if entry.HasCaller() {
+		function, file := entry.callerInfo()
+		fmt.Fprintf(b, " \x1b[%sm%s\x1b[0m=", keyColor, FieldKeyFunc)
+		f.appendValue(b, function)
+		fmt.Fprintf(b, " \x1b[%sm%s\x1b[0m=", keyColor, FieldKeyFile)
+		f.appendValue(b, file)
 	}
 	for _, k := range keys {
 		v := entry.Data[k]
-		fmt.Fprintf(b, " \x1b[%dm%s\x1b[0m=", levelColor, k)
+		fmt.Fprintf(b, " \x1b[%sm%s\x1b[0m=", keyColor, k)
 		f.appendValue(b, v)
 	}
 }
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"os"
+	"runtime"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -128,6 +131,23 @@ func TestTimestampFormat(t *testing.T) {
 	checkTimeStr("")
 }
 
+func TestTimestampMode(t *testing.T) {
+	entry := WithField("test", "test")
+	entry.Time = time.Unix(1514862245, 123456789)
+
+	for mode, expected := range map[TimestampMode]string{
+		TimestampEpochSeconds: "time=1514862245 ",
+		TimestampEpochMillis:  "time=1514862245123 ",
+		TimestampEpochNanos:   "time=1514862245123456789 ",
+	} {
+		customFormatter := &TextFormatter{DisableColors: true, TimestampMode: mode}
+		b, _ := customFormatter.Format(entry)
+		if !strings.HasPrefix(string(b), expected) {
+			t.Errorf("Expected %q to start with %q", string(b), expected)
+		}
+	}
+}
+
 func TestDisableLevelTruncation(t *testing.T) {
 	entry := &Entry{
 		Time:    time.Now(),
@@ -175,5 +195,96 @@ func TestDisableTimestampWithColoredOutput(t *testing.T) {
 	}
 }
 
+func TestColorScheme(t *testing.T) {
+	tf := &TextFormatter{
+		DisableTimestamp: true,
+		ForceColors:      true,
+		LevelColors:      map[Level]string{InfoLevel: Color256(208)},
+		KeyColor:         RGBColor(0, 128, 255),
+	}
+
+	entry := WithField("test", "test")
+	entry.Level = InfoLevel
+	b, _ := tf.Format(entry)
+	if !strings.HasPrefix(string(b), "\x1b[38;5;208mINFO\x1b[0m") {
+		t.Errorf("level not colored by LevelColors: %q", string(b))
+	}
+	if !strings.Contains(string(b), "\x1b[38;2;0;128;255mtest\x1b[0m=test") {
+		t.Errorf("key not colored by KeyColor: %q", string(b))
+	}
+}
+
+func TestEnvironmentOverrideColors(t *testing.T) {
+	defer os.Unsetenv("NO_COLOR")
+	defer os.Unsetenv("CLICOLOR_FORCE")
+	defer os.Unsetenv("CLICOLOR")
+
+	for _, test := range []struct {
+		env           map[string]string
+		forceColors   bool
+		disableColors bool
+		colored       bool
+	}{
+		{map[string]string{}, false, false, false},
+		{map[string]string{"CLICOLOR_FORCE": "1"}, false, false, true},
+		{map[string]string{"CLICOLOR_FORCE": "1"}, false, true, false},
+		{map[string]string{"CLICOLOR_FORCE": "0"}, true, false, false},
+		{map[string]string{"CLICOLOR": "0"}, true, false, false},
+		{map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"}, true, false, false},
+	} {
+		for _, key := range []string{"NO_COLOR", "CLICOLOR_FORCE", "CLICOLOR"} {
+			os.Unsetenv(key)
+		}
+		for key, value := range test.env {
+			os.Setenv(key, value)
+		}
+
+		tf := &TextFormatter{
+			ForceColors:               test.forceColors,
+			DisableColors:             test.disableColors,
+			EnvironmentOverrideColors: true,
+		}
+		if tf.isColored() != test.colored {
+			t.Errorf("expected colored to be %v with %v", test.colored, test.env)
+		}
+	}
+}
+
+func TestPriorityKeys(t *testing.T) {
+	tf := &TextFormatter{DisableColors: true, DisableTimestamp: true, PriorityKeys: []string{"user_id", "missing", "request_id"}}
+
+	b, _ := tf.Format(WithFields(Fields{"a": 1, "request_id": 2, "user_id": 3, "z": 4}))
+	expected := "level=panic user_id=3 request_id=2 a=1 z=4\n"
+	if string(b) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(b))
+	}
+}
+
+func TestSortingFunc(t *testing.T) {
+	tf := &TextFormatter{
+		DisableColors:    true,
+		DisableTimestamp: true,
+		SortingFunc:      func(keys []string) { sort.Sort(sort.Reverse(sort.StringSlice(keys))) },
+	}
+
+	b, _ := tf.Format(WithFields(Fields{"a": 1, "b": 2, "c": 3}))
+	expected := "level=panic c=3 b=2 a=1\n"
+	if string(b) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(b))
+	}
+}
+
 // TODO add tests for sorting etc., this requires a parser for the text
 // formatter output.
+
+func TestTextReportCaller(t *testing.T) {
+	tf := &TextFormatter{DisableColors: true, DisableTimestamp: true}
+
+	entry := WithField("a", 1)
+	entry.Caller = &runtime.Frame{Function: "main.handler", File: "/src/app/handler.go", Line: 42}
+	b, _ := tf.Format(entry)
+	expected := "level=panic func=main.handler file=\"/src/app/handler.go:42\" a=1\n"
+	if string(b) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(b))
+	}
+}
@@ -0,0 +1,77 @@
+package logrus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	mathrand "math/rand"
+	"sync"
+)
+
+// FieldKeyTraceID is the key of generated trace IDs, see
+// SetTraceIDGeneration. It's the key of the OpenTelemetry trace IDs of the
+// otel package too, so either correlates entries.
+const FieldKeyTraceID = "trace_id"
+
+// SetTraceIDGeneration makes entries created with WithContext carry a random
+// UUID in the FieldKeyTraceID field if their context carries none, see
+// ContextWithFields, so services without tracing still correlate the entries
+// of a request. The ID is generated when the first entry is logged and shared
+// by the entries derived from it; add the ID to the context with
+// ContextWithTraceID to share it with all the entries logged with the
+// context. A trace ID from a context extractor or the entry's fields takes
+// precedence. It's off by default.
+func (logger *Logger) SetTraceIDGeneration(enabled bool) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	logger.traceIDGeneration = enabled
+}
+
+// ContextWithTraceID returns a copy of ctx carrying a random UUID in the
+// FieldKeyTraceID field, unless it carries one already, see
+// ContextWithFields.
+func ContextWithTraceID(ctx context.Context) context.Context {
+	if _, ok := FieldsFromContext(ctx)[FieldKeyTraceID]; ok {
+		return ctx
+	}
+	return ContextWithFields(ctx, Fields{FieldKeyTraceID: newTraceID()})
+}
+
+// withGeneratedTraceID returns ctx with a trace ID generated when first
+// logged, unless it carries one or the logger doesn't generate them.
+func (logger *Logger) withGeneratedTraceID(ctx context.Context) context.Context {
+	if logger == nil || ctx == nil {
+		return ctx
+	}
+	logger.mu.Lock()
+	enabled := logger.traceIDGeneration
+	logger.mu.Unlock()
+	if !enabled {
+		return ctx
+	}
+	if _, ok := FieldsFromContext(ctx)[FieldKeyTraceID]; ok {
+		return ctx
+	}
+
+	var once sync.Once
+	var id string
+	return ContextWithFields(ctx, Fields{FieldKeyTraceID: Lazy(func() interface{} {
+		once.Do(func() { id = newTraceID() })
+		return id
+	})})
+}
+
+// newTraceID returns a random version 4 UUID.
+func newTraceID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// unlikely, and logging must go on
+		binary.LittleEndian.PutUint64(b[:8], mathrand.Uint64())
+		binary.LittleEndian.PutUint64(b[8:], mathrand.Uint64())
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,68 @@
+package logrus
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestTraceIDGeneration(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := New()
+	logger.Out = &buffer
+	logger.Formatter = new(JSONFormatter)
+	logger.SetTraceIDGeneration(true)
+
+	entry := logger.WithContext(context.Background())
+	entry.Info("first")
+	entry.WithField("a", 1).Info("second")
+	logger.WithContext(context.Background()).Info("other")
+	logger.Info("without context")
+
+	fields := logLines(t, &buffer)
+	if assert.Len(t, fields, 4) {
+		id, _ := fields[0][FieldKeyTraceID].(string)
+		assert.Regexp(t, uuidPattern, id)
+		assert.Equal(t, id, fields[1][FieldKeyTraceID])
+		assert.Regexp(t, uuidPattern, fields[2][FieldKeyTraceID])
+		assert.NotEqual(t, id, fields[2][FieldKeyTraceID])
+		assert.NotContains(t, fields[3], FieldKeyTraceID)
+	}
+}
+
+func TestTraceIDGenerationKeepsIDs(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := New()
+	logger.Out = &buffer
+	logger.Formatter = new(JSONFormatter)
+	logger.SetTraceIDGeneration(true)
+
+	ctx := ContextWithFields(context.Background(), Fields{FieldKeyTraceID: "from-context"})
+	logger.WithContext(ctx).Info("context")
+	logger.WithContext(context.Background()).WithField(FieldKeyTraceID, "from-entry").Info("entry")
+
+	extracting := logger.Clone()
+	extracting.AddContextExtractor(func(ctx context.Context) Fields {
+		return Fields{FieldKeyTraceID: "extracted"}
+	})
+	extracting.WithContext(context.Background()).Info("extractor")
+
+	fields := logLines(t, &buffer)
+	if assert.Len(t, fields, 3) {
+		assert.Equal(t, "from-context", fields[0][FieldKeyTraceID])
+		assert.Equal(t, "from-entry", fields[1][FieldKeyTraceID])
+		assert.Equal(t, "extracted", fields[2][FieldKeyTraceID])
+	}
+}
+
+func TestContextWithTraceID(t *testing.T) {
+	ctx := ContextWithTraceID(context.Background())
+	id := FieldsFromContext(ctx)[FieldKeyTraceID]
+	assert.Regexp(t, uuidPattern, id)
+	assert.Equal(t, ctx, ContextWithTraceID(ctx))
+}
@@ -0,0 +1,69 @@
+package logrus
+
+import (
+	"fmt"
+	"time"
+)
+
+// Suffixes of the keys of the numeric fields of WithDuration and WithBytes.
+const (
+	DurationKeySuffix = "_ms"
+	BytesKeySuffix    = "_bytes"
+)
+
+// WithDuration adds d in two fields, as a human readable string like "1.2s"
+// in the key field and as a number of milliseconds in the key+"_ms" field,
+// so durations are both readable and queryable the same way everywhere:
+//
+//	log.WithDuration("latency", time.Since(start)).Info("Handled the request")
+//
+// logs `latency=1.2s latency_ms=1200.5`.
+func (entry *Entry) WithDuration(key string, d time.Duration) *Entry {
+	return entry.WithFields(Fields{
+		key:                     d.String(),
+		key + DurationKeySuffix: float64(d) / float64(time.Millisecond),
+	})
+}
+
+// WithBytes adds the size n in two fields, as a human readable string like
+// "1.5 MiB" in the key field and as a number of bytes in the key+"_bytes"
+// field, see WithDuration.
+func (entry *Entry) WithBytes(key string, n int64) *Entry {
+	return entry.WithFields(Fields{
+		key:                  formatBytes(n),
+		key + BytesKeySuffix: n,
+	})
+}
+
+// WithDuration creates an entry with a duration, see Entry.WithDuration.
+func (logger *Logger) WithDuration(key string, d time.Duration) *Entry {
+	entry := logger.newEntry()
+	defer logger.releaseEntry(entry)
+	return entry.WithDuration(key, d)
+}
+
+// WithBytes creates an entry with a size, see Entry.WithBytes.
+func (logger *Logger) WithBytes(key string, n int64) *Entry {
+	entry := logger.newEntry()
+	defer logger.releaseEntry(entry)
+	return entry.WithBytes(key, n)
+}
+
+// formatBytes formats n bytes with binary prefixes, e.g. "1.5 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := abs / unit; m >= unit && exp < 5; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,43 @@
+package logrus
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDuration(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := New()
+	logger.Out = &buffer
+	logger.Formatter = new(JSONFormatter)
+
+	logger.WithDuration("latency", 1200500*time.Microsecond).WithBytes("size", 1536).Info("handled")
+
+	fields := logLines(t, &buffer)
+	if assert.Len(t, fields, 1) {
+		assert.Equal(t, "1.2005s", fields[0]["latency"])
+		assert.Equal(t, 1200.5, fields[0]["latency_ms"])
+		assert.Equal(t, "1.5 KiB", fields[0]["size"])
+		assert.Equal(t, float64(1536), fields[0]["size_bytes"])
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, expected := range map[int64]string{
+		0:                 "0 B",
+		1023:              "1023 B",
+		1024:              "1.0 KiB",
+		-2048:             "-2.0 KiB",
+		5 << 20:           "5.0 MiB",
+		3 << 30:           "3.0 GiB",
+		1 << 40:           "1.0 TiB",
+		math.MaxInt64:     "8.0 EiB",
+		1<<20 + 1<<19 - 1: "1.5 MiB",
+	} {
+		assert.Equal(t, expected, formatBytes(n), n)
+	}
+}
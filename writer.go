@@ -2,8 +2,10 @@ package logrus
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"runtime"
+	"strings"
 )
 
 func (logger *Logger) Writer() *io.PipeWriter {
@@ -46,6 +48,76 @@ func (entry *Entry) WriterLevel(level Level) *io.PipeWriter {
 	return writer
 }
 
+// WriterParseLevel is like Writer, but logs each line at the level of its
+// prefix, e.g. from third-party libraries, see Entry.WriterParseLevel.
+func (logger *Logger) WriterParseLevel(defaultLevel Level) *io.PipeWriter {
+	return NewEntry(logger).WriterParseLevel(defaultLevel)
+}
+
+// WriterParseLevel is like Writer, but logs each line at the level of its
+// prefix, removing it, or at defaultLevel if it has none. Prefixes are level
+// names in brackets or followed by a colon, e.g. `[debug] ` or `Error: `, or
+// upper case and followed by a space, e.g. `WARN `. Lines with a panic prefix
+// are logged at FatalLevel, and lines at FatalLevel don't exit, see Log.
+func (entry *Entry) WriterParseLevel(defaultLevel Level) *io.PipeWriter {
+	reader, writer := io.Pipe()
+
+	go entry.writerScanner(reader, func(args ...interface{}) {
+		level, msg := parseLevelPrefix(fmt.Sprint(args...), defaultLevel)
+		entry.Log(level, msg)
+	})
+	runtime.SetFinalizer(writer, writerFinalizer)
+
+	return writer
+}
+
+// levelPrefixAliases are the level prefixes other than level names.
+var levelPrefixAliases = map[string]Level{
+	"err":      ErrorLevel,
+	"crit":     ErrorLevel,
+	"critical": ErrorLevel,
+	"dbg":      DebugLevel,
+	"trace":    DebugLevel,
+}
+
+// parseLevelPrefix returns the level of the prefix of line and the rest of
+// the line, or defaultLevel and line if it has no level prefix.
+func parseLevelPrefix(line string, defaultLevel Level) (Level, string) {
+	s := strings.TrimLeft(line, " \t")
+
+	var name, rest string
+	if strings.HasPrefix(s, "[") {
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return defaultLevel, line
+		}
+		name, rest = s[1:end], s[end+1:]
+	} else {
+		end := strings.IndexAny(s, ": ")
+		if end < 0 {
+			return defaultLevel, line
+		}
+		name, rest = s[:end], s[end+1:]
+		if s[end] == ' ' && name != strings.ToUpper(name) {
+			// e.g. "Info about ..."
+			return defaultLevel, line
+		}
+	}
+
+	level, err := ParseLevel(name)
+	if err != nil {
+		var ok bool
+		if level, ok = levelPrefixAliases[strings.ToLower(name)]; !ok {
+			return defaultLevel, line
+		}
+	}
+	if level == PanicLevel {
+		// don't panic in the goroutine of the writer
+		level = FatalLevel
+	}
+	return level, strings.TrimLeft(rest, " \t")
+}
+
 func (entry *Entry) writerScanner(reader *io.PipeReader, printFunc func(args ...interface{})) {
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {